@@ -0,0 +1,203 @@
+package exo
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/exo-framework/exo/migrator"
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	claimsLocalsKey  = "exo_claims"
+	authErrLocalsKey = "exo_auth_error"
+	refreshTokenType = "refresh+jwt"
+)
+
+var (
+	ErrAuthMissingSecret    = errors.New("missing authentication secret")
+	ErrAuthUnsupportedAlgo  = errors.New("unsupported authentication hashing algorithm")
+	ErrAuthNoSigningKey     = errors.New("no valid signing key")
+	ErrAuthInvalidToken     = errors.New("invalid token")
+	ErrAuthInvalidAuthValue = errors.New("invalid authorization header")
+	ErrAuthRefreshDisabled  = errors.New("refresh tokens are not enabled")
+	ErrAuthTokenExpired     = errors.New("token expired")
+	ErrAuthTokenRevoked     = errors.New("token revoked")
+	ErrAuthTokenReused      = errors.New("refresh token reused")
+	ErrAuthNoDatabase       = errors.New("database is not initialized")
+)
+
+// Claims is a struct that holds the claims of an identity token issued by the exo auth subsystem.
+type Claims struct {
+	jwt.RegisteredClaims
+	Roles  []string `json:"roles,omitempty"`  // roles of the subject, checked by the roles tag of generated routes
+	Scopes []string `json:"scopes,omitempty"` // scopes granted to the token, checked by the scopes tag of generated routes
+	Data   O        `json:"dat,omitempty"`    // custom application data carried by the token
+}
+
+// HasRole reports whether the claims contain at least one of the given roles.
+func (c *Claims) HasRole(roles ...string) bool {
+	for _, role := range roles {
+		if slices.Contains(c.Roles, role) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasScopes reports whether the claims contain all of the given scopes.
+func (c *Claims) HasScopes(scopes ...string) bool {
+	for _, scope := range scopes {
+		if !slices.Contains(c.Scopes, scope) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Auth is the authentication subsystem of the exo framework. It issues and verifies identity tokens.
+type Auth struct {
+	config      AuthConfig
+	database    func() *gorm.DB // database of the migrator, which is connected after the auth subsystem is created
+	keys        *tokenKeys
+	refreshKeys *tokenKeys
+}
+
+func newAuth(config AuthConfig, mig *migrator.Migrator) (*Auth, error) {
+	auth := &Auth{
+		config:   config,
+		database: mig.DB,
+	}
+
+	var err error
+	if len(config.keys) > 0 {
+		auth.keys, err = newTokenKeys(config.keys)
+	} else {
+		auth.keys, err = parseAuthKeys(config.hashAlgo, config.secrets)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if config.refreshToken {
+		if len(config.secrets) >= 3 {
+			auth.refreshKeys, err = parseAuthKeys(config.refreshHashAlgo, config.secrets[2:])
+			if err != nil {
+				return nil, err
+			}
+		} else if len(config.keys) > 0 {
+			// refresh tokens are only verified by this service, so they may share the identity keys. The token type header keeps them apart.
+			auth.refreshKeys = auth.keys
+		} else {
+			return nil, ErrAuthMissingSecret
+		}
+
+		mig.AddModel(&refreshToken{})
+	}
+
+	return auth, nil
+}
+
+// Issue signs the given claims as identity token. If the claims have no expiration time set, the configured identity token expiration is used.
+func (a *Auth) Issue(claims Claims) (string, error) {
+	now := time.Now()
+
+	if claims.ID == "" {
+		claims.ID = uuid.NewString()
+	}
+
+	if claims.IssuedAt == nil {
+		claims.IssuedAt = jwt.NewNumericDate(now)
+	}
+
+	if claims.ExpiresAt == nil && a.config.idenityTokenExp > 0 {
+		claims.ExpiresAt = jwt.NewNumericDate(now.Add(time.Duration(a.config.idenityTokenExp) * time.Second))
+	}
+
+	return a.keys.sign(claims, "")
+}
+
+// Verify parses the given identity token and verifies its signature and expiration. It returns the claims of the token.
+func (a *Auth) Verify(token string) (*Claims, error) {
+	claims := &Claims{}
+
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Header["typ"] == refreshTokenType {
+			return nil, ErrAuthInvalidToken
+		}
+
+		return a.keys.verifyKey(t)
+	})
+	if err != nil {
+		return nil, errors.Join(ErrAuthInvalidToken, err)
+	}
+
+	return claims, nil
+}
+
+// handler verifies the bearer token of the request. Requests with a malformed or invalid token are handled like anonymous requests,
+// the failure is only recorded so that routes requiring authentication can report it.
+func (a *Auth) handler(c *fiber.Ctx) error {
+	header := c.Get(fiber.HeaderAuthorization)
+	if header == "" {
+		return c.Next()
+	}
+
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		c.Locals(authErrLocalsKey, ErrAuthInvalidAuthValue)
+		return c.Next()
+	}
+
+	claims, err := a.Verify(token)
+	if err != nil {
+		c.Locals(authErrLocalsKey, err)
+		return c.Next()
+	}
+
+	c.Locals(claimsLocalsKey, claims)
+	return c.Next()
+}
+
+// Decode decodes the claims into the given value, e.g. a custom claims struct using the json tags of the registered claims.
+func (c *Claims) Decode(v any) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// GetClaims returns the verified claims of the current request or nil if the request is not authenticated.
+func GetClaims(c *fiber.Ctx) *Claims {
+	claims, _ := c.Locals(claimsLocalsKey).(*Claims)
+	return claims
+}
+
+// RequireClaims returns the verified claims of the current request. If the request is not authenticated, it returns an error which is
+// answered with 401 and tells whether the token was missing or invalid.
+func RequireClaims(c *fiber.Ctx) (*Claims, error) {
+	if claims := GetClaims(c); claims != nil {
+		return claims, nil
+	}
+
+	if err, ok := c.Locals(authErrLocalsKey).(error); ok {
+		if errors.Is(err, ErrAuthInvalidAuthValue) {
+			return nil, Unauthorized(ErrAuthInvalidAuthValue.Error())
+		}
+
+		return nil, Unauthorized(ErrAuthInvalidToken.Error()).Wrap(err)
+	}
+
+	return nil, Unauthorized("authentication required")
+}
@@ -0,0 +1,347 @@
+package exo
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/asn1"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// AuthKey is a struct that holds an asymmetric key used to sign and verify identity tokens.
+type AuthKey struct {
+	ID        string           // key id written into the kid header of signed tokens and published in the JWKS
+	Signer    crypto.Signer    // private key used for signing. Keys without a signer are only used for verification.
+	Public    crypto.PublicKey // public key used for verification. If nil, the public key of the signer is used.
+	CreatedAt time.Time        // the newest valid key with a signer is used for signing
+	ExpiresAt time.Time        // the key is neither used for signing nor for verification after this time. Zero means no expiration.
+}
+
+type tokenKey struct {
+	id        string
+	method    jwt.SigningMethod
+	signKey   any
+	verifyKey any
+	createdAt time.Time
+	expiresAt time.Time
+}
+
+func (k *tokenKey) valid(now time.Time) bool {
+	return k.expiresAt.IsZero() || now.Before(k.expiresAt)
+}
+
+// tokenKeys is a key ring which signs with the newest valid key and verifies against every valid key.
+type tokenKeys struct {
+	keys []*tokenKey
+}
+
+func newTokenKeys(keys []AuthKey) (*tokenKeys, error) {
+	ring := &tokenKeys{keys: make([]*tokenKey, 0, len(keys))}
+	ids := make(map[string]bool, len(keys))
+
+	for _, key := range keys {
+		if key.ID == "" {
+			return nil, fmt.Errorf("auth key without id")
+		}
+
+		if ids[key.ID] {
+			return nil, fmt.Errorf("duplicate auth key id %s", key.ID)
+		}
+
+		ids[key.ID] = true
+
+		public := key.Public
+		if public == nil {
+			if key.Signer == nil {
+				return nil, fmt.Errorf("auth key %s has neither a signer nor a public key", key.ID)
+			}
+
+			public = key.Signer.Public()
+		}
+
+		method, err := signerSigningMethod(public)
+		if err != nil {
+			return nil, fmt.Errorf("auth key %s: %w", key.ID, err)
+		}
+
+		tk := &tokenKey{
+			id:        key.ID,
+			method:    method,
+			verifyKey: public,
+			createdAt: key.CreatedAt,
+			expiresAt: key.ExpiresAt,
+		}
+
+		if key.Signer != nil {
+			tk.signKey = key.Signer
+		}
+
+		ring.keys = append(ring.keys, tk)
+	}
+
+	return ring, nil
+}
+
+func (r *tokenKeys) sign(claims jwt.Claims, typ string) (string, error) {
+	now := time.Now()
+
+	var key *tokenKey
+	for _, k := range r.keys {
+		if k.signKey == nil || !k.valid(now) {
+			continue
+		}
+
+		if key == nil || !k.createdAt.Before(key.createdAt) {
+			key = k
+		}
+	}
+
+	if key == nil {
+		return "", ErrAuthNoSigningKey
+	}
+
+	token := jwt.NewWithClaims(key.method, claims)
+	if key.id != "" {
+		token.Header["kid"] = key.id
+	}
+
+	if typ != "" {
+		token.Header["typ"] = typ
+	}
+
+	return token.SignedString(key.signKey)
+}
+
+func (r *tokenKeys) verifyKey(t *jwt.Token) (any, error) {
+	now := time.Now()
+	kid, _ := t.Header["kid"].(string)
+
+	for _, k := range r.keys {
+		if k.id != kid || !k.valid(now) {
+			continue
+		}
+
+		if t.Method.Alg() != k.method.Alg() {
+			return nil, ErrAuthInvalidToken
+		}
+
+		return k.verifyKey, nil
+	}
+
+	return nil, ErrAuthInvalidToken
+}
+
+func (r *tokenKeys) jwksHandler(c *fiber.Ctx) error {
+	now := time.Now()
+	keys := make([]O, 0, len(r.keys))
+
+	for _, k := range r.keys {
+		if !k.valid(now) {
+			continue
+		}
+
+		if jwk := publicJWK(k); jwk != nil {
+			keys = append(keys, jwk)
+		}
+	}
+
+	return c.JSON(O{"keys": keys})
+}
+
+func (r *tokenKeys) asymmetric() bool {
+	for _, k := range r.keys {
+		if _, ok := k.verifyKey.([]byte); !ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseAuthKeys(algo AuthHashAlgo, secrets []string) (*tokenKeys, error) {
+	key := &tokenKey{}
+
+	switch algo {
+	case AuthHashAlgoHMACSHA256, AuthHashAlgoHMACSHA512:
+		if len(secrets) < 1 || secrets[0] == "" {
+			return nil, ErrAuthMissingSecret
+		}
+
+		key.method = jwt.SigningMethodHS256
+		if algo == AuthHashAlgoHMACSHA512 {
+			key.method = jwt.SigningMethodHS512
+		}
+
+		key.signKey = []byte(secrets[0])
+		key.verifyKey = []byte(secrets[0])
+	case AuthHashAlgoRSA:
+		if len(secrets) < 2 {
+			return nil, ErrAuthMissingSecret
+		}
+
+		private, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(secrets[0]))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing RSA private key: %w", err)
+		}
+
+		public, err := jwt.ParseRSAPublicKeyFromPEM([]byte(secrets[1]))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing RSA public key: %w", err)
+		}
+
+		key.method = jwt.SigningMethodRS256
+		key.signKey = private
+		key.verifyKey = public
+	case AuthHashAlgoECDSA:
+		if len(secrets) < 2 {
+			return nil, ErrAuthMissingSecret
+		}
+
+		private, err := jwt.ParseECPrivateKeyFromPEM([]byte(secrets[0]))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing ECDSA private key: %w", err)
+		}
+
+		public, err := jwt.ParseECPublicKeyFromPEM([]byte(secrets[1]))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing ECDSA public key: %w", err)
+		}
+
+		method, err := ecdsaSigningMethod(public)
+		if err != nil {
+			return nil, err
+		}
+
+		key.method = method
+		key.signKey = private
+		key.verifyKey = public
+	default:
+		return nil, ErrAuthUnsupportedAlgo
+	}
+
+	return &tokenKeys{keys: []*tokenKey{key}}, nil
+}
+
+func ecdsaSigningMethod(key *ecdsa.PublicKey) (jwt.SigningMethod, error) {
+	switch key.Curve.Params().BitSize {
+	case 256:
+		return jwt.SigningMethodES256, nil
+	case 384:
+		return jwt.SigningMethodES384, nil
+	case 521:
+		return jwt.SigningMethodES512, nil
+	}
+
+	return nil, ErrAuthUnsupportedAlgo
+}
+
+// signerMethod is a jwt.SigningMethod which signs using any crypto.Signer (e.g. keys held by a KMS) and verifies using the standard method of the algorithm.
+type signerMethod struct {
+	jwt.SigningMethod
+	hash    crypto.Hash
+	keySize int // size of r and s in bytes for ECDSA signatures
+}
+
+func signerSigningMethod(public crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		return &signerMethod{SigningMethod: jwt.SigningMethodRS256, hash: crypto.SHA256}, nil
+	case *ecdsa.PublicKey:
+		method, err := ecdsaSigningMethod(key)
+		if err != nil {
+			return nil, err
+		}
+
+		ecMethod := method.(*jwt.SigningMethodECDSA)
+		return &signerMethod{SigningMethod: method, hash: ecMethod.Hash, keySize: ecMethod.KeySize}, nil
+	case ed25519.PublicKey:
+		return &signerMethod{SigningMethod: jwt.SigningMethodEdDSA}, nil
+	}
+
+	return nil, ErrAuthUnsupportedAlgo
+}
+
+func (m *signerMethod) Sign(signingString string, key interface{}) ([]byte, error) {
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, jwt.ErrInvalidKeyType
+	}
+
+	digest := []byte(signingString)
+	if m.hash != 0 {
+		hasher := m.hash.New()
+		hasher.Write(digest)
+		digest = hasher.Sum(nil)
+	}
+
+	sig, err := signer.Sign(rand.Reader, digest, m.hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.keySize == 0 {
+		return sig, nil
+	}
+
+	// crypto.Signer returns ASN.1 encoded ECDSA signatures, JWS expects the fixed size concatenation of r and s
+	var parsed struct {
+		R, S *big.Int
+	}
+
+	if _, err := asn1.Unmarshal(sig, &parsed); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 2*m.keySize)
+	parsed.R.FillBytes(out[:m.keySize])
+	parsed.S.FillBytes(out[m.keySize:])
+
+	return out, nil
+}
+
+func publicJWK(k *tokenKey) O {
+	jwk := O{
+		"use": "sig",
+		"alg": k.method.Alg(),
+	}
+
+	if k.id != "" {
+		jwk["kid"] = k.id
+	}
+
+	switch key := k.verifyKey.(type) {
+	case *rsa.PublicKey:
+		jwk["kty"] = "RSA"
+		jwk["n"] = base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+		jwk["e"] = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+	case *ecdsa.PublicKey:
+		ecdhKey, err := key.ECDH()
+		if err != nil {
+			return nil
+		}
+
+		point := ecdhKey.Bytes()[1:] // uncompressed point without the 0x04 prefix
+		size := len(point) / 2
+
+		jwk["kty"] = "EC"
+		jwk["crv"] = key.Curve.Params().Name
+		jwk["x"] = base64.RawURLEncoding.EncodeToString(point[:size])
+		jwk["y"] = base64.RawURLEncoding.EncodeToString(point[size:])
+	case ed25519.PublicKey:
+		jwk["kty"] = "OKP"
+		jwk["crv"] = "Ed25519"
+		jwk["x"] = base64.RawURLEncoding.EncodeToString(key)
+	default:
+		return nil
+	}
+
+	return jwk
+}
@@ -0,0 +1,141 @@
+package exo
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newTestKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+func TestVerifyRejectsUnknownKeys(t *testing.T) {
+	key := newTestKey(t)
+	auth := newTestAuth(t, WithAuthKeys(AuthKey{ID: "current", Signer: key}))
+
+	other, err := newTokenKeys([]AuthKey{{ID: "other", Signer: newTestKey(t)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unknownKid, err := other.sign(jwt.RegisteredClaims{Subject: "user"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a token signed by a foreign key using the kid of a known key
+	forgedKid := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.RegisteredClaims{Subject: "user"})
+	forgedKid.Header["kid"] = "current"
+	forged, err := forgedKid.SignedString(newTestKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a token using another algorithm than the key of its kid
+	otherAlg := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{Subject: "user"})
+	otherAlg.Header["kid"] = "current"
+	mismatched, err := otherAlg.SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, token := range map[string]string{"unknown kid": unknownKid, "forged kid": forged, "mismatched alg": mismatched} {
+		if _, err := auth.Verify(token); !errors.Is(err, ErrAuthInvalidToken) {
+			t.Errorf("%s: expected %v, got %v", name, ErrAuthInvalidToken, err)
+		}
+	}
+
+	// the algorithm is checked before the signature, so the key of the kid is never used with another algorithm
+	parsed, _, err := jwt.NewParser().ParseUnverified(mismatched, &jwt.RegisteredClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := auth.keys.verifyKey(parsed); !errors.Is(err, ErrAuthInvalidToken) {
+		t.Errorf("mismatched alg: expected %v from the key lookup, got %v", ErrAuthInvalidToken, err)
+	}
+}
+
+// opaqueSigner hides the key type like a signer of a KMS does.
+type opaqueSigner struct {
+	crypto.Signer
+}
+
+func TestSignerES256VerifiesWithJWKS(t *testing.T) {
+	key := newTestKey(t)
+	auth := newTestAuth(t, WithAuthKeys(AuthKey{ID: "kms", Signer: opaqueSigner{key}}))
+
+	token, err := auth.Issue(Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "user"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := fiber.New()
+	app.Get("/jwks", auth.keys.jwksHandler)
+
+	res, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/jwks", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwks := struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Alg string `json:"alg"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}{}
+
+	if err := json.NewDecoder(res.Body).Decode(&jwks); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(jwks.Keys) != 1 || jwks.Keys[0].Kid != "kms" || jwks.Keys[0].Alg != "ES256" || jwks.Keys[0].Crv != "P-256" {
+		t.Fatalf("unexpected JWKS: %+v", jwks)
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(jwks.Keys[0].X)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	y, err := base64.RawURLEncoding.DecodeString(jwks.Keys[0].Y)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	public := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+
+	// the standard ES256 method only accepts the fixed size concatenation of r and s
+	claims := &jwt.RegisteredClaims{}
+	_, err = jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return public, nil
+	}, jwt.WithValidMethods([]string{"ES256"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if claims.Subject != "user" {
+		t.Fatalf("unexpected subject %q", claims.Subject)
+	}
+}
@@ -0,0 +1,284 @@
+package exo
+
+import (
+	"errors"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// refreshToken is the model of the refresh token store. It is added to the migrator so the table is part of the generated migrations.
+type refreshToken struct {
+	ID              uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	FamilyID        uuid.UUID  `gorm:"type:uuid;not null"`
+	Subject         string     `gorm:"type:text;not null"`
+	Claims          string     `gorm:"type:text;not null"` // identity claims re-issued on refresh
+	ExpiresAt       time.Time  `gorm:"not null"`
+	FamilyExpiresAt *time.Time // absolute expiration of the whole token family
+	RotatedAt       *time.Time // set once the token was exchanged for a new one
+	Revoked         bool       `gorm:"not null;default:false"`
+	CreatedAt       time.Time  `gorm:"not null"`
+}
+
+func (refreshToken) TableName() string {
+	return "exo_refresh_tokens"
+}
+
+// TokenPair is a struct that holds an identity token and its refresh token.
+type TokenPair struct {
+	IdentityToken string `json:"identityToken"`
+	RefreshToken  string `json:"refreshToken,omitempty"`
+}
+
+// IssuePair signs the given claims as identity token and, if refresh tokens are enabled, creates a new refresh token family for them.
+func (a *Auth) IssuePair(claims Claims) (*TokenPair, error) {
+	identity, err := a.Issue(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	pair := &TokenPair{IdentityToken: identity}
+	if !a.config.refreshToken {
+		return pair, nil
+	}
+
+	db, err := a.db()
+	if err != nil {
+		return nil, err
+	}
+
+	// the stored claims are re-issued on every refresh, so token specific claims must not be kept
+	claims.ID = ""
+	claims.IssuedAt = nil
+	claims.NotBefore = nil
+	claims.ExpiresAt = nil
+
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	stored := refreshToken{
+		FamilyID:  uuid.New(),
+		Subject:   claims.Subject,
+		Claims:    string(data),
+		CreatedAt: now,
+	}
+
+	if a.config.refreshTokenAbsoluteExp > 0 {
+		familyExp := now.Add(time.Duration(a.config.refreshTokenAbsoluteExp) * time.Second)
+		stored.FamilyExpiresAt = &familyExp
+	}
+
+	pair.RefreshToken, err = a.signRefreshToken(&stored, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Create(&stored).Error; err != nil {
+		return nil, err
+	}
+
+	return pair, nil
+}
+
+// Refresh exchanges the given refresh token for a new identity token. If rotation is enabled, the refresh token is replaced by a new one of the same family.
+// If steal detection is enabled and an already rotated refresh token is used again, the whole token family is revoked.
+func (a *Auth) Refresh(token string) (*TokenPair, error) {
+	if !a.config.refreshToken {
+		return nil, ErrAuthRefreshDisabled
+	}
+
+	db, err := a.db()
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := a.parseRefreshToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var pair *TokenPair
+	var reusedFamily *uuid.UUID
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		stored := refreshToken{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&stored).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrAuthInvalidToken
+			}
+
+			return err
+		}
+
+		if stored.Revoked {
+			return ErrAuthTokenRevoked
+		}
+
+		if stored.RotatedAt != nil {
+			reusedFamily = &stored.FamilyID
+			return ErrAuthTokenReused
+		}
+
+		now := time.Now()
+		if now.After(stored.ExpiresAt) || (stored.FamilyExpiresAt != nil && now.After(*stored.FamilyExpiresAt)) {
+			return ErrAuthTokenExpired
+		}
+
+		claims := Claims{}
+		if err := json.Unmarshal([]byte(stored.Claims), &claims); err != nil {
+			return err
+		}
+
+		identity, err := a.Issue(claims)
+		if err != nil {
+			return err
+		}
+
+		pair = &TokenPair{IdentityToken: identity, RefreshToken: token}
+		if !a.config.refreshTokenRotation {
+			return nil
+		}
+
+		if err := tx.Model(&stored).Update("rotated_at", now).Error; err != nil {
+			return err
+		}
+
+		next := refreshToken{
+			FamilyID:        stored.FamilyID,
+			Subject:         stored.Subject,
+			Claims:          stored.Claims,
+			FamilyExpiresAt: stored.FamilyExpiresAt,
+			CreatedAt:       now,
+		}
+
+		pair.RefreshToken, err = a.signRefreshToken(&next, now)
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&next).Error
+	})
+
+	if reusedFamily != nil && a.config.refreshTokenStealDetect {
+		if rerr := a.revokeFamily(db, *reusedFamily); rerr != nil {
+			return nil, errors.Join(err, rerr)
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return pair, nil
+}
+
+// Revoke revokes the whole token family of the given refresh token, e.g. on logout.
+func (a *Auth) Revoke(token string) error {
+	if !a.config.refreshToken {
+		return ErrAuthRefreshDisabled
+	}
+
+	db, err := a.db()
+	if err != nil {
+		return err
+	}
+
+	id, err := a.parseRefreshToken(token)
+	if err != nil {
+		return err
+	}
+
+	stored := refreshToken{}
+	if err := db.Where("id = ?", id).First(&stored).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAuthInvalidToken
+		}
+
+		return err
+	}
+
+	return a.revokeFamily(db, stored.FamilyID)
+}
+
+func (a *Auth) refreshHandler(c *fiber.Ctx) error {
+	body := struct {
+		RefreshToken string `json:"refreshToken"`
+	}{}
+
+	if err := c.BodyParser(&body); err != nil || body.RefreshToken == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "missing refresh token")
+	}
+
+	pair, err := a.Refresh(body.RefreshToken)
+	if err != nil {
+		if errors.Is(err, ErrAuthInvalidToken) || errors.Is(err, ErrAuthTokenExpired) || errors.Is(err, ErrAuthTokenRevoked) || errors.Is(err, ErrAuthTokenReused) {
+			return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+		}
+
+		return err
+	}
+
+	return c.JSON(pair)
+}
+
+func (a *Auth) signRefreshToken(stored *refreshToken, now time.Time) (string, error) {
+	stored.ID = uuid.New()
+	stored.ExpiresAt = now.Add(time.Duration(a.config.refreshTokenExp) * time.Second)
+	if stored.FamilyExpiresAt != nil && stored.FamilyExpiresAt.Before(stored.ExpiresAt) {
+		stored.ExpiresAt = *stored.FamilyExpiresAt
+	}
+
+	return a.refreshKeys.sign(jwt.RegisteredClaims{
+		ID:        stored.ID.String(),
+		Subject:   stored.Subject,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(stored.ExpiresAt),
+	}, refreshTokenType)
+}
+
+func (a *Auth) parseRefreshToken(token string) (uuid.UUID, error) {
+	claims := &jwt.RegisteredClaims{}
+
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Header["typ"] != refreshTokenType {
+			return nil, ErrAuthInvalidToken
+		}
+
+		return a.refreshKeys.verifyKey(t)
+	})
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return uuid.Nil, ErrAuthTokenExpired
+		}
+
+		return uuid.Nil, errors.Join(ErrAuthInvalidToken, err)
+	}
+
+	id, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return uuid.Nil, errors.Join(ErrAuthInvalidToken, err)
+	}
+
+	return id, nil
+}
+
+func (a *Auth) revokeFamily(db *gorm.DB, family uuid.UUID) error {
+	return db.Model(&refreshToken{}).Where("family_id = ?", family).Update("revoked", true).Error
+}
+
+func (a *Auth) db() (*gorm.DB, error) {
+	db := a.database()
+	if db == nil {
+		return nil, ErrAuthNoDatabase
+	}
+
+	return db, nil
+}
@@ -0,0 +1,86 @@
+package exo
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/exo-framework/exo/migrator"
+	"github.com/glebarez/sqlite"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+func newTestAuth(t *testing.T, opts ...AuthOption) *Auth {
+	t.Helper()
+
+	auth, err := newAuth(getConfig([]ConfigOption{WithAuth(opts...)}).auth, migrator.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "auth.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.AutoMigrate(&refreshToken{}); err != nil {
+		t.Fatal(err)
+	}
+
+	auth.database = func() *gorm.DB { return db }
+	return auth
+}
+
+func TestRefreshRotatesToken(t *testing.T) {
+	auth := newTestAuth(t, WithAuthSecrets("identity", "", "refresh"), WithAuthRefreshToken(), WithAuthRefreshTokenRotation())
+
+	pair, err := auth.IssuePair(Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "user"}, Roles: []string{"admin"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next, err := auth.Refresh(pair.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if next.RefreshToken == pair.RefreshToken {
+		t.Fatal("refresh token was not rotated")
+	}
+
+	claims, err := auth.Verify(next.IdentityToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if claims.Subject != "user" || !claims.HasRole("admin") {
+		t.Fatalf("claims were not re-issued: %+v", claims)
+	}
+
+	if _, err := auth.Refresh(next.RefreshToken); err != nil {
+		t.Fatalf("rotated token was not accepted: %v", err)
+	}
+}
+
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	auth := newTestAuth(t, WithAuthSecrets("identity", "", "refresh"), WithAuthRefreshToken(), WithAuthRefreshTokenRotation(), WithAuthRefreshTokenStealDetect())
+
+	pair, err := auth.IssuePair(Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "user"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next, err := auth.Refresh(pair.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := auth.Refresh(pair.RefreshToken); !errors.Is(err, ErrAuthTokenReused) {
+		t.Fatalf("expected %v for the old token, got %v", ErrAuthTokenReused, err)
+	}
+
+	if _, err := auth.Refresh(next.RefreshToken); !errors.Is(err, ErrAuthTokenRevoked) {
+		t.Fatalf("expected %v for the newest token of the family, got %v", ErrAuthTokenRevoked, err)
+	}
+}
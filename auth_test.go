@@ -0,0 +1,172 @@
+package exo
+
+import (
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/exo-framework/exo/migrator"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestAuthRequiresSecrets(t *testing.T) {
+	for _, algo := range []AuthHashAlgo{AuthHashAlgoHMACSHA256, AuthHashAlgoRSA, AuthHashAlgoECDSA} {
+		_, err := newAuth(getConfig([]ConfigOption{WithAuth(WithAuthHashAlgorithms(algo))}).auth, migrator.New())
+		if !errors.Is(err, ErrAuthMissingSecret) {
+			t.Errorf("%s: expected %v, got %v", algo, ErrAuthMissingSecret, err)
+		}
+	}
+}
+
+func TestInvalidTokenOnlyRejectsProtectedRoutes(t *testing.T) {
+	auth := newTestAuth(t, WithAuthSecrets("identity"))
+
+	app := fiber.New(fiber.Config{ErrorHandler: ProblemErrorHandler(false)})
+	app.Use(auth.handler)
+	app.Get("/public", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Get("/protected", func(c *fiber.Ctx) error {
+		if _, err := RequireClaims(c); err != nil {
+			return err
+		}
+
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	for _, header := range []string{"Bearer invalid", "Basic invalid"} {
+		for route, status := range map[string]int{"/public": fiber.StatusOK, "/protected": fiber.StatusUnauthorized} {
+			req := httptest.NewRequest(fiber.MethodGet, route, nil)
+			req.Header.Set(fiber.HeaderAuthorization, header)
+
+			res, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if res.StatusCode != status {
+				t.Errorf("%s with %q: expected %d, got %d", route, header, status, res.StatusCode)
+			}
+		}
+	}
+}
+
+func TestRequireClaims(t *testing.T) {
+	auth := newTestAuth(t, WithAuthSecrets("identity"))
+
+	token, err := auth.Issue(Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "user"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		header  string
+		subject string
+		detail  string
+	}{
+		{"valid token", "Bearer " + token, "user", ""},
+		{"missing token", "", "", "authentication required"},
+		{"invalid token", "Bearer invalid", "", ErrAuthInvalidToken.Error()},
+		{"other scheme", "Basic " + token, "", ErrAuthInvalidAuthValue.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Use(auth.handler)
+			app.Get("/", func(c *fiber.Ctx) error {
+				claims, err := RequireClaims(c)
+				if tt.detail != "" {
+					var httpErr *HTTPError
+					if !errors.As(err, &httpErr) || httpErr.Status != fiber.StatusUnauthorized || httpErr.Detail != tt.detail {
+						t.Errorf("expected 401 %q, got %v", tt.detail, err)
+					}
+
+					if GetClaims(c) != nil {
+						t.Error("expected no claims")
+					}
+
+					return nil
+				}
+
+				if err != nil || claims.Subject != tt.subject || GetClaims(c) != claims {
+					t.Errorf("expected claims of %s, got %+v, %v", tt.subject, claims, err)
+				}
+
+				return nil
+			})
+
+			req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(fiber.HeaderAuthorization, tt.header)
+			}
+
+			if _, err := app.Test(req); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestClaimsDecode(t *testing.T) {
+	type custom struct {
+		Subject string   `json:"sub"`
+		Roles   []string `json:"roles"`
+		Data    struct {
+			Tenant string `json:"tenant"`
+		} `json:"dat"`
+	}
+
+	tests := []struct {
+		name   string
+		claims Claims
+		want   custom
+	}{
+		{"empty claims", Claims{}, custom{}},
+		{"registered claims", Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "user"}}, custom{Subject: "user"}},
+		{"roles and data", Claims{Roles: []string{"admin"}, Data: O{"tenant": "acme"}}, custom{Roles: []string{"admin"}, Data: struct {
+			Tenant string `json:"tenant"`
+		}{"acme"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got custom
+			if err := tt.claims.Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestClaimsRolesAndScopes(t *testing.T) {
+	claims := Claims{Roles: []string{"editor"}, Scopes: []string{"read", "write"}}
+
+	tests := []struct {
+		name string
+		got  bool
+		want bool
+	}{
+		{"one of the roles", claims.HasRole("admin", "editor"), true},
+		{"none of the roles", claims.HasRole("admin"), false},
+		{"no roles given", claims.HasRole(), false},
+		{"all scopes", claims.HasScopes("read", "write"), true},
+		{"one scope missing", claims.HasScopes("read", "delete"), false},
+		{"no scopes given", claims.HasScopes(), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, tt.got)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package exo
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strings"
+
+	"github.com/goccy/go-json"
+)
+
+// Client is the base of the generated Go clients. It holds the connection settings shared by all requests.
+type Client struct {
+	BaseURL    string       // base URL of the service, e.g. https://orders.internal
+	HTTPClient *http.Client // client used to send the requests. If nil, http.DefaultClient is used.
+	Token      string       // bearer token sent with every request if not empty
+	Header     http.Header  // headers sent with every request
+}
+
+// ClientRequest is a struct that holds a request built by a generated client.
+type ClientRequest struct {
+	Method  string
+	Path    string
+	Query   url.Values
+	Header  http.Header
+	Cookies []*http.Cookie
+	Form    url.Values              // sent as application/x-www-form-urlencoded if Body is nil and there are no Files
+	Files   map[string][]ClientFile // sent as multipart/form-data along with Form if not empty
+	Body    any                     // sent as JSON if not nil
+}
+
+// ClientFile is a file uploaded by a generated client.
+type ClientFile struct {
+	Filename    string
+	ContentType string // media type of the file. If empty, application/octet-stream is sent
+	Content     io.Reader
+}
+
+// ClientError is returned by the generated clients if the service answers with a status code outside of 2xx.
+type ClientError struct {
+	StatusCode int
+	Body       []byte
+	Problem    *Problem // decoded body if the service answered with application/problem+json
+}
+
+func (e *ClientError) Error() string {
+	if len(e.Body) == 0 {
+		return fmt.Sprintf("request failed with status %d", e.StatusCode)
+	}
+
+	return fmt.Sprintf("request failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+// Do sends the given request. If the service answers with a status code outside of 2xx, a *ClientError is returned.
+func (c *Client) Do(ctx context.Context, req ClientRequest) (*http.Response, error) {
+	var body io.Reader
+	contentType := ""
+
+	if req.Body != nil {
+		data, err := json.Marshal(req.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		body = bytes.NewReader(data)
+		contentType = "application/json"
+	} else if len(req.Files) > 0 {
+		data, multipartType, err := encodeMultipart(req.Form, req.Files)
+		if err != nil {
+			return nil, err
+		}
+
+		body = bytes.NewReader(data)
+		contentType = multipartType
+	} else if len(req.Form) > 0 {
+		body = strings.NewReader(req.Form.Encode())
+		contentType = "application/x-www-form-urlencoded"
+	}
+
+	u := strings.TrimSuffix(c.BaseURL, "/") + req.Path
+	if len(req.Query) > 0 {
+		u += "?" + req.Query.Encode()
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, u, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range c.Header {
+		httpReq.Header[k] = v
+	}
+
+	for k, v := range req.Header {
+		httpReq.Header[k] = v
+	}
+
+	for _, cookie := range req.Cookies {
+		httpReq.AddCookie(cookie)
+	}
+
+	if contentType != "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+
+	if c.Token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	res, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		defer res.Body.Close()
+
+		data, _ := io.ReadAll(res.Body)
+		clientErr := &ClientError{StatusCode: res.StatusCode, Body: data}
+
+		if mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type")); mediaType == ProblemContentType {
+			problem := &Problem{}
+			if json.Unmarshal(data, problem) == nil {
+				clientErr.Problem = problem
+			}
+		}
+
+		return nil, clientErr
+	}
+
+	return res, nil
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// encodeMultipart encodes the form values and the files as multipart form and returns it along with its content type.
+func encodeMultipart(form url.Values, files map[string][]ClientFile) ([]byte, string, error) {
+	buf := &bytes.Buffer{}
+	writer := multipart.NewWriter(buf)
+
+	for key, values := range form {
+		for _, value := range values {
+			if err := writer.WriteField(key, value); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+
+	for key, keyFiles := range files {
+		for _, file := range keyFiles {
+			contentType := file.ContentType
+			if contentType == "" {
+				contentType = "application/octet-stream"
+			}
+
+			header := textproto.MIMEHeader{}
+			header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, quoteEscaper.Replace(key), quoteEscaper.Replace(file.Filename)))
+			header.Set("Content-Type", contentType)
+
+			part, err := writer.CreatePart(header)
+			if err != nil {
+				return nil, "", err
+			}
+
+			if _, err := io.Copy(part, file.Content); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return buf.Bytes(), writer.FormDataContentType(), nil
+}
+
+// ReadResponse reads the body of the response into v. Strings and byte slices are read as they are, every other type is decoded from JSON,
+// or from XML if the service answered with XML.
+func ReadResponse(res *http.Response, v any) error {
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	switch t := v.(type) {
+	case *string:
+		*t = string(data)
+		return nil
+	case *[]byte:
+		*t = data
+		return nil
+	}
+
+	if len(data) == 0 {
+		return nil
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type")); mediaType == "application/xml" || mediaType == "text/xml" {
+		return xml.Unmarshal(data, v)
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// ResponseCookies returns the cookies the service set on the response.
+func ResponseCookies(res *http.Response) Cookies {
+	cookies := Cookies{}
+	for _, cookie := range res.Cookies() {
+		cookies = append(cookies, Cookie{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Path:     cookie.Path,
+			Domain:   cookie.Domain,
+			Expires:  cookie.Expires,
+			MaxAge:   cookie.MaxAge,
+			Secure:   cookie.Secure,
+			HTTPOnly: cookie.HttpOnly,
+			SameSite: sameSiteName(cookie.SameSite),
+		})
+	}
+
+	return cookies
+}
+
+func sameSiteName(mode http.SameSite) string {
+	switch mode {
+	case http.SameSiteLaxMode:
+		return "Lax"
+	case http.SameSiteStrictMode:
+		return "Strict"
+	case http.SameSiteNoneMode:
+		return "None"
+	}
+
+	return ""
+}
@@ -0,0 +1,210 @@
+package exo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClientDo(t *testing.T) {
+	type received struct {
+		method, path, query, contentType, auth, custom, cookie string
+		form                                                   url.Values
+		files                                                  map[string]string // content of the files by key
+		body                                                   string
+	}
+
+	tests := []struct {
+		name   string
+		client Client
+		req    ClientRequest
+		want   received
+	}{
+		{
+			name:   "query, headers and cookies",
+			client: Client{Token: "token", Header: http.Header{"X-Custom": {"base"}}},
+			req: ClientRequest{
+				Method:  http.MethodGet,
+				Path:    "/items",
+				Query:   url.Values{"tag": {"a", "b"}},
+				Header:  http.Header{"X-Custom": {"request"}},
+				Cookies: []*http.Cookie{{Name: "theme", Value: "dark"}},
+			},
+			want: received{method: "GET", path: "/items", query: "tag=a&tag=b", auth: "Bearer token", custom: "request", cookie: "theme=dark"},
+		},
+		{
+			name: "json body",
+			req:  ClientRequest{Method: http.MethodPut, Path: "/items/1", Form: url.Values{}, Body: O{"name": "a"}},
+			want: received{method: "PUT", path: "/items/1", contentType: "application/json", body: `{"name":"a"}`},
+		},
+		{
+			name: "urlencoded form",
+			req:  ClientRequest{Method: http.MethodPost, Path: "/login", Form: url.Values{"user": {"a b"}}},
+			want: received{method: "POST", path: "/login", contentType: "application/x-www-form-urlencoded", form: url.Values{"user": {"a b"}}},
+		},
+		{
+			name: "multipart form",
+			req: ClientRequest{
+				Method: http.MethodPost,
+				Path:   "/upload",
+				Form:   url.Values{"title": {"t"}},
+				Files:  map[string][]ClientFile{"file": {{Filename: `a "b".png`, ContentType: "image/png", Content: strings.NewReader("png")}}},
+			},
+			want: received{method: "POST", path: "/upload", contentType: "multipart/form-data", form: url.Values{"title": {"t"}}, files: map[string]string{"file": `a "b".png image/png png`}},
+		},
+		{
+			name: "file without content type",
+			req: ClientRequest{
+				Method: http.MethodPost,
+				Path:   "/upload",
+				Files:  map[string][]ClientFile{"file": {{Filename: "a", Content: strings.NewReader("x")}}},
+			},
+			want: received{method: "POST", path: "/upload", contentType: "multipart/form-data", form: url.Values{}, files: map[string]string{"file": "a application/octet-stream x"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got received
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = received{
+					method:      r.Method,
+					path:        r.URL.Path,
+					query:       r.URL.RawQuery,
+					contentType: strings.Split(r.Header.Get("Content-Type"), ";")[0],
+					auth:        r.Header.Get("Authorization"),
+					custom:      r.Header.Get("X-Custom"),
+				}
+				if cookie, err := r.Cookie("theme"); err == nil {
+					got.cookie = cookie.String()
+				}
+
+				switch got.contentType {
+				case "multipart/form-data":
+					if err := r.ParseMultipartForm(1 << 20); err != nil {
+						t.Error(err)
+					}
+					got.form = r.MultipartForm.Value
+					got.files = map[string]string{}
+					for key, files := range r.MultipartForm.File {
+						f, _ := files[0].Open()
+						content, _ := io.ReadAll(f)
+						got.files[key] = files[0].Filename + " " + files[0].Header.Get("Content-Type") + " " + string(content)
+					}
+				case "application/x-www-form-urlencoded":
+					if err := r.ParseForm(); err != nil {
+						t.Error(err)
+					}
+					got.form = r.PostForm
+				default:
+					body, _ := io.ReadAll(r.Body)
+					got.body = string(body)
+				}
+
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer server.Close()
+
+			client := tt.client
+			client.BaseURL = server.URL + "/"
+
+			res, err := client.Do(context.Background(), tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestClientDoErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		msg         string
+		problem     *Problem
+	}{
+		{"problem", http.StatusBadRequest, ProblemContentType, `{"title":"Bad Request","status":400,"detail":"invalid email"}`, "request failed with status 400", &Problem{Title: "Bad Request", Status: 400, Detail: "invalid email"}},
+		{"plain text", http.StatusInternalServerError, "text/plain", "broken", "request failed with status 500: broken", nil},
+		{"empty body", http.StatusNotFound, "", "", "request failed with status 404", nil},
+		{"malformed problem", http.StatusBadRequest, ProblemContentType, "{", "request failed with status 400: {", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.contentType != "" {
+					w.Header().Set("Content-Type", tt.contentType)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := Client{BaseURL: server.URL}
+			_, err := client.Do(context.Background(), ClientRequest{Method: http.MethodGet, Path: "/"})
+
+			var clientErr *ClientError
+			if !errors.As(err, &clientErr) {
+				t.Fatalf("expected *ClientError, got %v", err)
+			}
+
+			if clientErr.StatusCode != tt.status || !strings.HasPrefix(clientErr.Error(), tt.msg) {
+				t.Errorf("expected %d %q, got %d %q", tt.status, tt.msg, clientErr.StatusCode, clientErr.Error())
+			}
+
+			if !reflect.DeepEqual(clientErr.Problem, tt.problem) {
+				t.Errorf("expected problem %+v, got %+v", tt.problem, clientErr.Problem)
+			}
+		})
+	}
+}
+
+func TestReadResponse(t *testing.T) {
+	type item struct {
+		Name string `json:"name" xml:"name"`
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		v           any
+		want        any
+	}{
+		{"string", "text/plain", "hello", new(string), "hello"},
+		{"bytes", "application/octet-stream", "\x00\x01", new([]byte), []byte{0, 1}},
+		{"json", "application/json", `{"name":"a"}`, new(item), item{Name: "a"}},
+		{"xml", "application/xml; charset=utf-8", `<item><name>a</name></item>`, new(item), item{Name: "a"}},
+		{"empty body", "application/json", "", new(item), item{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := &http.Response{
+				Header: http.Header{"Content-Type": {tt.contentType}},
+				Body:   io.NopCloser(strings.NewReader(tt.body)),
+			}
+
+			if err := ReadResponse(res, tt.v); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := reflect.ValueOf(tt.v).Elem().Interface(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %#v, got %#v", tt.want, got)
+			}
+		})
+	}
+}
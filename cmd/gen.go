@@ -1,20 +1,60 @@
 package cmd
 
 import (
+	"fmt"
+	"log"
+	"os"
+
 	"github.com/exo-framework/exo/gen"
 	"github.com/spf13/cobra"
 )
 
+func analyzeForCLI(cmd *cobra.Command, args []string) *gen.Generator {
+	ignore, _ := cmd.Flags().GetStringSlice("ignore")
+
+	g := gen.NewGenerator()
+	g.Ignore(ignore...)
+
+	roots := args
+	if len(roots) == 0 {
+		roots = g.Roots()
+	}
+
+	for _, root := range roots {
+		if err := g.Analyze(root); err != nil {
+			log.Fatalf("Failed to analyze %s: %v", root, err)
+		}
+	}
+
+	return g
+}
+
 var generateCmd = &cobra.Command{
-	Use:     "generate",
+	Use:     "generate [roots...]",
 	Aliases: []string{"g", "gen"},
 	Short:   "Generates the REST API glue code for the exo framework",
+	Long:    "Generates the REST API glue code for the exo framework. The given roots (or GEN_ROOTS of the .exorc file, or the module root) are analyzed recursively.",
+	Args:    cobra.ArbitraryArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		const path = "E:\\Development\\Sevenity\\z_otherstuff\\exo\\gentest"
+		check, _ := cmd.Flags().GetBool("check")
 
-		g := gen.NewGenerator()
-		if err := g.Analyze(path); err != nil {
-			panic(err)
+		g := analyzeForCLI(cmd, args)
+
+		if check {
+			outdated, err := g.Check()
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			if len(outdated) > 0 {
+				for _, file := range outdated {
+					fmt.Println("outdated:", file)
+				}
+
+				os.Exit(1)
+			}
+
+			return
 		}
 
 		if err := g.Generate(); err != nil {
@@ -22,3 +62,55 @@ var generateCmd = &cobra.Command{
 		}
 	},
 }
+
+var generateOpenAPICmd = &cobra.Command{
+	Use:   "openapi",
+	Short: "Generates an OpenAPI 3.1 document describing the REST API",
+	Run: func(cmd *cobra.Command, args []string) {
+		out, _ := cmd.Flags().GetString("out")
+
+		g := analyzeForCLI(cmd, args)
+
+		if err := g.GenerateOpenAPI(out); err != nil {
+			panic(err)
+		}
+
+		println("OpenAPI document written to", out)
+	},
+}
+
+var generateClientCmd = &cobra.Command{
+	Use:   "client",
+	Short: "Generates a typed client for the REST API",
+	Run: func(cmd *cobra.Command, args []string) {
+		lang, _ := cmd.Flags().GetString("lang")
+		out, _ := cmd.Flags().GetString("out")
+
+		g := analyzeForCLI(cmd, args)
+
+		switch lang {
+		case "ts":
+			if err := g.GenerateTSClient(out); err != nil {
+				panic(err)
+			}
+
+			println("Client written to", out)
+		case "go":
+			if err := g.GenerateGoClient(); err != nil {
+				panic(err)
+			}
+
+			println("Client written to client_gen.go of every package")
+		default:
+			log.Fatalf("Unsupported client language %q", lang)
+		}
+	},
+}
+
+func init() {
+	generateCmd.PersistentFlags().StringSlice("ignore", nil, "glob patterns of directories and files to skip, in addition to GEN_IGNORE of the .exorc file")
+	generateCmd.Flags().Bool("check", false, "only check whether the generated files are up to date and exit with 1 if they are not")
+	generateOpenAPICmd.Flags().StringP("out", "o", "openapi.json", "file the OpenAPI document is written to")
+	generateClientCmd.Flags().String("lang", "ts", "language of the client (ts, go)")
+	generateClientCmd.Flags().StringP("out", "o", "client.ts", "file the TypeScript client is written to. Go clients are written to client_gen.go of every package")
+}
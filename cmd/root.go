@@ -24,6 +24,8 @@ func init() {
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(generateCmd)
+	generateCmd.AddCommand(generateOpenAPICmd)
+	generateCmd.AddCommand(generateClientCmd)
 	rootCmd.AddCommand(migrationsCmd)
 	migrationsCmd.AddCommand(migrationsGenerateCmd)
 	migrationsCmd.AddCommand(migrationsDiffCmd)
@@ -2,7 +2,6 @@ package exo
 
 import (
 	"crypto/tls"
-	"errors"
 	"fmt"
 	"os"
 	"strconv"
@@ -38,13 +37,13 @@ func getConfig(opts []ConfigOption) Config {
 			enable:                  false,
 			hashAlgo:                AuthHashAlgoHMACSHA256,
 			refreshHashAlgo:         AuthHashAlgoHMACSHA256,
-			secrets:                 []string{"secret"},
 			idenityTokenExp:         3600,
 			refreshToken:            false,
 			refreshTokenExp:         3600,
 			refreshTokenRotation:    false,
 			refreshTokenAbsoluteExp: 0,
 			refreshTokenStealDetect: false,
+			refreshRoute:            "/auth/refresh",
 		},
 	}
 
@@ -64,14 +63,17 @@ type Config struct {
 		Expiration    time.Duration
 		ClientControl bool
 	}
-	logging      bool
-	compress     bool
-	ssl          *tls.Certificate
-	errorHandler func(*fiber.Ctx, error) error
-	cors         CorsConfig
-	auth         AuthConfig
-	db           *gorm.DB
-	autoMigrate  bool
+	logging       bool
+	compress      bool
+	ssl           *tls.Certificate
+	errorHandler  func(*fiber.Ctx, error) error
+	errorCallback func(error)
+	cors          CorsConfig
+	auth          AuthConfig
+	db            *gorm.DB
+	autoMigrate   bool
+	openAPI       []byte
+	bodyLimit     int
 }
 
 func (c Config) addr() string {
@@ -106,7 +108,9 @@ func WithCors(opts ...CorsOption) ConfigOption {
 	}
 }
 
-// WithAuth sets the authentication configuration in the configuration.
+// WithAuth sets the authentication configuration in the configuration. Bearer tokens are verified for every request, but requests
+// with a malformed or invalid token are only rejected by routes requiring authentication; public routes handle them as anonymous.
+// Secrets or keys must be set using WithAuthSecrets or WithAuthKeys, New fails otherwise.
 func WithAuth(opts ...AuthOption) ConfigOption {
 	return func(c *Config) {
 		c.auth = getAuthConfig(&c.auth, opts)
@@ -144,25 +148,20 @@ func WithCompress() ConfigOption {
 	}
 }
 
-// WithSimpleErrorHandler sets the error handler in the configuration. The error handler only logs the error and returns a 500 status code.
+// WithSimpleErrorHandler sets a function which is called with every error, e.g. to log it. The errors are answered by the default error handler, see ProblemErrorHandler.
 func WithSimpleErrorHandler(handler func(error)) ConfigOption {
-	return WithFullErrorHandler(func(ctx *fiber.Ctx, err error) error {
-		handler(err)
-
-		code := fiber.StatusInternalServerError
-		var e *fiber.Error
-		if errors.As(err, &e) {
-			code = e.Code
-		}
-
-		return ctx.SendStatus(code)
-	})
+	return func(c *Config) {
+		c.errorHandler = nil
+		c.errorCallback = handler
+	}
 }
 
 // WithErrorHandler sets the error handler in the configuration. The error handler is a function that takes the context and the error and returns an error.
+// It replaces the default error handler, which answers with problem documents.
 func WithFullErrorHandler(handler func(ctx *fiber.Ctx, err error) error) ConfigOption {
 	return func(c *Config) {
 		c.errorHandler = handler
+		c.errorCallback = nil
 	}
 }
 
@@ -187,6 +186,21 @@ func AutoMigration() ConfigOption {
 	}
 }
 
+// WithBodyLimit sets the maximum size of request bodies in bytes, larger requests are answered with 413 before they reach a route.
+// The default limit is 4 MB (fiber.DefaultBodyLimit); uploads with a maxsize tag above it require a larger limit.
+func WithBodyLimit(limit int) ConfigOption {
+	return func(c *Config) {
+		c.bodyLimit = limit
+	}
+}
+
+// WithOpenAPI serves the given OpenAPI document (e.g. generated by exo generate openapi and embedded using go:embed) at /openapi.json.
+func WithOpenAPI(spec []byte) ConfigOption {
+	return func(c *Config) {
+		c.openAPI = spec
+	}
+}
+
 // CorsConfig is a struct that holds the configuration for CORS.
 type CorsConfig struct {
 	enable           bool
@@ -272,13 +286,15 @@ type AuthConfig struct {
 	enable                  bool
 	hashAlgo                AuthHashAlgo
 	refreshHashAlgo         AuthHashAlgo
-	secrets                 []string // used for the secrets used for the hashing algorithm. For symmetric algorithms, only the first secret is used. For asymmetric algorithms, the first two secrets are used. If refresh token is enabled, the third secret is used for symmetric algorithms and the third and fourth secrets are used for asymmetric algorithms.
-	idenityTokenExp         int64    // used for the expiration time of the identity token
-	refreshToken            bool     // enable refresh token
-	refreshTokenExp         int64    // used for the expiration time of the refresh token. Time in seconds.
-	refreshTokenRotation    bool     // enable refresh token rotation
-	refreshTokenAbsoluteExp int64    // used for the absolute expiration time of the refresh token. Time in seconds.
-	refreshTokenStealDetect bool     // enable steal detection for refresh token
+	keys                    []AuthKey // asymmetric keys with key ids. If set, they are used instead of the secrets for identity tokens and published as JWKS.
+	secrets                 []string  // used for the secrets used for the hashing algorithm. For symmetric algorithms, only the first secret is used. For asymmetric algorithms, the first two secrets are used. If refresh token is enabled, the third secret is used for symmetric algorithms and the third and fourth secrets are used for asymmetric algorithms.
+	idenityTokenExp         int64     // used for the expiration time of the identity token
+	refreshToken            bool      // enable refresh token
+	refreshTokenExp         int64     // used for the expiration time of the refresh token. Time in seconds.
+	refreshTokenRotation    bool      // enable refresh token rotation
+	refreshTokenAbsoluteExp int64     // used for the absolute expiration time of the refresh token. Time in seconds.
+	refreshTokenStealDetect bool      // enable steal detection for refresh token. If a rotated refresh token is used again, the whole token family is revoked.
+	refreshRoute            string    // route of the refresh endpoint
 }
 
 // AuthOption is a function that modifies the authentication configuration.
@@ -311,6 +327,19 @@ func WithAuthSecrets(secrets ...string) AuthOption {
 	}
 }
 
+// WithAuthKeys sets the asymmetric keys in the authentication configuration. Tokens are signed with the newest valid key and verified against every valid key,
+// which allows rotating keys without invalidating issued tokens. The public keys are served at /.well-known/jwks.json.
+// Refresh tokens are signed with the same keys unless refresh secrets are set using WithAuthSecrets.
+func WithAuthKeys(keys ...AuthKey) AuthOption {
+	return func(c *AuthConfig) {
+		if len(keys) == 0 {
+			panic("at least one key must be provided")
+		}
+
+		c.keys = keys
+	}
+}
+
 // WithAuthIdentityTokenExp sets the expiration time of the identity token in the authentication configuration. Time in seconds.
 func WithAuthIdentityTokenExp(exp int64) AuthOption {
 	return func(c *AuthConfig) {
@@ -353,6 +382,13 @@ func WithAuthRefreshTokenStealDetect() AuthOption {
 	}
 }
 
+// WithAuthRefreshRoute sets the route of the refresh endpoint in the authentication configuration. Defaults to /auth/refresh.
+func WithAuthRefreshRoute(route string) AuthOption {
+	return func(c *AuthConfig) {
+		c.refreshRoute = route
+	}
+}
+
 func getAuthConfig(config *AuthConfig, opts []AuthOption) AuthConfig {
 	config.enable = true
 
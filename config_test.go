@@ -0,0 +1,103 @@
+package exo
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestWithBodyLimit(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []ConfigOption
+		size   int
+		status int
+	}{
+		{"default limit", nil, 4 * 1024 * 1024, fiber.StatusOK},
+		{"above default limit", nil, 5 * 1024 * 1024, fiber.StatusRequestEntityTooLarge},
+		{"raised limit", []ConfigOption{WithBodyLimit(10 * 1024 * 1024)}, 5 * 1024 * 1024, fiber.StatusOK},
+		{"lowered limit", []ConfigOption{WithBodyLimit(1024)}, 4096, fiber.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := New(tt.opts...)
+			app.Post("/", func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			conn, err := net.Dial("tcp", listenTestApp(t, app.App))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			// the body is only sent if it is accepted, rejected requests are answered after their headers
+			if _, err := fmt.Fprintf(conn, "POST / HTTP/1.1\r\nHost: test\r\nContent-Length: %d\r\n\r\n", tt.size); err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.status == fiber.StatusOK {
+				if _, err := conn.Write(bytes.Repeat([]byte("x"), tt.size)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			res, err := http.ReadResponse(bufio.NewReader(conn), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if res.StatusCode != tt.status {
+				t.Errorf("expected %d, got %d", tt.status, res.StatusCode)
+			}
+		})
+	}
+}
+
+func TestWithOpenAPI(t *testing.T) {
+	spec := []byte(`{"openapi":"3.1.0"}`)
+
+	tests := []struct {
+		name   string
+		opts   []ConfigOption
+		status int
+		body   string
+	}{
+		{"served", []ConfigOption{WithOpenAPI(spec)}, fiber.StatusOK, string(spec)},
+		{"not configured", nil, fiber.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := New(tt.opts...).Test(httptest.NewRequest(fiber.MethodGet, "/openapi.json", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if res.StatusCode != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, res.StatusCode)
+			}
+
+			if tt.body == "" {
+				return
+			}
+
+			body, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(body) != tt.body || res.Header.Get(fiber.HeaderContentType) != fiber.MIMEApplicationJSON {
+				t.Errorf("expected %s as JSON, got %s as %s", tt.body, body, res.Header.Get(fiber.HeaderContentType))
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package exo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// WatchContext replaces the user context of the request with a context which is cancelled once the client closes the connection and,
+// if timeout is positive, once the timeout has passed. The handler keeps running on the request goroutine, so it has to stop on its own
+// once the context is done. The returned function stops watching the connection and must be called before the handler returns, the
+// generated handlers defer it.
+//
+// Closed connections are detected on unix systems only, other systems cancel the context after the timeout only.
+func WatchContext(c *fiber.Ctx, timeout time.Duration) func() {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(c.UserContext(), timeout)
+	} else {
+		ctx, cancel = context.WithCancel(c.UserContext())
+	}
+
+	c.SetUserContext(ctx)
+	stop := watchDisconnect(c.Context().Conn(), cancel)
+
+	return func() {
+		stop()
+		cancel()
+	}
+}
+
+// TimeoutError returns a 504 error if err was caused by the exceeded deadline of ctx, e.g. by a cancelled database lookup, and err otherwise.
+func TimeoutError(ctx context.Context, err error) error {
+	if errors.Is(err, context.DeadlineExceeded) && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return GatewayTimeout("the request timed out").Wrap(err)
+	}
+
+	return err
+}
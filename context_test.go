@@ -0,0 +1,170 @@
+package exo
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func listenTestApp(t *testing.T, app *fiber.App) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() { _ = app.Listener(ln) }()
+	t.Cleanup(func() { _ = app.Shutdown() })
+
+	return ln.Addr().String()
+}
+
+func TestWatchContextTimeout(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: ProblemErrorHandler(false)})
+	app.Get("/", func(c *fiber.Ctx) error {
+		stop := WatchContext(c, 10*time.Millisecond)
+		defer stop()
+
+		<-c.UserContext().Done()
+		return TimeoutError(c.UserContext(), c.UserContext().Err())
+	})
+
+	res, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.StatusCode != fiber.StatusGatewayTimeout {
+		t.Fatalf("expected 504, got %d", res.StatusCode)
+	}
+}
+
+func TestWatchContextDisconnect(t *testing.T) {
+	started := make(chan struct{})
+	cancelled := make(chan error, 1)
+
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Get("/", func(c *fiber.Ctx) error {
+		stop := WatchContext(c, 0)
+		defer stop()
+
+		close(started)
+		select {
+		case <-c.UserContext().Done():
+			cancelled <- c.UserContext().Err()
+		case <-time.After(5 * time.Second):
+			cancelled <- nil
+		}
+
+		return nil
+	})
+
+	conn, err := net.Dial("tcp", listenTestApp(t, app))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := conn.Write([]byte("GET / HTTP/1.1\r\nHost: test\r\n\r\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	<-started
+	conn.Close()
+
+	if err := <-cancelled; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the context to be cancelled, got %v", err)
+	}
+}
+
+func TestWatchContextKeepAlive(t *testing.T) {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Get("/", func(c *fiber.Ctx) error {
+		stop := WatchContext(c, 0)
+		defer stop()
+
+		time.Sleep(10 * time.Millisecond)
+		if err := c.UserContext().Err(); err != nil {
+			return err
+		}
+
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	conn, err := net.Dial("tcp", listenTestApp(t, app))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// the watcher must neither consume nor block the following requests of the connection
+	reader := bufio.NewReader(conn)
+	for i := 0; i < 3; i++ {
+		if _, err := conn.Write([]byte("GET / HTTP/1.1\r\nHost: test\r\n\r\n")); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := http.ReadResponse(reader, nil)
+		if err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode != fiber.StatusOK {
+			t.Fatalf("request %d: expected 200, got %d", i, res.StatusCode)
+		}
+	}
+}
+
+func TestTimeoutError(t *testing.T) {
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	lookup := fmt.Errorf("loading order: %w", context.DeadlineExceeded)
+	other := errors.New("connection refused")
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		err     error
+		timeout bool
+	}{
+		{"exceeded deadline", expired, lookup, true},
+		{"other error after the deadline", expired, other, false},
+		{"deadline of another context", context.Background(), lookup, false},
+		{"cancelled context", cancelled, lookup, false},
+		{"no error", expired, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := TimeoutError(tt.ctx, tt.err)
+			if !tt.timeout {
+				if err != tt.err {
+					t.Fatalf("expected %v, got %v", tt.err, err)
+				}
+				return
+			}
+
+			var httpErr *HTTPError
+			if !errors.As(err, &httpErr) || httpErr.Status != fiber.StatusGatewayTimeout || !errors.Is(err, tt.err) {
+				t.Errorf("expected 504 wrapping %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
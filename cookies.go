@@ -0,0 +1,52 @@
+package exo
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Cookie is a cookie which is set by a handler returning Cookies.
+type Cookie struct {
+	Name     string
+	Value    string
+	Path     string // path of the cookie. If empty, "/" is used
+	Domain   string
+	Expires  time.Time // if zero and MaxAge is 0, the cookie is a session cookie
+	MaxAge   int       // lifetime in seconds, takes precedence over Expires
+	Secure   bool
+	HTTPOnly bool
+	SameSite string // Lax, Strict or None
+}
+
+// Cookies are the cookies a handler sets. Handlers may return them in addition to their other return values, they are set before
+// the response is sent.
+type Cookies []Cookie
+
+// ExpireCookie returns a cookie which deletes the cookie with the given name and path "/" on the client, e.g. on logout.
+func ExpireCookie(name string) Cookie {
+	return Cookie{Name: name, Path: "/", Expires: time.Unix(0, 0)}
+}
+
+// SetCookies adds a Set-Cookie header for every cookie to the response.
+func SetCookies(c *fiber.Ctx, cookies Cookies) {
+	for _, cookie := range cookies {
+		path := cookie.Path
+		if path == "" {
+			path = "/"
+		}
+
+		c.Cookie(&fiber.Cookie{
+			Name:        cookie.Name,
+			Value:       cookie.Value,
+			Path:        path,
+			Domain:      cookie.Domain,
+			MaxAge:      cookie.MaxAge,
+			Expires:     cookie.Expires,
+			Secure:      cookie.Secure,
+			HTTPOnly:    cookie.HTTPOnly,
+			SameSite:    cookie.SameSite,
+			SessionOnly: cookie.Expires.IsZero() && cookie.MaxAge == 0,
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package exo
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestSetCookies(t *testing.T) {
+	tests := []struct {
+		name    string
+		cookies Cookies
+		want    []string
+	}{
+		{"no cookies", nil, nil},
+		{"session cookie", Cookies{{Name: "session", Value: "abc"}}, []string{"session=abc; path=/; SameSite=Lax"}},
+		{"attributes", Cookies{{Name: "theme", Value: "dark", Path: "/app", Domain: "example.com", MaxAge: 3600, Secure: true, HTTPOnly: true, SameSite: "Strict"}}, []string{
+			"theme=dark; max-age=3600; domain=example.com; path=/app; HttpOnly; secure; SameSite=Strict",
+		}},
+		{"expires and SameSite None", Cookies{{Name: "a", Value: "1", Expires: time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC), SameSite: "None"}}, []string{
+			"a=1; expires=Wed, 02 Jan 2030 03:04:05 GMT; path=/; secure; SameSite=None",
+		}},
+		{"expired cookie", Cookies{ExpireCookie("session")}, []string{"session=; expires=Thu, 01 Jan 1970 00:00:00 GMT; path=/; SameSite=Lax"}},
+		{"several cookies", Cookies{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}}, []string{"a=1; path=/; SameSite=Lax", "b=2; path=/; SameSite=Lax"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error {
+				SetCookies(c, tt.cookies)
+				return nil
+			})
+
+			res, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := res.Header.Values(fiber.HeaderSetCookie); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
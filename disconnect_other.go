@@ -0,0 +1,13 @@
+//go:build !unix
+
+package exo
+
+import (
+	"context"
+	"net"
+)
+
+// watchDisconnect does not detect closed connections on this system.
+func watchDisconnect(net.Conn, context.CancelFunc) func() {
+	return func() {}
+}
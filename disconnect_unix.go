@@ -0,0 +1,60 @@
+//go:build unix
+
+package exo
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"syscall"
+	"time"
+)
+
+// watchDisconnect calls cancel once the peer closes conn. It waits for the connection to become readable without reading from it, so
+// the next request of a keep-alive connection stays untouched; watching stops as soon as data arrives. The returned function stops
+// watching and waits until the watcher is gone, so the connection is not used after it returned.
+func watchDisconnect(conn net.Conn, cancel context.CancelFunc) func() {
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		conn = tlsConn.NetConn()
+	}
+
+	sysConn, ok := conn.(syscall.Conn)
+	if !ok {
+		return func() {}
+	}
+
+	raw, err := sysConn.SyscallConn()
+	if err != nil {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		buf := make([]byte, 1)
+		closed := false
+
+		// Read returns once the function returned true or the read deadline set by stop passed
+		_ = raw.Read(func(fd uintptr) bool {
+			n, _, err := syscall.Recvfrom(int(fd), buf, syscall.MSG_PEEK|syscall.MSG_DONTWAIT)
+			if err == syscall.EAGAIN || err == syscall.EINTR {
+				return false
+			}
+
+			closed = err != nil || n == 0
+			return true
+		})
+
+		if closed {
+			cancel()
+		}
+	}()
+
+	return func() {
+		// fasthttp sets its own read deadlines before it reads the next request
+		_ = conn.SetReadDeadline(time.Unix(1, 0))
+		<-done
+		_ = conn.SetReadDeadline(time.Time{})
+	}
+}
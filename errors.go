@@ -0,0 +1,143 @@
+package exo
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// HTTPError is an error which is answered with its status code and a problem document. Handlers return it, also wrapped by other errors,
+// to answer with a client error instead of 500.
+type HTTPError struct {
+	Status int
+	Detail string       // human-readable explanation sent to the client
+	Errors []FieldError // invalid fields of the request
+	Err    error        // cause of the error, which is only sent to the client if the framework runs locally
+}
+
+func (e *HTTPError) Error() string {
+	msg := fmt.Sprintf("%d %s", e.Status, utils.StatusMessage(e.Status))
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+
+	return msg
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// Wrap sets the cause of the error, e.g. exo.NotFound("order not found").Wrap(err).
+func (e *HTTPError) Wrap(err error) *HTTPError {
+	e.Err = err
+	return e
+}
+
+// Problem returns the problem document of the error. The cause is only added to the detail if local is true.
+func (e *HTTPError) Problem(local bool) Problem {
+	detail := e.Detail
+	if local && e.Err != nil {
+		detail = e.Err.Error()
+		if e.Detail != "" {
+			detail = e.Detail + ": " + detail
+		}
+	}
+
+	return Problem{
+		Title:  utils.StatusMessage(e.Status),
+		Status: e.Status,
+		Detail: detail,
+		Errors: e.Errors,
+	}
+}
+
+// NewHTTPError creates an error which is answered with the given status code.
+func NewHTTPError(status int, detail string) *HTTPError {
+	return &HTTPError{Status: status, Detail: detail}
+}
+
+// BadRequest creates an error which is answered with 400.
+func BadRequest(detail string) *HTTPError {
+	return NewHTTPError(fiber.StatusBadRequest, detail)
+}
+
+// Unauthorized creates an error which is answered with 401.
+func Unauthorized(detail string) *HTTPError {
+	return NewHTTPError(fiber.StatusUnauthorized, detail)
+}
+
+// Forbidden creates an error which is answered with 403.
+func Forbidden(detail string) *HTTPError {
+	return NewHTTPError(fiber.StatusForbidden, detail)
+}
+
+// NotFound creates an error which is answered with 404.
+func NotFound(detail string) *HTTPError {
+	return NewHTTPError(fiber.StatusNotFound, detail)
+}
+
+// Conflict creates an error which is answered with 409.
+func Conflict(detail string) *HTTPError {
+	return NewHTTPError(fiber.StatusConflict, detail)
+}
+
+// GatewayTimeout creates an error which is answered with 504.
+func GatewayTimeout(detail string) *HTTPError {
+	return NewHTTPError(fiber.StatusGatewayTimeout, detail)
+}
+
+// Unprocessable creates an error which is answered with 422 and lists the invalid fields, e.g. for requests which are well-formed but
+// violate business rules.
+func Unprocessable(errs ValidationErrors) *HTTPError {
+	detail := "The request has an invalid field."
+	if len(errs) != 1 {
+		detail = fmt.Sprintf("The request has %d invalid fields.", len(errs))
+	}
+
+	return &HTTPError{Status: fiber.StatusUnprocessableEntity, Detail: detail, Errors: errs}
+}
+
+// ProblemErrorHandler returns the error handler which is installed by New unless WithFullErrorHandler is used. Errors are answered
+// with problem documents: *HTTPError and *fiber.Error with their status code, every other error with 500. The messages of other errors
+// and the causes of HTTP errors are only sent if local is true, otherwise they are logged.
+func ProblemErrorHandler(local bool) fiber.ErrorHandler {
+	return func(c *fiber.Ctx, err error) error {
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) {
+			return SendProblem(c, httpErr.Problem(local))
+		}
+
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			problem := Problem{Title: utils.StatusMessage(fiberErr.Code), Status: fiberErr.Code}
+
+			// fiber uses the status message if no message is given
+			if fiberErr.Message != problem.Title {
+				problem.Detail = fiberErr.Message
+			}
+
+			return SendProblem(c, problem)
+		}
+
+		problem := Problem{
+			Title:  utils.StatusMessage(fiber.StatusInternalServerError),
+			Status: fiber.StatusInternalServerError,
+		}
+
+		if local {
+			problem.Detail = err.Error()
+		} else {
+			log.Errorf("%s %s: %v", c.Method(), c.OriginalURL(), err)
+		}
+
+		return SendProblem(c, problem)
+	}
+}
@@ -0,0 +1,114 @@
+package exo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestHTTPError(t *testing.T) {
+	cause := errors.New("record not found")
+
+	tests := []struct {
+		name     string
+		err      *HTTPError
+		msg      string
+		local    Problem
+		nonLocal Problem
+	}{
+		{"detail", NotFound("order not found"), "404 Not Found: order not found",
+			Problem{Title: "Not Found", Status: 404, Detail: "order not found"},
+			Problem{Title: "Not Found", Status: 404, Detail: "order not found"}},
+		{"cause", NotFound("order not found").Wrap(cause), "404 Not Found: order not found: record not found",
+			Problem{Title: "Not Found", Status: 404, Detail: "order not found: record not found"},
+			Problem{Title: "Not Found", Status: 404, Detail: "order not found"}},
+		{"cause without detail", NewHTTPError(fiber.StatusConflict, "").Wrap(cause), "409 Conflict: record not found",
+			Problem{Title: "Conflict", Status: 409, Detail: "record not found"},
+			Problem{Title: "Conflict", Status: 409}},
+		{"no detail", Forbidden(""), "403 Forbidden",
+			Problem{Title: "Forbidden", Status: 403},
+			Problem{Title: "Forbidden", Status: 403}},
+		{"invalid fields", Unprocessable(ValidationErrors{{Field: "qty", Source: "body", Code: "stock", Message: "qty exceeds the stock"}}), "422 Unprocessable Entity: The request has an invalid field.",
+			Problem{Title: "Unprocessable Entity", Status: 422, Detail: "The request has an invalid field.", Errors: []FieldError{{Field: "qty", Source: "body", Code: "stock", Message: "qty exceeds the stock"}}},
+			Problem{Title: "Unprocessable Entity", Status: 422, Detail: "The request has an invalid field.", Errors: []FieldError{{Field: "qty", Source: "body", Code: "stock", Message: "qty exceeds the stock"}}}},
+		{"several invalid fields", Unprocessable(ValidationErrors{{Field: "a"}, {Field: "b"}}), "422 Unprocessable Entity: The request has 2 invalid fields.",
+			Problem{Title: "Unprocessable Entity", Status: 422, Detail: "The request has 2 invalid fields.", Errors: []FieldError{{Field: "a"}, {Field: "b"}}},
+			Problem{Title: "Unprocessable Entity", Status: 422, Detail: "The request has 2 invalid fields.", Errors: []FieldError{{Field: "a"}, {Field: "b"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err.Error() != tt.msg {
+				t.Errorf("expected message %q, got %q", tt.msg, tt.err.Error())
+			}
+
+			if got := tt.err.Problem(true); !reflect.DeepEqual(got, tt.local) {
+				t.Errorf("expected local problem %+v, got %+v", tt.local, got)
+			}
+
+			if got := tt.err.Problem(false); !reflect.DeepEqual(got, tt.nonLocal) {
+				t.Errorf("expected problem %+v, got %+v", tt.nonLocal, got)
+			}
+
+			if (tt.err.Err != nil) != errors.Is(tt.err, cause) {
+				t.Errorf("expected the cause to be unwrapped")
+			}
+		})
+	}
+}
+
+func TestProblemErrorHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		local   bool
+		problem Problem
+	}{
+		{"http error", BadRequest("invalid order"), false, Problem{Title: "Bad Request", Status: 400, Detail: "invalid order"}},
+		{"wrapped http error", fmt.Errorf("loading order: %w", NotFound("order not found")), false, Problem{Title: "Not Found", Status: 404, Detail: "order not found"}},
+		{"cause of a local http error", Conflict("duplicate").Wrap(errors.New("unique constraint")), true, Problem{Title: "Conflict", Status: 409, Detail: "duplicate: unique constraint"}},
+		{"cause of an http error", Conflict("duplicate").Wrap(errors.New("unique constraint")), false, Problem{Title: "Conflict", Status: 409, Detail: "duplicate"}},
+		{"fiber error", fiber.NewError(fiber.StatusTeapot, "short and stout"), false, Problem{Title: "I'm a teapot", Status: 418, Detail: "short and stout"}},
+		{"fiber error without message", fiber.ErrMethodNotAllowed, false, Problem{Title: "Method Not Allowed", Status: 405}},
+		{"local error", errors.New("connection refused"), true, Problem{Title: "Internal Server Error", Status: 500, Detail: "connection refused"}},
+		{"error", errors.New("connection refused"), false, Problem{Title: "Internal Server Error", Status: 500}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{ErrorHandler: ProblemErrorHandler(tt.local)})
+			app.Get("/", func(c *fiber.Ctx) error {
+				return tt.err
+			})
+
+			res, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if res.StatusCode != tt.problem.Status || res.Header.Get(fiber.HeaderContentType) != ProblemContentType {
+				t.Fatalf("expected %d %s, got %d %s", tt.problem.Status, ProblemContentType, res.StatusCode, res.Header.Get(fiber.HeaderContentType))
+			}
+
+			body, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var problem Problem
+			if err := json.Unmarshal(body, &problem); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(problem, tt.problem) {
+				t.Errorf("expected %+v, got %+v", tt.problem, problem)
+			}
+		})
+	}
+}
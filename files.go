@@ -0,0 +1,49 @@
+package exo
+
+import (
+	"mime"
+	"mime/multipart"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// FormFile returns the first file uploaded with the given key of a multipart form, or nil if there is none.
+func FormFile(c *fiber.Ctx, key string) *multipart.FileHeader {
+	if files := FormFiles(c, key); len(files) > 0 {
+		return files[0]
+	}
+
+	return nil
+}
+
+// FormFiles returns all files uploaded with the given key of a multipart form.
+func FormFiles(c *fiber.Ctx, key string) []*multipart.FileHeader {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil
+	}
+
+	return form.File[key]
+}
+
+// AcceptsFile reports whether the content type of the file is one of the given media types. Types like image/* accept every subtype.
+// The content type is the one sent by the client, the content of the file is not inspected.
+func AcceptsFile(file *multipart.FileHeader, accept ...string) bool {
+	mediaType, _, err := mime.ParseMediaType(file.Header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+
+	for _, allowed := range accept {
+		if strings.EqualFold(mediaType, allowed) {
+			return true
+		}
+
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok && strings.HasPrefix(strings.ToLower(mediaType), strings.ToLower(prefix)+"/") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,101 @@
+package exo
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http/httptest"
+	"net/textproto"
+	"reflect"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAcceptsFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		accept      []string
+		want        bool
+	}{
+		{"exact type", "image/png", []string{"image/png"}, true},
+		{"one of the types", "image/jpeg", []string{"image/png", "image/jpeg"}, true},
+		{"case", "Image/PNG", []string{"image/png"}, true},
+		{"parameters", "text/plain; charset=utf-8", []string{"text/plain"}, true},
+		{"wildcard", "image/webp", []string{"image/*"}, true},
+		{"wildcard of another type", "application/pdf", []string{"image/*"}, false},
+		{"prefix of the type", "imagex/png", []string{"image/*"}, false},
+		{"other type", "image/gif", []string{"image/png"}, false},
+		{"missing type", "", []string{"image/png"}, false},
+		{"no accepted types", "image/png", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := &multipart.FileHeader{Header: textproto.MIMEHeader{}}
+			if tt.contentType != "" {
+				file.Header.Set("Content-Type", tt.contentType)
+			}
+
+			if got := AcceptsFile(file, tt.accept...); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFormFiles(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer := multipart.NewWriter(buf)
+	for _, name := range []string{"a.png", "b.png"} {
+		if _, err := writer.CreateFormFile("attachments", name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := writer.CreateFormFile("avatar", "c.png"); err != nil {
+		t.Fatal(err)
+	}
+	_ = writer.Close()
+
+	tests := []struct {
+		name        string
+		key         string
+		contentType string
+		files       []string
+		file        string
+	}{
+		{"several files", "attachments", writer.FormDataContentType(), []string{"a.png", "b.png"}, "a.png"},
+		{"one file", "avatar", writer.FormDataContentType(), []string{"c.png"}, "c.png"},
+		{"missing file", "other", writer.FormDataContentType(), []string{}, ""},
+		{"no multipart form", "avatar", fiber.MIMEApplicationForm, []string{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var files []string
+			var file string
+			app := fiber.New()
+			app.Post("/", func(c *fiber.Ctx) error {
+				files = []string{}
+				for _, f := range FormFiles(c, tt.key) {
+					files = append(files, f.Filename)
+				}
+
+				if f := FormFile(c, tt.key); f != nil {
+					file = f.Filename
+				}
+				return nil
+			})
+
+			req := httptest.NewRequest(fiber.MethodPost, "/", bytes.NewReader(buf.Bytes()))
+			req.Header.Set(fiber.HeaderContentType, tt.contentType)
+			if _, err := app.Test(req); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(files, tt.files) || file != tt.file {
+				t.Errorf("expected %q and %q, got %q and %q", tt.files, tt.file, files, file)
+			}
+		})
+	}
+}
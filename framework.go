@@ -26,6 +26,7 @@ import (
 type Framework struct {
 	*fiber.App
 	config   Config
+	auth     *Auth
 	Migrator *migrator.Migrator
 }
 
@@ -35,15 +36,29 @@ func New(opts ...ConfigOption) *Framework {
 	config := getConfig(opts)
 	mig := migrator.New()
 
+	errorHandler := config.errorHandler
+	if errorHandler == nil {
+		errorHandler = ProblemErrorHandler(config.local)
+	}
+
+	if callback := config.errorCallback; callback != nil {
+		problemHandler := errorHandler
+		errorHandler = func(c *fiber.Ctx, err error) error {
+			callback(err)
+			return problemHandler(c, err)
+		}
+	}
+
 	app := &Framework{fiber.New(fiber.Config{
-		ErrorHandler: config.errorHandler,
+		ErrorHandler: errorHandler,
+		BodyLimit:    config.bodyLimit,
 		JSONDecoder: func(data []byte, v interface{}) error {
 			return json.Unmarshal(data, v)
 		},
 		JSONEncoder: func(v interface{}) ([]byte, error) {
 			return json.Marshal(v)
 		},
-	}), config, mig}
+	}), config, nil, mig}
 
 	app.Use(recover.New(recover.Config{
 		EnableStackTrace: true,
@@ -83,9 +98,40 @@ func New(opts ...ConfigOption) *Framework {
 		}))
 	}
 
+	if config.openAPI != nil {
+		app.Get("/openapi.json", func(c *fiber.Ctx) error {
+			c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			return c.Send(config.openAPI)
+		})
+	}
+
+	if config.auth.enable {
+		auth, err := newAuth(config.auth, mig)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		app.auth = auth
+
+		if auth.keys.asymmetric() {
+			app.Get("/.well-known/jwks.json", auth.keys.jwksHandler)
+		}
+
+		if config.auth.refreshToken {
+			app.Post(config.auth.refreshRoute, auth.refreshHandler)
+		}
+
+		app.Use(auth.handler)
+	}
+
 	return app
 }
 
+// Auth returns the authentication subsystem of the framework. It returns nil if authentication is not enabled using WithAuth.
+func (f *Framework) Auth() *Auth {
+	return f.auth
+}
+
 // Start starts the server. It will block the current goroutine and fatal out if the server fails to start.
 // It will also listen for the interrupt signal and gracefully shutdown the server upon receiving SIGINT.
 // The DB's Connect function must be called before calling Start.
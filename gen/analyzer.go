@@ -4,92 +4,272 @@ import (
 	"errors"
 	"fmt"
 	"go/ast"
+	"go/constant"
 	"go/parser"
 	"go/token"
+	"go/types"
+	"io"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/tools/go/packages"
 )
 
-// Analyze analyzes the given directory for packages and requests files.
+const exoPkgPath = "github.com/exo-framework/exo"
+
+// generatedHeader is the first line of the files generated by exo.
+const generatedHeader = "// Code generated by exo. DO NOT EDIT."
+
+var typeNameRegex = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// Analyze analyzes the given directory and its subdirectories for packages and requests files.
+// Directories of other modules, vendor and testdata directories and ignored paths are skipped.
+// The packages are loaded and type checked, so field and return types may come from any package.
 func (g *Generator) Analyze(dir string) error {
+	dirs := []string{}
+	if err := g.collectDirs(dir, &dirs); err != nil {
+		return err
+	}
+
+	if len(dirs) == 0 {
+		return nil
+	}
+
+	return g.loadPackages(dirs)
+}
+
+func (g *Generator) collectDirs(dir string, dirs *[]string) error {
 	files, err := os.ReadDir(dir)
 	if err != nil {
 		return err
 	}
 
-	reqFiles := make([]RequestsFile, 0, len(files))
+	hasSources := false
 
 	for _, file := range files {
+		p := filepath.Join(dir, file.Name())
+		if g.skip(p, file) {
+			continue
+		}
+
 		if file.IsDir() {
-			if err := g.Analyze(filepath.Join(dir, file.Name())); err != nil {
+			if err := g.collectDirs(p, dirs); err != nil {
 				return err
 			}
 			continue
 		}
 
-		if strings.HasSuffix(file.Name(), ".go") && !strings.HasSuffix(file.Name(), "_gen.go") {
-			if err := g.analyzeFile(filepath.Join(dir, file.Name()), &reqFiles); err != nil {
-				return err
-			}
+		if strings.HasSuffix(file.Name(), ".go") && !strings.HasSuffix(file.Name(), "_gen.go") && !strings.HasSuffix(file.Name(), "_test.go") {
+			hasSources = true
+		}
+
+		// other generators like msgp name their files _gen.go too
+		if strings.HasSuffix(file.Name(), "_gen.go") && isExoGenerated(p) {
+			g.existing = append(g.existing, p)
 		}
 	}
 
-	if len(reqFiles) > 0 {
-		g.packages[dir] = reqFiles
+	if hasSources {
+		*dirs = append(*dirs, dir)
 	}
 
 	return nil
 }
 
-func (g *Generator) analyzeFile(filePath string, files *[]RequestsFile) error {
-	fset := token.NewFileSet()
-	node, err := parser.ParseFile(fset, filePath, nil, parser.ParseComments)
+func (g *Generator) loadPackages(dirs []string) error {
+	patterns := make([]string, 0, len(dirs))
+	byPath := make(map[string]string)
+	overlay := make(map[string][]byte)
+
+	for _, dir := range dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+
+		patterns = append(patterns, abs)
+		byPath[abs] = dir
+
+		// previously generated files may be stale, so they must not take part in the type checking
+		generated, err := filepath.Glob(filepath.Join(abs, "*_gen.go"))
+		if err != nil {
+			return err
+		}
+
+		for _, file := range generated {
+			node, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.PackageClauseOnly)
+			if err != nil {
+				return err
+			}
+
+			overlay[file] = []byte("package " + node.Name.Name + "\n")
+		}
+	}
+
+	pkgs, err := packages.Load(&packages.Config{
+		Mode:    packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedImports | packages.NeedDeps | packages.NeedTypes | packages.NeedTypesInfo,
+		Dir:     g.root,
+		Overlay: overlay,
+	}, patterns...)
 	if err != nil {
 		return err
 	}
 
-	reqFile := RequestsFile{
-		FileName:  filePath,
-		Package:   node.Name.Name,
-		Imports:   make(map[string]string),
-		Requests:  []Request{},
-		Functions: []Function{},
-	}
-
-	for _, decl := range node.Decls {
-		switch d := decl.(type) {
-		case *ast.GenDecl:
-			if d.Tok == token.IMPORT {
-				for _, spec := range d.Specs {
-					importSpec := spec.(*ast.ImportSpec)
-					importPath := strings.Trim(importSpec.Path.Value, `"`)
-					importName := ""
-					if importSpec.Name != nil {
-						importName = importSpec.Name.Name
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) > 0 {
+			errs := make([]error, 0, len(pkg.Errors))
+			for _, err := range pkg.Errors {
+				errs = append(errs, err)
+			}
+
+			return errors.Join(errs...)
+		}
+
+		if len(pkg.GoFiles) == 0 {
+			continue
+		}
+
+		dir, ok := byPath[filepath.Dir(pkg.GoFiles[0])]
+		if !ok {
+			continue
+		}
+
+		if err := g.analyzePackage(dir, pkg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (g *Generator) analyzePackage(dir string, pkg *packages.Package) error {
+	resolver := newTypeResolver(pkg.Types)
+	reqFiles := make([]RequestsFile, 0, len(pkg.Syntax))
+	functions := []Function{}
+	var group *Group
+
+	for _, node := range pkg.Syntax {
+		filePath := pkg.Fset.File(node.Pos()).Name()
+		if strings.HasSuffix(filePath, "_gen.go") {
+			continue
+		}
+
+		reqFile := RequestsFile{
+			FileName:  filepath.Join(dir, filepath.Base(filePath)),
+			Package:   pkg.Name,
+			PkgPath:   pkg.PkgPath,
+			Imports:   make(map[string]string),
+			Requests:  []Request{},
+			Functions: []Function{},
+		}
+
+		for _, decl := range node.Decls {
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				if d.Tok == token.IMPORT {
+					for _, spec := range d.Specs {
+						importSpec := spec.(*ast.ImportSpec)
+						importPath := strings.Trim(importSpec.Path.Value, `"`)
+						importName := ""
+						if importSpec.Name != nil {
+							importName = importSpec.Name.Name
+						}
+						reqFile.Imports[importPath] = importName
 					}
-					reqFile.Imports[importPath] = importName
-				}
-			} else if d.Tok == token.TYPE {
-				for _, spec := range d.Specs {
-					typeSpec := spec.(*ast.TypeSpec)
-					if structType, ok := typeSpec.Type.(*ast.StructType); ok {
-						g.extractRequestStruct(typeSpec.Name.Name, structType, &reqFile)
+				} else if d.Tok == token.TYPE {
+					for _, spec := range d.Specs {
+						typeSpec := spec.(*ast.TypeSpec)
+						if typeSpec.Assign.IsValid() || typeSpec.TypeParams != nil {
+							continue
+						}
+
+						obj, ok := pkg.TypesInfo.Defs[typeSpec.Name].(*types.TypeName)
+						if !ok {
+							continue
+						}
+
+						if structType, ok := obj.Type().Underlying().(*types.Struct); ok {
+							if err := g.extractRequestStruct(typeSpec.Name.Name, structType, &reqFile, resolver, pkg.Fset); err != nil {
+								return err
+							}
+						}
+					}
+				} else if d.Tok == token.VAR {
+					for _, spec := range d.Specs {
+						found, err := extractGroup(spec.(*ast.ValueSpec), pkg.TypesInfo)
+						if err != nil {
+							return err
+						}
+
+						if found != nil && group != nil {
+							return errors.Join(ErrInvalidGroup, fmt.Errorf("package %s declares the groups %s and %s", pkg.Name, group.Var, found.Var))
+						}
+
+						if found != nil {
+							group = found
+						}
 					}
 				}
+
+			case *ast.FuncDecl:
+				g.extractFunction(d, pkg.TypesInfo, &reqFile, resolver)
 			}
+		}
+
+		functions = append(functions, reqFile.Functions...)
+		reqFiles = append(reqFiles, reqFile)
+	}
 
-		case *ast.FuncDecl:
-			g.extractFunction(d, &reqFile)
+	// handlers and validators may be declared in any file of the package
+	analyzed := make([]RequestsFile, 0, len(reqFiles))
+	for _, reqFile := range reqFiles {
+		if len(reqFile.Requests) == 0 {
+			continue
+		}
+
+		if err := g.linkFunctions(&reqFile, functions); err != nil {
+			return err
 		}
+
+		if err := checkMiddleware(reqFile, pkg.Types.Scope()); err != nil {
+			return err
+		}
+
+		// the group may be declared in any file of the package
+		reqFile.Group = group
+		if group != nil {
+			for i := range reqFile.Requests {
+				reqFile.Requests[i].Prefix = group.Prefix
+			}
+		}
+
+		analyzed = append(analyzed, reqFile)
 	}
 
+	if err := checkController(analyzed); err != nil {
+		return err
+	}
+
+	if len(analyzed) > 0 {
+		g.packages[dir] = analyzed
+	}
+
+	return nil
+}
+
+func (g *Generator) linkFunctions(reqFile *RequestsFile, functions []Function) error {
 	for k, req := range reqFile.Requests {
 		for i, field := range req.Fields {
 			if field.Validator != nil {
-				for _, fn := range reqFile.Functions {
-					if fn.Name == *field.Validator {
+				for _, fn := range functions {
+					if fn.Name == *field.Validator && fn.Receiver == "" {
 						field.ValidaotrFunc = &fn
 						req.Fields[i] = field
 						break
@@ -102,7 +282,7 @@ func (g *Generator) analyzeFile(filePath string, files *[]RequestsFile) error {
 			}
 		}
 
-		for _, fn := range reqFile.Functions {
+		for _, fn := range functions {
 			for _, param := range fn.Params {
 				if param == req.StructName {
 					req.Handler = &fn
@@ -120,221 +300,911 @@ func (g *Generator) analyzeFile(filePath string, files *[]RequestsFile) error {
 			return ErrHandlerIllegalSignature
 		}
 
-		for _, ret := range req.Handler.Returns {
-			if !g.isAllowedReturnType(ret) {
-				return ErrHandlerIllegalSignature
+		if req.Handler.ReturnErr != nil {
+			return errors.Join(ErrInvalidValidateTag, req.Handler.ReturnErr)
+		}
+
+		for i, kind := range req.Handler.ReturnKinds {
+			if kind == "" {
+				return errors.Join(ErrHandlerIllegalSignature, fmt.Errorf("handler: %s returns %s", req.Handler.Name, req.Handler.Returns[i]))
+			}
+
+			// the response carries status, cookies and body on its own
+			for j, other := range req.Handler.ReturnKinds {
+				if kind == ReturnResponse && j != i && other != ReturnError {
+					return errors.Join(ErrHandlerIllegalSignature, fmt.Errorf("handler: %s returns %s, which can only be combined with an error", req.Handler.Name, req.Handler.Returns[i]))
+				}
 			}
 		}
 	}
 
-	*files = append(*files, reqFile)
 	return nil
 }
 
-func (g *Generator) extractRequestStruct(name string, structType *ast.StructType, reqFile *RequestsFile) {
+func (g *Generator) extractRequestStruct(name string, structType *types.Struct, reqFile *RequestsFile, resolver *typeResolver, fset *token.FileSet) error {
 	var req Request
 	req.StructName = name
 	req.Route = ""
 	req.Method = ""
 	req.Fields = []Field{}
 
-	for _, field := range structType.Fields.List {
-		if len(field.Names) == 0 {
-			if ident, ok := field.Type.(*ast.SelectorExpr); ok {
-				if x, ok := ident.X.(*ast.Ident); ok && x.Name == "exo" {
-					req.Method = Method(ident.Sel.Name)
-
-					tagValue := ""
-					if field.Tag != nil {
-						tagValue = field.Tag.Value
-						tagValue = strings.Trim(tagValue, "`")
-					}
-
-					tagParts := strings.Split(tagValue, " ")
-					for i := 0; i < len(tagParts); i++ {
-						tag := tagParts[i]
-						if !strings.HasPrefix(tag, "route:") {
-							continue
-						}
+	for f := 0; f < structType.NumFields(); f++ {
+		if method, ok := exoMethod(structType.Field(f).Type()); ok && structType.Field(f).Embedded() {
+			req.Method = method
 
-						route := strings.TrimPrefix(tag, "route:")
-						route = strings.TrimPrefix(route, `"`)
-						route = strings.TrimSuffix(route, `"`)
+			tag := reflect.StructTag(structType.Tag(f))
+			req.Route = tag.Get("route")
+			req.Roles = splitTagList(tag.Get("roles"))
+			req.Scopes = splitTagList(tag.Get("scopes"))
+			req.Middleware = splitTagList(tag.Get("use"))
 
-						req.Route = route
-					}
+			if value, ok := tag.Lookup("timeout"); ok {
+				timeout, err := time.ParseDuration(value)
+				if err != nil || timeout <= 0 {
+					return errors.Join(ErrInvalidTimeoutTag, fmt.Errorf("timeout of struct %s must be a positive duration like 5s, got %q", name, value))
 				}
+
+				req.Timeout = timeout
 			}
+		}
+	}
+
+	// other structs are only used as field types
+	if req.Method == "" {
+		return nil
+	}
+
+	for f := 0; f < structType.NumFields(); f++ {
+		field := structType.Field(f)
+		tagValue := structType.Tag(f)
+
+		// untagged unexported fields are left to the handler
+		if field.Embedded() || (!field.Exported() && tagValue == "") {
 			continue
 		}
 
-		fieldName := field.Names[0].Name
-		fieldType := ""
-		if ident, ok := field.Type.(*ast.Ident); ok {
-			fieldType = ident.Name
-		} else if star, ok := field.Type.(*ast.StarExpr); ok {
-			if ident, ok := star.X.(*ast.Ident); ok {
-				fieldType = "*" + ident.Name
-			}
-		} else if sel, ok := field.Type.(*ast.SelectorExpr); ok {
-			if x, ok := sel.X.(*ast.Ident); ok {
-				fieldType = x.Name + "." + sel.Sel.Name
-			}
-		} else if s, ok := field.Type.(*ast.StructType); ok {
-			fieldType = "struct {"
-			for _, field := range s.Fields.List {
-				if len(field.Names) == 0 {
-					continue
-				}
+		fieldName := field.Name()
 
-				fieldName := field.Names[0].Name
-				fieldType += fmt.Sprintf(" %s %s", fieldName, field.Type)
-				if field.Tag != nil {
-					fieldType += fmt.Sprintf(" %s", field.Tag.Value)
-				}
+		fieldTypeEnum := FieldType("")
+		fieldKey := ""
+		tag := reflect.StructTag(tagValue)
 
-				fieldType += ";"
+		for _, source := range []FieldType{FieldPath, FieldQuery, FieldHeader, FieldCookie, FieldBody, FieldForm, FieldFile} {
+			if key, ok := tag.Lookup(string(source)); ok {
+				fieldTypeEnum = source
+				fieldKey = key
 			}
-			fieldType += " }"
 		}
 
-		tagValue := ""
-		if field.Tag != nil {
-			tagValue = field.Tag.Value
-			tagValue = strings.Trim(tagValue, "`")
+		authOptional := false
+		if auth, ok := tag.Lookup("auth"); ok {
+			fieldTypeEnum = FieldAuth
+			authOptional = strings.EqualFold(auth, "optional")
 		}
 
-		fieldTypeEnum := FieldType("")
-		tagParts := strings.Split(tagValue, " ")
-		fieldKey := ""
-		notEmpty := false
+		fromDbClause, err := parseDBLoad(tag, field.Type())
+		if err != nil {
+			return errors.Join(ErrInvalidDBTag, fmt.Errorf("field %s in struct %s: %w", fieldName, name, err))
+		}
 
-		var fromDbClause *string
-		var validator *string
-
-		for i := 0; i < len(tagParts); i++ {
-			tag := tagParts[i]
-			pair := strings.Split(tag, ":")
-
-			tagKey := pair[0]
-			tagVal := pair[1]
-
-			tagVal = strings.TrimPrefix(tagVal, `"`)
-			tagVal = strings.TrimSuffix(tagVal, `"`)
-
-			switch tagKey {
-			case "path":
-				fieldTypeEnum = FieldPath
-				fieldKey = tagVal
-			case "query":
-				fieldTypeEnum = FieldQuery
-				fieldKey = tagVal
-			case "header":
-				fieldTypeEnum = FieldHeader
-				fieldKey = tagVal
-			case "body":
-				fieldTypeEnum = FieldBody
-				fieldKey = tagVal
-			case "form":
-				fieldTypeEnum = FieldForm
-				fieldKey = tagVal
-			case "db":
-				fromDbClause = &tagVal
-			case "validate":
-				if strings.EqualFold(tagVal, "notempty") {
-					notEmpty = true
-					continue
-				}
+		// fields looked up by the parameters of other fields have no parameter of their own
+		if fromDbClause != nil && fromDbClause.Keys[0].Param != "" {
+			if fieldTypeEnum != "" {
+				return errors.Join(ErrInvalidDBTag, fmt.Errorf("field %s in struct %s: fields looked up by other parameters cannot have a %s tag", fieldName, name, fieldTypeEnum))
+			}
 
-				validator = &tagVal
+			if tag.Get("validate") != "" {
+				return errors.Join(ErrInvalidDBTag, fmt.Errorf("field %s in struct %s: fields looked up by other parameters are validated by these parameters", fieldName, name))
 			}
+
+			fieldTypeEnum = FieldDB
+		} else if fromDbClause != nil && fieldTypeEnum == "" {
+			return errors.Join(ErrInvalidDBTag, fmt.Errorf("field %s in struct %s: the column %s must be compared with a path, query, header, cookie or form parameter", fieldName, name, fromDbClause.Keys[0].Column))
+		} else if fieldTypeEnum == "" {
+			return errors.Join(ErrMissingSourceTag, fmt.Errorf("%s: field %s in struct %s needs a path, query, header, cookie, body, form, file, auth or db tag", fset.Position(field.Pos()), fieldName, name))
+		}
+
+		var defaultValue *string
+		if value, ok := tag.Lookup("default"); ok {
+			defaultValue = &value
+		}
+
+		rules, validator, err := parseValidateTag(tag.Get("validate"), true)
+		if err != nil {
+			return errors.Join(ErrInvalidValidateTag, fmt.Errorf("field %s in struct %s: %w", fieldName, name, err))
+		}
+
+		// db fields are validated by the key they are loaded with
+		validated := field.Type()
+		if fromDbClause != nil {
+			validated = types.Typ[types.String]
+		}
+
+		if err := checkRules(validated, rules); err != nil {
+			return errors.Join(ErrInvalidValidateTag, fmt.Errorf("field %s in struct %s: %w", fieldName, name, err))
+		}
+
+		notEmpty := false
+		for _, rule := range rules {
+			notEmpty = notEmpty || rule.Name == "notempty"
 		}
 
 		if fieldKey == "" {
 			fieldKey = strings.ToLower(fieldName[:1]) + fieldName[1:]
 		}
 
-		req.Fields = append(req.Fields, Field{
-			Name:       fieldName,
-			DataType:   fieldType,
-			FieldType:  fieldTypeEnum,
-			FieldKey:   fieldKey,
-			LoadFromDB: fromDbClause,
-			Validator:  validator,
-			NotEmpty:   notEmpty,
-		})
+		fieldInfo := Field{
+			Name:         fieldName,
+			DataType:     resolver.typeString(field.Type()),
+			GoType:       field.Type(),
+			Type:         resolver.resolve(field.Type()),
+			FieldType:    fieldTypeEnum,
+			FieldKey:     fieldKey,
+			LoadFromDB:   fromDbClause,
+			Default:      defaultValue,
+			Validator:    validator,
+			Rules:        rules,
+			NotEmpty:     notEmpty,
+			AuthOptional: authOptional,
+		}
+
+		if err := resolver.err(); err != nil {
+			return errors.Join(ErrInvalidValidateTag, fmt.Errorf("field %s in struct %s: %w", fieldName, name, err))
+		}
+
+		if _, ok := tag.Lookup("maxsize"); (ok || tag.Get("accept") != "") && fieldTypeEnum != FieldFile {
+			return errors.Join(ErrInvalidFileTag, fmt.Errorf("field %s in struct %s: maxsize and accept can only be used for files", fieldName, name))
+		}
+
+		switch fieldTypeEnum {
+		case FieldPath, FieldQuery, FieldHeader, FieldCookie, FieldForm:
+			paramType, paramInfo := fieldInfo.ParamType()
+			defaults := []string{}
+			if defaultValue != nil {
+				defaults = append(defaults, *defaultValue)
+			}
+
+			separator, err := parseSeparator(tag, fieldTypeEnum)
+			if err != nil {
+				return errors.Join(ErrUnsupportedFieldType, fmt.Errorf("field %s in struct %s: %w", fieldName, name, err))
+			}
+
+			// slice parameters are parsed element by element
+			if elem := fieldInfo.SliceElem(); elem != nil {
+				if fieldTypeEnum == FieldPath || fieldTypeEnum == FieldCookie {
+					return errors.Join(ErrUnsupportedFieldType, fmt.Errorf("field %s in struct %s: %s parameters cannot be slices", fieldName, name, fieldTypeEnum))
+				}
+
+				paramType, paramInfo = elem, paramInfo.Elem
+				fieldInfo.Separator = separator
+				if defaultValue != nil && separator != "" {
+					defaults = strings.Split(*defaultValue, separator)
+				}
+			} else if _, ok := tag.Lookup("sep"); ok || tag.Get("explode") != "" {
+				return errors.Join(ErrUnsupportedFieldType, fmt.Errorf("field %s in struct %s: sep and explode can only be used for slices", fieldName, name))
+			}
+
+			if fromDbClause != nil {
+				break
+			}
+
+			kind := paramKindOf(paramType)
+			if kind == paramUnsupported {
+				return errors.Join(ErrUnsupportedFieldType, fmt.Errorf("field %s in struct %s: %s cannot be parsed from %s", fieldName, name, fieldInfo.DataType, fieldTypeEnum))
+			}
+
+			if format, ok := tag.Lookup("format"); ok {
+				if kind != paramTime {
+					return errors.Join(ErrUnsupportedFieldType, fmt.Errorf("field %s in struct %s: format can only be used for time.Time", fieldName, name))
+				}
+
+				if err := checkTimeFormat(format); err != nil {
+					return errors.Join(ErrUnsupportedFieldType, fmt.Errorf("field %s in struct %s: %w", fieldName, name, err))
+				}
+
+				fieldInfo.Format = format
+			}
+
+			for _, value := range defaults {
+				if err := checkDefault(paramType, fieldInfo.Format, value); err != nil {
+					return errors.Join(ErrInvalidDefault, fmt.Errorf("field %s in struct %s: %w", fieldName, name, err))
+				}
+			}
+
+			// the documents and clients describe the parameter as it is sent instead of the Go type
+			if info := paramTypeInfo(kind, fieldInfo.Format); info != nil {
+				switch {
+				case fieldInfo.Optional():
+					fieldInfo.Type = &TypeInfo{Kind: KindPointer, Elem: info}
+				case fieldInfo.SliceElem() != nil:
+					fieldInfo.Type = &TypeInfo{Kind: KindSlice, Name: fieldInfo.Type.Name, Elem: info}
+				default:
+					fieldInfo.Type = info
+				}
+			}
+		case FieldFile:
+			if !isFileHeader(field.Type()) && (fieldInfo.SliceElem() == nil || !isFileHeader(fieldInfo.SliceElem())) {
+				return errors.Join(ErrUnsupportedFieldType, fmt.Errorf("field %s in struct %s: files must be *multipart.FileHeader or []*multipart.FileHeader", fieldName, name))
+			}
+
+			if fromDbClause != nil {
+				return errors.Join(ErrUnsupportedFieldType, fmt.Errorf("field %s in struct %s: files cannot be loaded from the database", fieldName, name))
+			}
+
+			if value, ok := tag.Lookup("maxsize"); ok {
+				size, err := parseSize(value)
+				if err != nil {
+					return errors.Join(ErrInvalidFileTag, fmt.Errorf("field %s in struct %s: %w", fieldName, name, err))
+				}
+
+				fieldInfo.MaxSize = size
+			}
+
+			if value, ok := tag.Lookup("accept"); ok {
+				accept, err := parseAccept(value)
+				if err != nil {
+					return errors.Join(ErrInvalidFileTag, fmt.Errorf("field %s in struct %s: %w", fieldName, name, err))
+				}
+
+				fieldInfo.Accept = accept
+			}
+
+			// files are sent as binary parts of a multipart form
+			fieldInfo.Type = &TypeInfo{Kind: KindFile}
+			if fieldInfo.SliceElem() != nil {
+				fieldInfo.Type = &TypeInfo{Kind: KindSlice, Elem: fieldInfo.Type}
+			}
+
+			fallthrough
+		default:
+			if defaultValue != nil {
+				return errors.Join(ErrInvalidDefault, fmt.Errorf("field %s in struct %s: only path, query, header, cookie and form parameters can have a default value", fieldName, name))
+			}
+
+			if _, ok := tag.Lookup("format"); ok {
+				return errors.Join(ErrUnsupportedFieldType, fmt.Errorf("field %s in struct %s: only path, query, header, cookie and form parameters can have a format", fieldName, name))
+			}
+		}
+
+		req.Fields = append(req.Fields, fieldInfo)
 	}
 
-	sort.Slice(req.Fields, func(i, j int) bool {
+	if err := linkDBKeys(&req); err != nil {
+		return err
+	}
+
+	// files are parts of a multipart form, which cannot be sent along with a JSON body
+	hasBody, hasFile, hasForm := false, false, false
+	for _, field := range req.Fields {
+		hasBody = hasBody || field.FieldType == FieldBody
+		hasFile = hasFile || field.FieldType == FieldFile
+		hasForm = hasForm || field.FieldType == FieldForm
+	}
+
+	if hasBody && hasFile {
+		return errors.Join(ErrUnsupportedFieldType, fmt.Errorf("struct %s: files cannot be combined with a body", name))
+	}
+
+	// form parameters are sent as the body, clients like fetch refuse to send a body with GET or HEAD
+	if hasBody && hasForm {
+		return errors.Join(ErrUnsupportedFieldType, fmt.Errorf("struct %s: form parameters cannot be combined with a body", name))
+	}
+
+	// form parameters of GET and HEAD requests are sent as query, c.FormValue reads both
+	if (hasBody || hasFile) && req.HasNoBody() {
+		return errors.Join(ErrUnsupportedFieldType, fmt.Errorf("struct %s: %s requests cannot have a body or files", name, strings.ToUpper(string(req.Method))))
+	}
+
+	sort.SliceStable(req.Fields, func(i, j int) bool {
 		return req.Fields[i].FieldType.Priority() < req.Fields[j].FieldType.Priority()
 	})
 
-	if req.Method != "" {
-		reqFile.Requests = append(reqFile.Requests, req)
-	}
+	reqFile.Requests = append(reqFile.Requests, req)
+	return nil
 }
 
-func (g *Generator) extractFunction(fn *ast.FuncDecl, reqFile *RequestsFile) {
-	if fn.Recv != nil {
+func (g *Generator) extractFunction(fn *ast.FuncDecl, info *types.Info, reqFile *RequestsFile, resolver *typeResolver) {
+	if fn.Type.TypeParams != nil {
+		return
+	}
+
+	obj, ok := info.Defs[fn.Name].(*types.Func)
+	if !ok {
 		return
 	}
 
+	signature := obj.Type().(*types.Signature)
+
 	function := Function{
 		Name:    fn.Name.Name,
 		Params:  make(map[string]string),
 		Returns: []string{},
 	}
 
-	uk := 0
+	// methods may handle requests, the generated handlers then call them on the controller passed to RegisterRoutes
+	if recv := signature.Recv(); recv != nil {
+		recvType := recv.Type()
+		if ptr, ok := recvType.(*types.Pointer); ok {
+			recvType = ptr.Elem()
+			function.PointerReceiver = true
+		}
 
-	for _, param := range fn.Type.Params.List {
-		paramType := ""
-		if ident, ok := param.Type.(*ast.Ident); ok {
-			paramType = ident.Name
-		} else if star, ok := param.Type.(*ast.StarExpr); ok {
-			if ident, ok := star.X.(*ast.Ident); ok {
-				paramType = "*" + ident.Name
-			}
-		} else if sel, ok := param.Type.(*ast.SelectorExpr); ok {
-			if x, ok := sel.X.(*ast.Ident); ok {
-				paramType = x.Name + "." + sel.Sel.Name
-			}
+		named, ok := types.Unalias(recvType).(*types.Named)
+		if !ok || named.TypeParams().Len() > 0 {
+			return
 		}
 
-		name := ""
-		if len(param.Names) == 0 {
+		function.Receiver = named.Obj().Name()
+	}
+
+	uk := 0
+
+	for i := 0; i < signature.Params().Len(); i++ {
+		param := signature.Params().At(i)
+
+		name := param.Name()
+		if name == "" || name == "_" {
 			name = fmt.Sprintf("uk_%d", uk)
 			uk++
-		} else {
-			name = param.Names[0].Name
 		}
 
-		function.Params[name] = paramType
+		function.Params[name] = resolver.typeString(param.Type())
 	}
 
-	if fn.Type.Results != nil {
-		for _, result := range fn.Type.Results.List {
-			if ident, ok := result.Type.(*ast.Ident); ok {
-				function.Returns = append(function.Returns, ident.Name)
-			} else if star, ok := result.Type.(*ast.StarExpr); ok {
-				if ident, ok := star.X.(*ast.Ident); ok {
-					function.Returns = append(function.Returns, "*"+ident.Name)
-				}
-			} else if sel, ok := result.Type.(*ast.SelectorExpr); ok {
-				if x, ok := sel.X.(*ast.Ident); ok {
-					function.Returns = append(function.Returns, x.Name+"."+sel.Sel.Name)
+	for i := 0; i < signature.Results().Len(); i++ {
+		result := signature.Results().At(i).Type()
+
+		// the kind is empty for types handlers cannot return, which is reported once the function is linked to a request
+		kind, _ := returnKindOf(result)
+		resolved := result
+		switch kind {
+		case ReturnResponse:
+			resolved = exoTypeArg(result, "Response")
+		case ReturnSerialize:
+			resolved = exoTypeArg(result, "Serialize")
+		}
+
+		function.Returns = append(function.Returns, resolver.typeString(result))
+		function.ReturnKinds = append(function.ReturnKinds, kind)
+		function.ReturnTypes = append(function.ReturnTypes, resolver.resolve(resolved))
+		function.ReturnGoTypes = append(function.ReturnGoTypes, result)
+
+		if err := resolver.err(); err != nil {
+			function.ReturnErr = errors.Join(function.ReturnErr, fmt.Errorf("handler: %s returns %s: %w", function.Name, function.Returns[i], err))
+		}
+	}
+
+	reqFile.Functions = append(reqFile.Functions, function)
+}
+
+// linkDBKeys links the keys of fields looked up by other parameters to the fields bound to these parameters. The parsed values of the
+// fields are compared with the columns, so the parameters are validated and documented like any other.
+func linkDBKeys(req *Request) error {
+	for _, field := range req.Fields {
+		if field.FieldType != FieldDB {
+			continue
+		}
+
+		for k, key := range field.LoadFromDB.Keys {
+			for _, other := range req.Fields {
+				switch other.FieldType {
+				case FieldPath, FieldQuery, FieldHeader, FieldCookie, FieldForm:
+					if other.FieldKey == key.Param && other.LoadFromDB == nil && other.SliceElem() == nil && field.LoadFromDB.Keys[k].Field == "" {
+						field.LoadFromDB.Keys[k].Field = other.Name
+					}
 				}
 			}
+
+			if field.LoadFromDB.Keys[k].Field == "" {
+				return errors.Join(ErrInvalidDBTag, fmt.Errorf("field %s in struct %s: the parameter %s must be bound to a path, query, header, cookie or form field which is no slice", field.Name, req.StructName, key.Param))
+			}
 		}
 	}
 
-	reqFile.Functions = append(reqFile.Functions, function)
+	return nil
+}
+
+// checkController reports an error if the handlers of a package are methods of different controllers, as RegisterRoutes takes a
+// single controller, or if functions and methods with the same name handle requests.
+func checkController(files []RequestsFile) error {
+	controller := ""
+	handlers := map[string]string{} // name -> receiver
+	for _, reqFile := range files {
+		for _, req := range reqFile.Requests {
+			fn := req.Handler
+			if receiver, ok := handlers[fn.Name]; ok && receiver != fn.Receiver {
+				return errors.Join(ErrHandlerIllegalSignature, fmt.Errorf("handler: %s is declared as function and as method", fn.Name))
+			}
+			handlers[fn.Name] = fn.Receiver
+
+			if fn.Receiver == "" {
+				continue
+			}
+
+			if controller != "" && controller != fn.Receiver {
+				return errors.Join(ErrHandlerIllegalSignature, fmt.Errorf("handler: %s is a method of %s, but other handlers are methods of %s", fn.Name, fn.Receiver, controller))
+			}
+			controller = fn.Receiver
+		}
+	}
+
+	return nil
+}
+
+// checkMiddleware reports an error if a middleware of the use tag of a request is not a package-level fiber.Handler. Like handlers,
+// the middleware may be declared in any file of the package, either as function or as variable, e.g. var rateLimit = limiter.New().
+func checkMiddleware(reqFile RequestsFile, scope *types.Scope) error {
+	for _, req := range reqFile.Requests {
+		for _, name := range req.Middleware {
+			obj := scope.Lookup(name)
+			if obj == nil {
+				return errors.Join(ErrFunctionNotFound, fmt.Errorf("middleware: %s for struct %s", name, req.StructName))
+			}
+
+			if _, ok := obj.(*types.TypeName); ok || !isFiberHandler(obj.Type()) {
+				return errors.Join(ErrHandlerIllegalSignature, fmt.Errorf("middleware: %s for struct %s must be a fiber.Handler, got %s", name, req.StructName, types.TypeString(obj.Type(), (*types.Package).Name)))
+			}
+		}
+	}
+
+	return nil
+}
+
+// isFiberHandler reports whether t is fiber.Handler or a function with the same signature.
+func isFiberHandler(t types.Type) bool {
+	signature, ok := t.Underlying().(*types.Signature)
+	if !ok || signature.TypeParams() != nil || signature.Params().Len() != 1 || signature.Results().Len() != 1 || signature.Variadic() {
+		return false
+	}
+
+	ptr, ok := types.Unalias(signature.Params().At(0).Type()).(*types.Pointer)
+	if !ok {
+		return false
+	}
+
+	named, ok := types.Unalias(ptr.Elem()).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil || named.Obj().Pkg().Path() != "github.com/gofiber/fiber/v2" || named.Obj().Name() != "Ctx" {
+		return false
+	}
+
+	return types.Identical(signature.Results().At(0).Type(), types.Universe.Lookup("error").Type())
+}
+
+// extractGroup returns the group declared by spec, or nil if spec declares no exo.Group. The prefix is read from the composite literal
+// of the variable, as it must be known to generate the clients and documents.
+func extractGroup(spec *ast.ValueSpec, info *types.Info) (*Group, error) {
+	for i, name := range spec.Names {
+		obj, ok := info.Defs[name].(*types.Var)
+		if !ok || !isExoType(obj.Type(), "Group") {
+			continue
+		}
+
+		group := &Group{Var: name.Name}
+		if i >= len(spec.Values) {
+			return group, nil
+		}
+
+		lit, ok := spec.Values[i].(*ast.CompositeLit)
+		if !ok {
+			return nil, errors.Join(ErrInvalidGroup, fmt.Errorf("group %s must be declared as exo.Group{...}", name.Name))
+		}
+
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				return nil, errors.Join(ErrInvalidGroup, fmt.Errorf("group %s must be declared with field names", name.Name))
+			}
+
+			if key, ok := kv.Key.(*ast.Ident); !ok || key.Name != "Prefix" {
+				continue
+			}
+
+			value := info.Types[kv.Value].Value
+			if value == nil || value.Kind() != constant.String {
+				return nil, errors.Join(ErrInvalidGroup, fmt.Errorf("the prefix of group %s must be a constant string", name.Name))
+			}
+
+			group.Prefix = constant.StringVal(value)
+			if group.Prefix != "" && (!strings.HasPrefix(group.Prefix, "/") || strings.HasSuffix(group.Prefix, "/")) {
+				return nil, errors.Join(ErrInvalidGroup, fmt.Errorf("the prefix of group %s must start and must not end with /, got %q", name.Name, group.Prefix))
+			}
+
+			if strings.ContainsAny(group.Prefix, ":*+") {
+				return nil, errors.Join(ErrInvalidGroup, fmt.Errorf("the prefix of group %s must not contain parameters, got %q", name.Name, group.Prefix))
+			}
+		}
+
+		return group, nil
+	}
+
+	return nil, nil
 }
 
-func (g *Generator) isAllowedReturnType(ret string) bool {
-	switch ret {
-	case "error", "int", "int8", "int16", "int32", "int64", "string", "[]byte", "interface{}", "any", "exo.Serialize":
-		return true
+// isExoGenerated reports whether the file at p was generated by exo.
+func isExoGenerated(p string) bool {
+	f, err := os.Open(p)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	header := make([]byte, len(generatedHeader))
+	_, err = io.ReadFull(f, header)
+	return err == nil && string(header) == generatedHeader
+}
+
+// exoMethod reports the method of an embedded exo.Get, exo.Post, ... regardless of the name the exo package is imported with.
+func exoMethod(t types.Type) (Method, bool) {
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil || named.Obj().Pkg().Path() != exoPkgPath {
+		return "", false
+	}
+
+	switch method := Method(named.Obj().Name()); method {
+	case MethodGet, MethodPost, MethodPut, MethodDelete, MethodPatch, MethodOptions, MethodHead, MethodTrace:
+		return method, true
+	}
+
+	return "", false
+}
+
+// parseSeparator returns the separator of a slice parameter given by its sep and explode tags. Without them, the values are sent
+// as repeated keys like ?tag=a&tag=b. Headers are always lists separated by commas or sep, as browsers join repeated headers.
+func parseSeparator(tag reflect.StructTag, source FieldType) (string, error) {
+	explode, hasExplode := tag.Lookup("explode")
+	if hasExplode && explode != "true" && explode != "false" {
+		return "", fmt.Errorf("explode must be true or false, got %q", explode)
+	}
+
+	if source == FieldHeader && explode == "true" {
+		return "", errors.New("headers cannot be exploded")
+	}
+
+	if sep, ok := tag.Lookup("sep"); ok {
+		if sep == "" {
+			return "", errors.New("sep must not be empty")
+		}
+
+		if explode == "true" {
+			return "", errors.New("sep cannot be used with explode:\"true\"")
+		}
+
+		return sep, nil
+	}
+
+	if explode == "false" || source == FieldHeader {
+		return ",", nil
+	}
+
+	return "", nil
+}
+
+// checkDefault reports an error if the value of a default tag cannot be parsed as a parameter of type t.
+func checkDefault(t types.Type, format, value string) error {
+	var err error
+	switch paramKindOf(t) {
+	case paramUUID:
+		err = uuid.Validate(value)
+	case paramTime:
+		if layout, ok := timeLayout(format); ok {
+			_, err = time.Parse(layout, value)
+		} else {
+			_, err = strconv.ParseInt(value, 10, 64)
+		}
+	case paramDuration:
+		_, err = time.ParseDuration(value)
+	case paramBasic:
+		switch dataType := basicName(t); {
+		case dataType == "bool":
+			_, err = strconv.ParseBool(value)
+		case strings.HasPrefix(dataType, "int"):
+			_, err = strconv.ParseInt(value, 10, numberBits(dataType, "int"))
+		case strings.HasPrefix(dataType, "uint"):
+			_, err = strconv.ParseUint(value, 10, numberBits(dataType, "uint"))
+		case strings.HasPrefix(dataType, "float"):
+			_, err = strconv.ParseFloat(value, numberBits(dataType, "float"))
+		}
 	}
-	return false
+
+	// values of text unmarshalers can only be checked at runtime
+	return err
+}
+
+func splitTagList(value string) []string {
+	list := []string{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
+
+// returnKindOf returns how a value of type t returned by a handler is sent. False is returned if handlers cannot return t.
+func returnKindOf(t types.Type) (ReturnKind, bool) {
+	if types.Identical(t, types.Universe.Lookup("error").Type()) {
+		return ReturnError, true
+	}
+
+	switch {
+	case isExoType(t, "Cookies"):
+		return ReturnCookies, true
+	case exoTypeArg(t, "Response") != nil:
+		return ReturnResponse, true
+	case exoTypeArg(t, "Serialize") != nil:
+		return ReturnSerialize, true
+	}
+
+	if basic, ok := types.Unalias(t).(*types.Basic); ok {
+		switch {
+		case basic.Kind() == types.String:
+			return ReturnString, true
+		case basic.Info()&types.IsInteger != 0 && basic.Info()&types.IsUnsigned == 0:
+			return ReturnStatus, true
+		}
+
+		return "", false
+	}
+
+	if slice, ok := types.Unalias(t).(*types.Slice); ok && types.Identical(slice.Elem(), types.Typ[types.Byte]) {
+		return ReturnBytes, true
+	}
+
+	// structs, slices and maps are sent as JSON, also if they are named or pointed to
+	if ptr, ok := types.Unalias(t).(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Struct, *types.Slice, *types.Map:
+		return ReturnJSON, true
+	case *types.Interface:
+		return ReturnJSON, u.Empty()
+	}
+
+	return "", false
+}
+
+// exoTypeArg returns the type argument of a generic type of the exo package like exo.Response[T], or nil if t is no such type.
+func exoTypeArg(t types.Type, name string) types.Type {
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok || !isExoType(t, name) || named.TypeArgs().Len() != 1 {
+		return nil
+	}
+
+	return named.TypeArgs().At(0)
+}
+
+// bodyKind returns how the body of an exo.Response is sent.
+func bodyKind(t types.Type) ReturnKind {
+	switch kind, _ := returnKindOf(t); kind {
+	case ReturnString, ReturnBytes:
+		return kind
+	}
+
+	return ReturnJSON
+}
+
+// isExoType reports whether t is the type of the exo package with the given name.
+func isExoType(t types.Type, name string) bool {
+	named, ok := types.Unalias(t).(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == exoPkgPath && named.Obj().Name() == name
+}
+
+// basicName returns the name of the basic type underlying t, e.g. "int64" for int64 and for a named type declared as int64.
+// An empty string is returned if t is not based on a basic type.
+func basicName(t types.Type) string {
+	if basic, ok := t.Underlying().(*types.Basic); ok && basic.Info()&(types.IsString|types.IsBoolean|types.IsNumeric) != 0 && basic.Info()&types.IsComplex == 0 {
+		switch basic.Kind() {
+		case types.Uintptr:
+			return ""
+		case types.Byte:
+			return "uint8"
+		case types.Rune:
+			return "int32"
+		}
+
+		return basic.Name()
+	}
+
+	return ""
+}
+
+// hasMarshalMethod reports whether t or a pointer to t has a method like MarshalJSON or MarshalText with the
+// signature func() ([]byte, error).
+func hasMarshalMethod(t types.Type, name string) bool {
+	obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(t), false, nil, name)
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+
+	sig := fn.Type().(*types.Signature)
+	return sig.Params().Len() == 0 && sig.Results().Len() == 2 &&
+		types.Identical(sig.Results().At(0).Type(), types.NewSlice(types.Typ[types.Byte])) &&
+		types.Identical(sig.Results().At(1).Type(), types.Universe.Lookup("error").Type())
+}
+
+// typeResolver resolves types of a package into TypeInfo descriptions.
+type typeResolver struct {
+	pkg       *types.Package
+	resolving map[string]bool
+	errs      []error // invalid validate tags of resolved struct fields
+}
+
+func newTypeResolver(pkg *types.Package) *typeResolver {
+	return &typeResolver{
+		pkg:       pkg,
+		resolving: make(map[string]bool),
+	}
+}
+
+// err returns the errors found since the last call of err.
+func (r *typeResolver) err() error {
+	err := errors.Join(r.errs...)
+	r.errs = nil
+	return err
+}
+
+// typeString returns the type as it is written in the analyzed package, with other packages qualified by their package name.
+func (r *typeResolver) typeString(t types.Type) string {
+	if iface, ok := types.Unalias(t).(*types.Interface); ok && iface.Empty() {
+		return "any"
+	}
+
+	return types.TypeString(t, func(p *types.Package) string {
+		if p == r.pkg {
+			return ""
+		}
+
+		return p.Name()
+	})
+}
+
+func (r *typeResolver) typeName(named *types.Named) string {
+	name := named.Obj().Name()
+
+	args := named.TypeArgs()
+	for i := 0; i < args.Len(); i++ {
+		name += "_" + typeNameRegex.ReplaceAllString(types.TypeString(args.At(i), func(*types.Package) string { return "" }), "")
+	}
+
+	return name
+}
+
+func (r *typeResolver) resolve(t types.Type) *TypeInfo {
+	switch t := types.Unalias(t).(type) {
+	case *types.Basic:
+		info := t.Info()
+		switch {
+		case info&types.IsString != 0:
+			return &TypeInfo{Kind: KindString, Name: t.Name()}
+		case info&types.IsBoolean != 0:
+			return &TypeInfo{Kind: KindBool, Name: t.Name()}
+		case info&types.IsUnsigned != 0:
+			return &TypeInfo{Kind: KindUint, Name: t.Name()}
+		case info&types.IsInteger != 0:
+			return &TypeInfo{Kind: KindInt, Name: t.Name()}
+		case info&types.IsFloat != 0:
+			return &TypeInfo{Kind: KindFloat, Name: t.Name()}
+		}
+	case *types.Named:
+		obj := t.Obj()
+		if obj.Pkg() != nil {
+			switch obj.Pkg().Path() + "." + obj.Name() {
+			case "github.com/google/uuid.UUID":
+				return &TypeInfo{Kind: KindUUID, Name: "uuid.UUID"}
+			case "time.Time":
+				return &TypeInfo{Kind: KindTime, Name: "time.Time"}
+			case "gorm.io/gorm.DeletedAt":
+				// marshalled as null unless the record is deleted
+				return &TypeInfo{Kind: KindPointer, Elem: &TypeInfo{Kind: KindTime, Name: "time.Time"}}
+			}
+		}
+
+		name := r.typeName(t)
+		key := types.TypeString(t, nil)
+
+		pkg := ""
+		if obj.Pkg() != nil {
+			pkg = obj.Pkg().Path()
+		}
+
+		// the fields of types with their own encoding do not describe their JSON, encoding/json prefers MarshalJSON
+		// over MarshalText, which encodes as string
+		switch {
+		case hasMarshalMethod(t, "MarshalJSON"):
+			return &TypeInfo{Kind: KindAny}
+		case hasMarshalMethod(t, "MarshalText"):
+			return &TypeInfo{Kind: KindString, Name: name, Pkg: pkg}
+		}
+
+		if r.resolving[key] {
+			return &TypeInfo{Kind: KindStruct, Name: name, Pkg: pkg}
+		}
+
+		r.resolving[key] = true
+		defer delete(r.resolving, key)
+
+		// the errors of the fields are reported by their path starting at the outermost type
+		start := len(r.errs)
+		info := *r.resolve(t.Underlying())
+		for i := start; i < len(r.errs) && len(r.resolving) == 1; i++ {
+			r.errs[i] = fmt.Errorf("%s.%w", r.typeString(t), r.errs[i])
+		}
+
+		info.Name = name
+		info.Pkg = pkg
+		return &info
+	case *types.Pointer:
+		return &TypeInfo{Kind: KindPointer, Elem: r.resolve(t.Elem())}
+	case *types.Slice:
+		if types.Identical(t.Elem(), types.Typ[types.Byte]) {
+			return &TypeInfo{Kind: KindBytes}
+		}
+
+		return &TypeInfo{Kind: KindSlice, Elem: r.resolve(t.Elem())}
+	case *types.Array:
+		return &TypeInfo{Kind: KindSlice, Elem: r.resolve(t.Elem())}
+	case *types.Map:
+		return &TypeInfo{Kind: KindMap, Elem: r.resolve(t.Elem())}
+	case *types.Struct:
+		info := &TypeInfo{Kind: KindStruct, Fields: []TypeField{}}
+
+		for i := 0; i < t.NumFields(); i++ {
+			field := t.Field(i)
+			tag := reflect.StructTag(t.Tag(i))
+
+			jsonName, jsonOpts, _ := strings.Cut(tag.Get("json"), ",")
+			if jsonName == "-" && jsonOpts == "" {
+				continue
+			}
+
+			start := len(r.errs)
+			fieldType := r.resolve(field.Type())
+			for i := start; i < len(r.errs); i++ {
+				r.errs[i] = fmt.Errorf("%s.%w", field.Name(), r.errs[i])
+			}
+
+			if field.Embedded() {
+				// embedded structs are flattened like encoding/json does
+				if fieldType.Kind == KindStruct && jsonName == "" {
+					info.Fields = append(info.Fields, fieldType.Fields...)
+					continue
+				}
+			}
+
+			if !field.Exported() {
+				continue
+			}
+
+			fieldName := jsonName
+			if fieldName == "" {
+				fieldName = field.Name()
+			}
+
+			rules, _, err := parseValidateTag(tag.Get("validate"), false)
+			if err == nil {
+				err = checkRules(field.Type(), rules)
+			}
+
+			if err != nil {
+				r.errs = append(r.errs, fmt.Errorf("%s: %w", field.Name(), err))
+			}
+
+			info.Fields = append(info.Fields, TypeField{
+				Name:     field.Name(),
+				JSONName: fieldName,
+				Optional: strings.Contains(jsonOpts, "omitempty") || fieldType.Kind == KindPointer,
+				Type:     fieldType,
+				Rules:    rules,
+			})
+		}
+
+		return info
+	}
+
+	return &TypeInfo{Kind: KindAny}
 }
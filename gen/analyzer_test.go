@@ -0,0 +1,797 @@
+package gen
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// analyzeSources analyzes packages consisting of the given requests files, one generator per package. The packages are written below
+// testdata, so they belong to the module and may import exo, but are skipped when the module itself is generated. They are loaded at
+// once, as loading the dependencies takes most of the time.
+func analyzeSources(t *testing.T, srcs ...string) ([]*Generator, []string, []error) {
+	t.Helper()
+
+	if err := os.MkdirAll("testdata", 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := filepath.Abs("..")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dirs := make([]string, len(srcs))
+	patterns := make([]string, len(srcs))
+	for i, src := range srcs {
+		dir, err := os.MkdirTemp("testdata", "pkg")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+		if err := os.WriteFile(filepath.Join(dir, "requests.go"), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		dirs[i] = dir
+		if patterns[i], err = filepath.Abs(dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pkgs, err := packages.Load(&packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedImports | packages.NeedDeps | packages.NeedTypes | packages.NeedTypesInfo,
+		Dir:  root,
+	}, patterns...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gens := make([]*Generator, len(srcs))
+	errs := make([]error, len(srcs))
+	for _, pkg := range pkgs {
+		i := -1
+		for j, pattern := range patterns {
+			if len(pkg.GoFiles) > 0 && filepath.Dir(pkg.GoFiles[0]) == pattern {
+				i = j
+			}
+		}
+
+		if i < 0 {
+			t.Fatalf("package %s was not requested", pkg.PkgPath)
+		}
+
+		gens[i] = &Generator{
+			packages: make(map[string][]RequestsFile),
+			rc:       map[string]string{},
+			module:   "github.com/exo-framework/exo",
+			root:     root,
+		}
+
+		for _, err := range pkg.Errors {
+			errs[i] = errors.Join(errs[i], err)
+		}
+
+		if errs[i] == nil {
+			errs[i] = gens[i].analyzePackage(dirs[i], pkg)
+		}
+	}
+
+	return gens, dirs, errs
+}
+
+// analyzeErrors analyzes a package for every source and compares the errors with the expected ones. A nil error is expected to pass,
+// others must match using errors.Is and contain the message.
+func analyzeErrors(t *testing.T, tests []analyzeTest) {
+	t.Helper()
+
+	srcs := make([]string, len(tests))
+	for i, tt := range tests {
+		srcs[i] = tt.src
+	}
+
+	_, _, errs := analyzeSources(t, srcs...)
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := errs[i]
+			if tt.err == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, tt.err) || !strings.Contains(err.Error(), tt.msg) {
+				t.Fatalf("expected %v containing %q, got %v", tt.err, tt.msg, err)
+			}
+		})
+	}
+}
+
+type analyzeTest struct {
+	name string
+	src  string
+	err  error
+	msg  string
+}
+
+func TestAnalyzeReturnTypeRules(t *testing.T) {
+	src := func(out string) string {
+		return `package p
+
+import "github.com/exo-framework/exo"
+
+type Out struct {
+	` + out + `
+}
+
+type Get struct {
+	exo.Get ` + "`route:\"/\"`" + `
+	Name string ` + "`query:\"name\"`" + `
+}
+
+func get(Get) ([]Out, error) {
+	return nil, nil
+}
+`
+	}
+
+	analyzeErrors(t, []analyzeTest{
+		{name: "valid rules", src: src("N int `json:\"n\" validate:\"min=1\"`")},
+		{name: "invalid rule of returned type", src: src("N int8 `json:\"n\" validate:\"max=300\"`"), err: ErrInvalidValidateTag, msg: "handler: get returns []Out: Out.N: rule max"},
+		{name: "invalid rule of nested type", src: src("In struct{ N float64 `validate:\"min=NaN\"` }"), err: ErrInvalidValidateTag, msg: "handler: get returns []Out: Out.In.N: rule min"},
+	})
+}
+
+func TestAnalyzeRequestBodies(t *testing.T) {
+	src := func(method, fields string) string {
+		return `package p
+
+import (
+	"mime/multipart"
+
+	"github.com/exo-framework/exo"
+)
+
+var _ *multipart.FileHeader
+
+type Req struct {
+	exo.` + method + ` ` + "`route:\"/\"`" + `
+	` + fields + `
+}
+
+func handle(Req) error {
+	return nil
+}
+`
+	}
+
+	body := "Body struct{ N int } `body:\"\"`"
+	form := "Name string `form:\"name\"`"
+	file := "File *multipart.FileHeader `file:\"file\"`"
+
+	analyzeErrors(t, []analyzeTest{
+		{name: "body", src: src("Post", body)},
+		{name: "form and files", src: src("Post", form+"\n"+file)},
+		{name: "form of a GET request", src: src("Get", form)},
+		{name: "body and form", src: src("Put", body+"\n"+form), err: ErrUnsupportedFieldType, msg: "struct Req: form parameters cannot be combined with a body"},
+		{name: "body and files", src: src("Post", body+"\n"+file), err: ErrUnsupportedFieldType, msg: "struct Req: files cannot be combined with a body"},
+		{name: "body of a GET request", src: src("Get", body), err: ErrUnsupportedFieldType, msg: "struct Req: GET requests cannot have a body or files"},
+		{name: "files of a HEAD request", src: src("Head", file), err: ErrUnsupportedFieldType, msg: "struct Req: HEAD requests cannot have a body or files"},
+	})
+}
+
+// describe returns a compact notation of the type info, e.g. slice(int int64).
+func describe(t *TypeInfo) string {
+	if t == nil {
+		return "nil"
+	}
+
+	s := string(t.Kind)
+	if t.Name != "" {
+		s += " " + t.Name
+	}
+
+	if t.Format != "" {
+		s += " format=" + t.Format
+	}
+
+	if t.Elem != nil {
+		s += "(" + describe(t.Elem) + ")"
+	}
+
+	if t.Kind == KindStruct && t.Name == "" {
+		fields := []string{}
+		for _, field := range t.Fields {
+			fields = append(fields, field.JSONName+": "+describe(field.Type))
+		}
+		s += "{" + strings.Join(fields, ", ") + "}"
+	}
+
+	return s
+}
+
+func TestAnalyzeTypes(t *testing.T) {
+	gens, _, errs := analyzeSources(t, `package p
+
+import (
+	"time"
+
+	web "github.com/exo-framework/exo"
+	"github.com/google/uuid"
+)
+
+type Status string
+
+type Dto struct {
+	Name string `+"`json:\"name\"`"+`
+	Next *Dto   `+"`json:\"next\"`"+`
+	Meta struct {
+		Tags map[string]any `+"`json:\"tags,omitempty\"`"+`
+	} `+"`json:\"meta\"`"+`
+}
+
+type Page[T any] struct {
+	Items []T `+"`json:\"items\"`"+`
+}
+
+type Req struct {
+	web.Post `+"`route:\"/items/:id\"`"+`
+	Id       uuid.UUID             `+"`path:\"id\"`"+`
+	Status   Status                `+"`query:\"status\"`"+`
+	Since    time.Time             `+"`query:\"since\" format:\"date\"`"+`
+	Wait     time.Duration         `+"`query:\"wait\"`"+`
+	Ids      []int64               `+"`query:\"ids\"`"+`
+	Count    *uint8                `+"`query:\"count\"`"+`
+	Body     map[string][]Dto      `+"`body:\"\"`"+`
+}
+
+func handle(Req) (Page[Dto], int, error) {
+	return Page[Dto]{}, 0, nil
+}
+`)
+	if errs[0] != nil {
+		t.Fatal(errs[0])
+	}
+
+	var req Request
+	for _, files := range gens[0].packages {
+		req = files[0].Requests[0]
+	}
+
+	fields := map[string]Field{}
+	for _, field := range req.Fields {
+		fields[field.Name] = field
+	}
+
+	page := req.Handler.ReturnTypes[0]
+	dto := page.Fields[0].Type.Elem
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"method of an aliased import", string(req.Method) + " " + req.Route, "Post /items/:id"},
+		{"type of another package", describe(fields["Id"].Type), "uuid uuid.UUID"},
+		{"named string", fields["Status"].DataType + " " + describe(fields["Status"].Type), "Status string Status"},
+		{"time", describe(fields["Since"].Type), "time time.Time format=date"},
+		{"duration", describe(fields["Wait"].Type), "string format=duration"},
+		{"slice", describe(fields["Ids"].Type), "slice(int int64)"},
+		{"pointer", describe(fields["Count"].Type), "pointer(uint uint8)"},
+		{"map", describe(fields["Body"].Type), "map(slice(struct Dto))"},
+		{"return kinds", fmt.Sprint(req.Handler.ReturnKinds), "[json status error]"},
+		{"generic type", describe(page), "struct Page_Dto"},
+		{"field of a generic type", describe(page.Fields[0].Type), "slice(struct Dto)"},
+		{"recursive type", describe(dto.Fields[1].Type), "pointer(struct Dto)"},
+		{"anonymous struct", describe(dto.Fields[2].Type), "struct{tags: map(any)}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, tt.got)
+			}
+		})
+	}
+}
+
+func TestAnalyzeRequests(t *testing.T) {
+	src := func(fields, handler string) string {
+		return `package p
+
+import "github.com/exo-framework/exo"
+
+type Req struct {
+	exo.Get ` + "`route:\"/\"`" + `
+	` + fields + `
+}
+
+` + handler + `
+`
+	}
+
+	handler := "func handle(Req) error {\n\treturn nil\n}"
+
+	analyzeErrors(t, []analyzeTest{
+		{name: "valid request", src: src("Name string `query:\"name\"`", handler)},
+		{name: "dot import", src: `package p
+
+import . "github.com/exo-framework/exo"
+
+type Req struct {
+	Get ` + "`route:\"/\"`" + `
+}
+`, err: ErrFunctionNotFound, msg: "handler: for struct Req"},
+		{name: "missing source tag", src: src("Name string", handler), err: ErrMissingSourceTag, msg: "field Name in struct Req needs a path, query, header, cookie, body, form, file, auth or db tag"},
+		{name: "unexported field with a tag", src: src("name string `query:\"name\"`", handler)},
+		{name: "unexported field without a tag", src: src("name string", handler)},
+		{name: "missing handler", src: src("", ""), err: ErrFunctionNotFound, msg: "handler: for struct Req"},
+		{name: "missing validator", src: src("Name string `query:\"name\" validate:\"check\"`", handler), err: ErrFunctionNotFound, msg: "validator: for field Name in struct Req"},
+		{name: "several parameters", src: src("", "func handle(Req, int) error {\n\treturn nil\n}"), err: ErrHandlerIllegalSignature},
+		{name: "unsupported return type", src: src("", "func handle(Req) (chan int, error) {\n\treturn nil, nil\n}"), err: ErrHandlerIllegalSignature, msg: "handler: handle returns chan int"},
+		{name: "response with other values", src: src("", "func handle(Req) (exo.Response[string], int, error) {\n\treturn exo.Response[string]{}, 0, nil\n}"), err: ErrHandlerIllegalSignature, msg: "which can only be combined with an error"},
+		{name: "unsupported parameter type", src: src("Ch chan int `query:\"ch\"`", handler), err: ErrUnsupportedFieldType, msg: "field Ch in struct Req: chan int cannot be parsed from query"},
+	})
+}
+
+func TestAnalyzeDefaults(t *testing.T) {
+	src := func(field string) string {
+		return `package p
+
+import (
+	"mime/multipart"
+	"time"
+
+	"github.com/exo-framework/exo"
+	"github.com/google/uuid"
+)
+
+var (
+	_ *multipart.FileHeader
+	_ time.Time
+	_ uuid.UUID
+)
+
+type Req struct {
+	exo.Post ` + "`route:\"/\"`" + `
+	` + field + `
+}
+
+func handle(Req) error {
+	return nil
+}
+`
+	}
+
+	analyzeErrors(t, []analyzeTest{
+		{name: "int", src: src("V int `query:\"v\" default:\"-20\"`")},
+		{name: "pointer", src: src("V *uint `query:\"v\" default:\"1\"`")},
+		{name: "uuid", src: src("V uuid.UUID `header:\"V\" default:\"6ba7b810-9dad-11d1-80b4-00c04fd430c8\"`")},
+		{name: "duration", src: src("V time.Duration `query:\"v\" default:\"1m30s\"`")},
+		{name: "date", src: src("V time.Time `query:\"v\" format:\"date\" default:\"2024-01-01\"`")},
+		{name: "unix time", src: src("V time.Time `query:\"v\" format:\"unix\" default:\"0\"`")},
+		{name: "form", src: src("V bool `form:\"v\" default:\"true\"`")},
+		{name: "empty string", src: src("V string `query:\"v\" default:\"\"`")},
+		{name: "no integer", src: src("V int `query:\"v\" default:\"x\"`"), err: ErrInvalidDefault, msg: "field V in struct Req"},
+		{name: "int8 overflow", src: src("V int8 `query:\"v\" default:\"128\"`"), err: ErrInvalidDefault},
+		{name: "negative uint", src: src("V uint `query:\"v\" default:\"-1\"`"), err: ErrInvalidDefault},
+		{name: "no bool", src: src("V bool `cookie:\"v\" default:\"yes\"`"), err: ErrInvalidDefault},
+		{name: "no uuid", src: src("V uuid.UUID `query:\"v\" default:\"1\"`"), err: ErrInvalidDefault},
+		{name: "no duration", src: src("V time.Duration `query:\"v\" default:\"1\"`"), err: ErrInvalidDefault},
+		{name: "timestamp for a date", src: src("V time.Time `query:\"v\" format:\"date\" default:\"2024-01-01T00:00:00Z\"`"), err: ErrInvalidDefault},
+		{name: "body", src: src("V struct{} `body:\"\" default:\"{}\"`"), err: ErrInvalidDefault, msg: "only path, query, header, cookie and form parameters can have a default value"},
+		{name: "file", src: src("V *multipart.FileHeader `file:\"v\" default:\"a\"`"), err: ErrInvalidDefault, msg: "only path, query, header, cookie and form parameters can have a default value"},
+	})
+}
+
+func TestParseSeparator(t *testing.T) {
+	tests := []struct {
+		name   string
+		tag    reflect.StructTag
+		source FieldType
+		sep    string
+		err    string
+	}{
+		{"repeated keys", ``, FieldQuery, "", ""},
+		{"exploded", `explode:"true"`, FieldQuery, "", ""},
+		{"not exploded", `explode:"false"`, FieldQuery, ",", ""},
+		{"separator", `sep:"|"`, FieldForm, "|", ""},
+		{"separator of a non exploded list", `sep:";" explode:"false"`, FieldQuery, ";", ""},
+		{"header", ``, FieldHeader, ",", ""},
+		{"header separator", `sep:";"`, FieldHeader, ";", ""},
+		{"exploded header", `explode:"true"`, FieldHeader, "", "headers cannot be exploded"},
+		{"invalid explode", `explode:"yes"`, FieldQuery, "", `explode must be true or false, got "yes"`},
+		{"empty separator", `sep:""`, FieldQuery, "", "sep must not be empty"},
+		{"separator of an exploded list", `sep:"," explode:"true"`, FieldQuery, "", `sep cannot be used with explode:"true"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sep, err := parseSeparator(tt.tag, tt.source)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+
+			if err != nil || sep != tt.sep {
+				t.Errorf("expected %q, got %q, %v", tt.sep, sep, err)
+			}
+		})
+	}
+}
+
+func TestAnalyzeSlices(t *testing.T) {
+	src := func(field string) string {
+		return `package p
+
+import "github.com/exo-framework/exo"
+
+type Req struct {
+	exo.Post ` + "`route:\"/:ids\"`" + `
+	` + field + `
+}
+
+func handle(Req) error {
+	return nil
+}
+`
+	}
+
+	analyzeErrors(t, []analyzeTest{
+		{name: "query", src: src("V []int `query:\"v\"`")},
+		{name: "header", src: src("V []string `header:\"V\"`")},
+		{name: "form", src: src("V []float64 `form:\"v\" sep:\";\"`")},
+		{name: "defaults of the elements", src: src("V []int `query:\"v\" sep:\",\" default:\"1,2\"`")},
+		{name: "path", src: src("V []int `path:\"ids\"`"), err: ErrUnsupportedFieldType, msg: "field V in struct Req: path parameters cannot be slices"},
+		{name: "cookie", src: src("V []string `cookie:\"v\"`"), err: ErrUnsupportedFieldType, msg: "field V in struct Req: cookie parameters cannot be slices"},
+		{name: "separator of a value", src: src("V string `query:\"v\" sep:\",\"`"), err: ErrUnsupportedFieldType, msg: "sep and explode can only be used for slices"},
+		{name: "explode of a value", src: src("V string `query:\"v\" explode:\"false\"`"), err: ErrUnsupportedFieldType, msg: "sep and explode can only be used for slices"},
+		{name: "invalid explode", src: src("V []string `query:\"v\" explode:\"1\"`"), err: ErrUnsupportedFieldType, msg: "explode must be true or false"},
+		{name: "invalid default of an element", src: src("V []int `query:\"v\" sep:\",\" default:\"1,x\"`"), err: ErrInvalidDefault, msg: "field V in struct Req"},
+		{name: "unsupported element", src: src("V [][]int `query:\"v\"`"), err: ErrUnsupportedFieldType},
+	})
+}
+
+func TestAnalyzeGroups(t *testing.T) {
+	src := func(decl string) string {
+		return `package p
+
+import (
+	"github.com/exo-framework/exo"
+	"github.com/gofiber/fiber/v2"
+)
+
+const prefix = "/orders"
+
+var _ = prefix
+
+func audit(c *fiber.Ctx) error {
+	return c.Next()
+}
+
+var _ = audit
+
+` + decl + `
+
+type Req struct {
+	exo.Get ` + "`route:\"/:id\"`" + `
+	Id int ` + "`path:\"id\"`" + `
+}
+
+func handle(Req) error {
+	return nil
+}
+`
+	}
+
+	tests := []struct {
+		name  string
+		decl  string
+		group *Group
+		path  string
+		err   error
+		msg   string
+	}{
+		{"no group", "", nil, "/:id", nil, ""},
+		{"prefix and middleware", `var Routes = exo.Group{Prefix: "/orders", Middleware: []fiber.Handler{audit}}`, &Group{Var: "Routes", Prefix: "/orders"}, "/orders/:id", nil, ""},
+		{"constant prefix", `var Routes = exo.Group{Prefix: prefix + "/v2"}`, &Group{Var: "Routes", Prefix: "/orders/v2"}, "/orders/v2/:id", nil, ""},
+		{"middleware only", `var Routes = exo.Group{Middleware: []fiber.Handler{audit}}`, &Group{Var: "Routes"}, "/:id", nil, ""},
+		{"zero value", `var Routes exo.Group`, &Group{Var: "Routes"}, "/:id", nil, ""},
+		{"other variables", `var limit, Routes = 10, exo.Group{Prefix: "/orders"}
+
+var _ = limit`, &Group{Var: "Routes", Prefix: "/orders"}, "/orders/:id", nil, ""},
+		{"no composite literal", `var Routes = newGroup()
+
+func newGroup() exo.Group {
+	return exo.Group{}
+}`, nil, "", ErrInvalidGroup, "group Routes must be declared as exo.Group{...}"},
+		{"no field names", `var Routes = exo.Group{"/orders", nil}`, nil, "", ErrInvalidGroup, "group Routes must be declared with field names"},
+		{"variable prefix", `var p = "/orders"
+
+var Routes = exo.Group{Prefix: p}`, nil, "", ErrInvalidGroup, "the prefix of group Routes must be a constant string"},
+		{"no leading slash", `var Routes = exo.Group{Prefix: "orders"}`, nil, "", ErrInvalidGroup, `must start and must not end with /, got "orders"`},
+		{"trailing slash", `var Routes = exo.Group{Prefix: "/orders/"}`, nil, "", ErrInvalidGroup, `must start and must not end with /, got "/orders/"`},
+		{"parameter", `var Routes = exo.Group{Prefix: "/tenants/:tenant"}`, nil, "", ErrInvalidGroup, `must not contain parameters, got "/tenants/:tenant"`},
+		{"two groups", `var (
+	Routes = exo.Group{Prefix: "/orders"}
+	Admin  = exo.Group{Prefix: "/admin"}
+)`, nil, "", ErrInvalidGroup, "package p declares the groups Routes and Admin"},
+	}
+
+	srcs := make([]string, len(tests))
+	for i, tt := range tests {
+		srcs[i] = src(tt.decl)
+	}
+
+	gens, _, errs := analyzeSources(t, srcs...)
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err != nil {
+				if !errors.Is(errs[i], tt.err) || !strings.Contains(errs[i].Error(), tt.msg) {
+					t.Fatalf("expected %v containing %q, got %v", tt.err, tt.msg, errs[i])
+				}
+				return
+			}
+
+			if errs[i] != nil {
+				t.Fatalf("unexpected error: %v", errs[i])
+			}
+
+			var paths []string
+			for _, reqFiles := range gens[i].packages {
+				for _, reqFile := range reqFiles {
+					if !reflect.DeepEqual(reqFile.Group, tt.group) {
+						t.Errorf("expected group %+v, got %+v", tt.group, reqFile.Group)
+					}
+
+					for _, req := range reqFile.Requests {
+						paths = append(paths, req.Path())
+					}
+				}
+			}
+
+			if !reflect.DeepEqual(paths, []string{tt.path}) {
+				t.Errorf("expected path %s, got %v", tt.path, paths)
+			}
+		})
+	}
+}
+
+func TestAnalyzeMiddleware(t *testing.T) {
+	src := func(use string) string {
+		return `package p
+
+import (
+	"github.com/exo-framework/exo"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+)
+
+func audit(c *fiber.Ctx) error {
+	return c.Next()
+}
+
+var limit = limiter.New()
+
+type middleware func(*fiber.Ctx) error
+
+var named middleware = audit
+
+var count int
+
+func log(c *fiber.Ctx) {}
+
+func generic[T any](c *fiber.Ctx) error {
+	return c.Next()
+}
+
+var (
+	_ = audit
+	_ = limit
+	_ = named
+	_ = count
+	_ = log
+	_ = generic[int]
+)
+
+type Req struct {
+	exo.Get ` + "`route:\"/\" use:\"" + use + "\"`" + `
+}
+
+func handle(Req) error {
+	return nil
+}
+`
+	}
+
+	tests := []struct {
+		name       string
+		use        string
+		middleware []string
+		err        error
+		msg        string
+	}{
+		{"function", "audit", []string{"audit"}, nil, ""},
+		{"variable", "limit", []string{"limit"}, nil, ""},
+		{"named function type", "named", []string{"named"}, nil, ""},
+		{"in order", " limit, audit ,", []string{"limit", "audit"}, nil, ""},
+		{"empty", "", []string{}, nil, ""},
+		{"not declared", "auth", nil, ErrFunctionNotFound, "middleware: auth for struct Req"},
+		{"no function", "count", nil, ErrHandlerIllegalSignature, "middleware: count for struct Req must be a fiber.Handler, got int"},
+		{"no error", "log", nil, ErrHandlerIllegalSignature, "middleware: log for struct Req must be a fiber.Handler, got func(c *fiber.Ctx)"},
+		{"type parameters", "generic", nil, ErrHandlerIllegalSignature, "middleware: generic for struct Req must be a fiber.Handler"},
+		{"type", "middleware", nil, ErrHandlerIllegalSignature, "middleware: middleware for struct Req must be a fiber.Handler"},
+	}
+
+	srcs := make([]string, len(tests))
+	for i, tt := range tests {
+		srcs[i] = src(tt.use)
+	}
+
+	gens, _, errs := analyzeSources(t, srcs...)
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err != nil {
+				if !errors.Is(errs[i], tt.err) || !strings.Contains(errs[i].Error(), tt.msg) {
+					t.Fatalf("expected %v containing %q, got %v", tt.err, tt.msg, errs[i])
+				}
+				return
+			}
+
+			if errs[i] != nil {
+				t.Fatalf("unexpected error: %v", errs[i])
+			}
+
+			for _, reqFiles := range gens[i].packages {
+				for _, req := range reqFiles[0].Requests {
+					if !reflect.DeepEqual(req.Middleware, tt.middleware) {
+						t.Errorf("expected %v, got %v", tt.middleware, req.Middleware)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzeControllers(t *testing.T) {
+	src := func(handlers string) string {
+		return `package p
+
+import "github.com/exo-framework/exo"
+
+type Ctl struct{}
+
+type Other struct{}
+
+type Box[T any] struct{}
+
+type A struct {
+	exo.Get ` + "`route:\"/a\"`" + `
+}
+
+type B struct {
+	exo.Get ` + "`route:\"/b\"`" + `
+}
+
+` + handlers + `
+`
+	}
+
+	tests := []struct {
+		name       string
+		handlers   string
+		controller string
+		pointer    bool
+		err        error
+		msg        string
+	}{
+		{"functions", "func a(A) error {\n\treturn nil\n}\n\nfunc b(B) error {\n\treturn nil\n}", "", false, nil, ""},
+		{"value receivers", "func (Ctl) a(A) error {\n\treturn nil\n}\n\nfunc (Ctl) b(B) error {\n\treturn nil\n}", "Ctl", false, nil, ""},
+		{"pointer receiver", "func (*Ctl) a(A) error {\n\treturn nil\n}\n\nfunc (Ctl) b(B) error {\n\treturn nil\n}", "Ctl", true, nil, ""},
+		{"functions and methods", "func a(A) error {\n\treturn nil\n}\n\nfunc (*Ctl) b(B) error {\n\treturn nil\n}", "Ctl", true, nil, ""},
+		{"methods of different types", "func (*Ctl) a(A) error {\n\treturn nil\n}\n\nfunc (*Other) b(B) error {\n\treturn nil\n}", "", false, ErrHandlerIllegalSignature, "but other handlers are methods of"},
+		{"function and method with the same name", "func get(A) error {\n\treturn nil\n}\n\nfunc (*Ctl) get(B) error {\n\treturn nil\n}", "", false, ErrHandlerIllegalSignature, "handler: get is declared as function and as method"},
+		{"method of a generic type", "func a(A) error {\n\treturn nil\n}\n\nfunc (Box[T]) b(B) error {\n\treturn nil\n}", "", false, ErrFunctionNotFound, "handler: for struct B"},
+	}
+
+	srcs := make([]string, len(tests))
+	for i, tt := range tests {
+		srcs[i] = src(tt.handlers)
+	}
+
+	gens, _, errs := analyzeSources(t, srcs...)
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err != nil {
+				if !errors.Is(errs[i], tt.err) || !strings.Contains(errs[i].Error(), tt.msg) {
+					t.Fatalf("expected %v containing %q, got %v", tt.err, tt.msg, errs[i])
+				}
+				return
+			}
+
+			if errs[i] != nil {
+				t.Fatalf("unexpected error: %v", errs[i])
+			}
+
+			for _, files := range gens[i].packages {
+				if controller, pointer := Controller(files); controller != tt.controller || pointer != tt.pointer {
+					t.Errorf("expected controller %q (pointer %v), got %q (pointer %v)", tt.controller, tt.pointer, controller, pointer)
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzeTimeouts(t *testing.T) {
+	src := func(tag string) string {
+		return `package p
+
+import "github.com/exo-framework/exo"
+
+type Req struct {
+	exo.Get ` + "`route:\"/\"" + tag + "`" + `
+}
+
+func handle(Req) error {
+	return nil
+}
+`
+	}
+
+	tests := []struct {
+		name    string
+		tag     string
+		timeout time.Duration
+		err     error
+		msg     string
+	}{
+		{"no timeout", "", 0, nil, ""},
+		{"seconds", ` timeout:"5s"`, 5 * time.Second, nil, ""},
+		{"minutes and seconds", ` timeout:"1m30s"`, 90 * time.Second, nil, ""},
+		{"milliseconds", ` timeout:"250ms"`, 250 * time.Millisecond, nil, ""},
+		{"no unit", ` timeout:"5"`, 0, ErrInvalidTimeoutTag, `timeout of struct Req must be a positive duration like 5s, got "5"`},
+		{"empty", ` timeout:""`, 0, ErrInvalidTimeoutTag, `got ""`},
+		{"zero", ` timeout:"0s"`, 0, ErrInvalidTimeoutTag, `got "0s"`},
+		{"negative", ` timeout:"-1s"`, 0, ErrInvalidTimeoutTag, `got "-1s"`},
+	}
+
+	srcs := make([]string, len(tests))
+	for i, tt := range tests {
+		srcs[i] = src(tt.tag)
+	}
+
+	gens, _, errs := analyzeSources(t, srcs...)
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err != nil {
+				if !errors.Is(errs[i], tt.err) || !strings.Contains(errs[i].Error(), tt.msg) {
+					t.Fatalf("expected %v containing %q, got %v", tt.err, tt.msg, errs[i])
+				}
+				return
+			}
+
+			if errs[i] != nil {
+				t.Fatalf("unexpected error: %v", errs[i])
+			}
+
+			for _, reqFiles := range gens[i].packages {
+				for _, req := range reqFiles[0].Requests {
+					if req.Timeout != tt.timeout {
+						t.Errorf("expected %v, got %v", tt.timeout, req.Timeout)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,406 @@
+package gen
+
+import (
+	"fmt"
+	"go/types"
+	"path"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/dave/jennifer/jen"
+)
+
+// GenerateGoClient generates a client_gen.go file for every analyzed package. It contains a client with one method per request struct,
+// which encodes the request struct using the same tags the generated handlers parse.
+func (g *Generator) GenerateGoClient() error {
+	for dir, files := range g.packages {
+		if len(files) == 0 {
+			continue
+		}
+
+		if err := g.generateGoClientPackage(dir, files); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (g *Generator) generateGoClientPackage(dir string, files []RequestsFile) error {
+	file := jen.NewFilePathName(files[0].PkgPath, files[0].Package)
+	file.PackageComment("Code generated by exo. DO NOT EDIT.")
+
+	file.Comment("Client is a generated client for the routes of this package.")
+	file.Type().Id("Client").Struct(
+		jen.Op("*").Qual(exoPkgPath, "Client"),
+	)
+
+	file.Comment("NewClient creates a new client for the routes of this package using the given connection settings.")
+	file.Func().Id("NewClient").Params(
+		jen.Id("base").Op("*").Qual(exoPkgPath, "Client"),
+	).Op("*").Id("Client").Block(
+		jen.Return(jen.Op("&").Id("Client").Values(jen.Id("base"))),
+	)
+
+	for _, reqFile := range files {
+		for _, req := range reqFile.Requests {
+			method, err := g.generateClientMethod(req)
+			if err != nil {
+				return err
+			}
+
+			file.Comment(fmt.Sprintf("%s sends a %s request to %s.", req.StructName, strings.ToUpper(string(req.Method)), req.Path()))
+			file.Add(method)
+		}
+	}
+
+	return g.save(file, path.Join(dir, "client_gen.go"))
+}
+
+func (g *Generator) generateClientMethod(req Request) (jen.Code, error) {
+	codes, routePath, err := g.clientRoutePath(req)
+	if err != nil {
+		return nil, err
+	}
+
+	creq := jen.Dict{
+		jen.Id("Method"): jen.Lit(strings.ToUpper(string(req.Method))),
+		jen.Id("Path"):   routePath,
+		jen.Id("Query"):  jen.Qual("net/url", "Values").Values(),
+		jen.Id("Header"): jen.Qual("net/http", "Header").Values(),
+		jen.Id("Form"):   jen.Qual("net/url", "Values").Values(),
+	}
+	if slices.ContainsFunc(req.Fields, func(field Field) bool { return field.FieldType == FieldFile }) {
+		creq[jen.Id("Files")] = jen.Map(jen.String()).Index().Qual(exoPkgPath, "ClientFile").Values()
+	}
+
+	codes = append(codes, jen.Id("creq").Op(":=").Qual(exoPkgPath, "ClientRequest").Values(creq))
+
+	for _, field := range req.Fields {
+		if field.FieldType == FieldBody {
+			codes = append(codes, jen.Id("creq").Dot("Body").Op("=").Id("req").Dot(field.Name))
+		}
+	}
+
+	// records loaded from the database are sent as their keys, which the method takes as strings
+	params := []jen.Code{jen.Id("ctx").Qual("context", "Context"), jen.Id("req").Id(req.StructName)}
+	keys := clientKeys(req)
+	for _, key := range keys {
+		params = append(params, jen.Id(clientParamName("key_", key)).String())
+	}
+
+	// files are passed to the method as readers, as the fields hold files uploaded to the service
+	for _, field := range req.Fields {
+		if field.FieldType != FieldFile {
+			continue
+		}
+
+		name := jen.Id(clientParamName("file_", field))
+		files := jen.Index().Qual(exoPkgPath, "ClientFile").Values(name.Clone())
+		sent := name.Clone().Dot("Content").Op("!=").Nil()
+		if field.SliceElem() != nil {
+			params = append(params, name.Clone().Index().Qual(exoPkgPath, "ClientFile"))
+			files = name.Clone()
+			sent = jen.Len(name.Clone()).Op(">").Lit(0)
+		} else {
+			params = append(params, name.Clone().Qual(exoPkgPath, "ClientFile"))
+		}
+
+		codes = append(codes,
+			jen.If(sent).Block(
+				jen.Id("creq").Dot("Files").Index(jen.Lit(field.FieldKey)).Op("=").Add(files),
+			))
+	}
+
+	for _, field := range req.Fields {
+		value := g.clientValue(field)
+		isKey := field.LoadFromDB != nil
+		if isKey {
+			if !slices.ContainsFunc(keys, func(key Field) bool { return key.Name == field.Name }) {
+				continue
+			}
+
+			value = jen.Id(clientParamName("key_", field))
+		}
+
+		target := ""
+		switch field.FieldType {
+		case FieldQuery:
+			target = "Query"
+		case FieldHeader:
+			target = "Header"
+		case FieldCookie:
+			target = "Cookies"
+		case FieldForm:
+			// form parameters of requests without a body are sent as query
+			target = "Form"
+			if req.HasNoBody() {
+				target = "Query"
+			}
+		default:
+			continue
+		}
+
+		if elem := field.SliceElem(); elem != nil {
+			codes = append(codes, g.clientSliceValues(field, elem, target)...)
+			continue
+		}
+
+		set := jen.Id("creq").Dot(target).Dot("Set").Call(jen.Lit(field.FieldKey), value)
+		if field.FieldType == FieldCookie {
+			set = jen.Id("creq").Dot("Cookies").Op("=").Append(jen.Id("creq").Dot("Cookies"), jen.Op("&").Qual("net/http", "Cookie").Values(jen.Dict{
+				jen.Id("Name"):  jen.Lit(field.FieldKey),
+				jen.Id("Value"): value,
+			}))
+		}
+		switch {
+		case isKey:
+			set = jen.If(jen.Id(clientParamName("key_", field)).Op("!=").Lit("")).Block(set)
+		case field.Optional():
+			set = jen.If(jen.Id("req").Dot(field.Name).Op("!=").Nil()).Block(set)
+		case paramKindOf(field.GoType) == paramString:
+			set = jen.If(jen.Id("req").Dot(field.Name).Op("!=").Lit("")).Block(set)
+		}
+
+		codes = append(codes, set)
+	}
+
+	returns := []jen.Code{}
+	values := []jen.Code{}
+	bodyIndex, bodyKind, _ := req.Handler.Body()
+
+	// serialized values are negotiated, the client reads them as JSON
+	if bodyKind == ReturnSerialize {
+		codes = append(codes, jen.Id("creq").Dot("Header").Dot("Set").Call(jen.Lit("Accept"), jen.Lit("application/json")))
+	}
+
+	for i, kind := range req.Handler.ReturnKinds {
+		if kind == ReturnError {
+			continue
+		}
+
+		rname := fmt.Sprintf("r_%d", i)
+		returns = append(returns, typeCode(req.Handler.ReturnGoTypes[i]))
+		values = append(values, jen.Id(rname))
+		codes = append(codes, jen.Var().Id(rname).Add(typeCode(req.Handler.ReturnGoTypes[i])))
+	}
+
+	returns = append(returns, jen.Error())
+
+	codes = append(codes,
+		jen.List(jen.Id("res"), jen.Err()).Op(":=").Id("cl").Dot("Do").Call(jen.Id("ctx"), jen.Id("creq")),
+		jen.If(jen.Err().Op("!=").Nil()).Block(
+			jen.Return(append(append([]jen.Code{}, values...), jen.Err())...),
+		),
+		jen.Defer().Id("res").Dot("Body").Dot("Close").Call(),
+	)
+
+	read := func(target jen.Code) jen.Code {
+		return jen.If(
+			jen.Err().Op(":=").Qual(exoPkgPath, "ReadResponse").Call(jen.Id("res"), target),
+			jen.Err().Op("!=").Nil(),
+		).Block(
+			jen.Return(append(append([]jen.Code{}, values...), jen.Err())...),
+		)
+	}
+
+	for i, kind := range req.Handler.ReturnKinds {
+		rname := fmt.Sprintf("r_%d", i)
+
+		switch {
+		case kind == ReturnStatus:
+			codes = append(codes, jen.Id(rname).Op("=").Add(typeCode(req.Handler.ReturnGoTypes[i])).Call(jen.Id("res").Dot("StatusCode")))
+		case kind == ReturnCookies:
+			codes = append(codes, jen.Id(rname).Op("=").Qual(exoPkgPath, "ResponseCookies").Call(jen.Id("res")))
+		case kind == ReturnResponse:
+			codes = append(codes,
+				jen.Id(rname).Dot("Status").Op("=").Id("res").Dot("StatusCode"),
+				jen.Id(rname).Dot("Header").Op("=").Id("res").Dot("Header"),
+				jen.Id(rname).Dot("Cookies").Op("=").Qual(exoPkgPath, "ResponseCookies").Call(jen.Id("res")),
+				read(jen.Op("&").Id(rname).Dot("Body")),
+			)
+		case i == bodyIndex && kind == ReturnSerialize:
+			codes = append(codes, read(jen.Op("&").Id(rname).Dot("Value")))
+		case i == bodyIndex:
+			codes = append(codes, read(jen.Op("&").Id(rname)))
+		}
+	}
+
+	codes = append(codes, jen.Return(append(values, jen.Nil())...))
+
+	return jen.Func().Params(jen.Id("cl").Op("*").Id("Client")).Id(req.StructName).Params(
+		params...,
+	).Params(returns...).Block(codes...), nil
+}
+
+// clientKeys returns the fields loaded from the database by a parameter which no other field is bound to. The client cannot send the
+// record, so the key is passed to the generated method instead.
+func clientKeys(req Request) []Field {
+	bound := map[string]bool{}
+	for _, field := range req.Fields {
+		if field.LoadFromDB == nil {
+			bound[string(field.FieldType)+":"+field.FieldKey] = true
+		}
+	}
+
+	keys := []Field{}
+	for _, field := range req.Fields {
+		key := string(field.FieldType) + ":" + field.FieldKey
+		if field.LoadFromDB == nil || field.FieldType == FieldDB || bound[key] {
+			continue
+		}
+
+		bound[key] = true
+		keys = append(keys, field)
+	}
+
+	return keys
+}
+
+// clientParamName returns the name of a parameter of a generated method taking the value of a field, e.g. key_id for the key of a
+// record or file_avatar for a file.
+func clientParamName(prefix string, field Field) string {
+	return prefix + strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, field.FieldKey)
+}
+
+// clientRoutePath returns the expression building the path of a request. Optional parameters are escaped before by the returned codes.
+func (g *Generator) clientRoutePath(req Request) ([]jen.Code, jen.Code, error) {
+	codes := []jen.Code{}
+	parts := []jen.Code{}
+	route := req.Path()
+	last := 0
+
+	for _, m := range routeParamRegex.FindAllStringSubmatchIndex(route, -1) {
+		if m[0] > last {
+			parts = append(parts, jen.Lit(route[last:m[0]]))
+		}
+
+		name := route[m[2]:m[3]]
+
+		var param *Field
+		for i, field := range req.Fields {
+			if field.FieldType == FieldPath && field.FieldKey == name && field.LoadFromDB == nil {
+				param = &req.Fields[i]
+				break
+			}
+		}
+
+		// parameters only bound to records are passed to the method as keys
+		if param == nil {
+			for _, key := range clientKeys(req) {
+				if key.FieldType == FieldPath && key.FieldKey == name {
+					param = &key
+					break
+				}
+			}
+		}
+
+		if param == nil {
+			return nil, nil, fmt.Errorf("client: route parameter %s of struct %s is not bound to a field", name, req.StructName)
+		}
+
+		value := jen.Qual("net/url", "PathEscape").Call(g.clientValue(*param))
+		if param.LoadFromDB != nil {
+			value = jen.Qual("net/url", "PathEscape").Call(jen.Id(clientParamName("key_", *param)))
+		} else if param.Optional() {
+			codes = append(codes,
+				jen.Id("path_"+param.Name).Op(":=").Lit(""),
+				jen.If(jen.Id("req").Dot(param.Name).Op("!=").Nil()).Block(
+					jen.Id("path_"+param.Name).Op("=").Add(value),
+				))
+			value = jen.Id("path_" + param.Name)
+		}
+
+		parts = append(parts, value)
+		last = m[1]
+	}
+
+	if last < len(route) || len(parts) == 0 {
+		parts = append(parts, jen.Lit(route[last:]))
+	}
+
+	path := parts[0]
+	for _, part := range parts[1:] {
+		path = jen.Add(path).Op("+").Add(part)
+	}
+
+	return codes, path, nil
+}
+
+// clientSliceValues adds the values of a slice parameter as repeated keys, or joined by the separator of the field.
+func (g *Generator) clientSliceValues(field Field, elem types.Type, target string) []jen.Code {
+	value := clientFormat(elem, field.Format, jen.Id("v"), false)
+
+	if field.Separator == "" {
+		return []jen.Code{
+			jen.For(jen.List(jen.Id("_"), jen.Id("v")).Op(":=").Range().Id("req").Dot(field.Name)).Block(
+				jen.Id("creq").Dot(target).Dot("Add").Call(jen.Lit(field.FieldKey), value),
+			),
+		}
+	}
+
+	values := "values_" + field.Name
+	return []jen.Code{
+		jen.If(jen.Len(jen.Id("req").Dot(field.Name)).Op(">").Lit(0)).Block(
+			jen.Id(values).Op(":=").Make(jen.Index().String(), jen.Lit(0), jen.Len(jen.Id("req").Dot(field.Name))),
+			jen.For(jen.List(jen.Id("_"), jen.Id("v")).Op(":=").Range().Id("req").Dot(field.Name)).Block(
+				jen.Id(values).Op("=").Append(jen.Id(values), value),
+			),
+			jen.Id("creq").Dot(target).Dot("Set").Call(jen.Lit(field.FieldKey), jen.Qual("strings", "Join").Call(jen.Id(values), jen.Lit(field.Separator))),
+		),
+	}
+}
+
+// clientValue formats the value of a parameter. Optional parameters are dereferenced, they have to be checked for nil before.
+func (g *Generator) clientValue(field Field) jen.Code {
+	t, _ := field.ParamType()
+	return clientFormat(t, field.Format, jen.Id("req").Dot(field.Name), field.Optional())
+}
+
+// clientFormat formats value of type t the way the generated handlers parse it. If pointer is true, value is a pointer to the value.
+func clientFormat(t types.Type, format string, value *jen.Statement, pointer bool) jen.Code {
+	// methods are called on the pointer directly
+	deref := value.Clone()
+	if pointer {
+		deref = jen.Op("*").Add(value.Clone())
+	}
+
+	switch paramKindOf(t) {
+	case paramString:
+		if types.Identical(t, types.Typ[types.String]) {
+			return deref
+		}
+
+		return jen.String().Call(deref)
+	case paramTime:
+		switch strings.ToLower(format) {
+		case "", "rfc3339":
+			return value.Dot("Format").Call(jen.Qual("time", "RFC3339Nano"))
+		case "date":
+			return value.Dot("Format").Call(jen.Qual("time", "DateOnly"))
+		case "unix":
+			return jen.Qual("strconv", "FormatInt").Call(value.Dot("Unix").Call(), jen.Lit(10))
+		case "unixmilli":
+			return jen.Qual("strconv", "FormatInt").Call(value.Dot("UnixMilli").Call(), jen.Lit(10))
+		}
+
+		return value.Dot("Format").Call(jen.Lit(format))
+	case paramDuration:
+		return value.Dot("String").Call()
+	case paramText:
+		switch {
+		case types.Implements(t, textMarshaler) || (pointer && types.Implements(types.NewPointer(t), textMarshaler)):
+			return jen.Qual(exoPkgPath, "FormatText").Call(value)
+		case types.Implements(types.NewPointer(t), textMarshaler):
+			return jen.Qual(exoPkgPath, "FormatText").Call(jen.Op("&").Add(value))
+		}
+	}
+
+	return jen.Qual("fmt", "Sprint").Call(deref)
+}
@@ -0,0 +1,416 @@
+package gen
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var tsIdentRegex = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+const tsClientRuntime = `export interface ClientOptions {
+  baseUrl?: string;
+  token?: string;
+  headers?: Record<string, string>;
+  fetch?: typeof fetch;
+}
+
+export interface FieldError {
+  field: string;
+  source: "path" | "query" | "header" | "cookie" | "body" | "form" | "file";
+  code: string;
+  message: string;
+}
+
+export interface Problem {
+  type?: string;
+  title: string;
+  status: number;
+  detail?: string;
+  instance?: string;
+  errors?: FieldError[];
+}
+
+export class ExoError extends Error {
+  public problem?: Problem;
+
+  constructor(public status: number, public body: string, contentType?: string | null) {
+    super(body || "request failed with status " + status);
+
+    if (contentType?.split(";")[0].trim() === "application/problem+json") {
+      try {
+        this.problem = JSON.parse(body);
+      } catch {
+        // the body is still available as text
+      }
+    }
+  }
+}
+
+type ResponseType = "json" | "text" | "blob" | "none";
+
+async function request(options: ClientOptions, method: string, path: string, query: URLSearchParams, headers: Record<string, string>, body: BodyInit | undefined, responseType: ResponseType): Promise<any> {
+  const allHeaders: Record<string, string> = { ...options.headers, ...headers };
+  if (options.token !== undefined) {
+    allHeaders["Authorization"] = "Bearer " + options.token;
+  }
+
+  const qs = query.toString();
+  const res = await (options.fetch ?? fetch)((options.baseUrl ?? "") + path + (qs ? "?" + qs : ""), { method, headers: allHeaders, body });
+  if (!res.ok) {
+    throw new ExoError(res.status, await res.text(), res.headers.get("Content-Type"));
+  }
+
+  if (res.status === 204) {
+    return undefined;
+  }
+
+  switch (responseType) {
+    case "json":
+      return res.json();
+    case "text":
+      return res.text();
+    case "blob":
+      return res.blob();
+  }
+
+  return undefined;
+}
+`
+
+// tsClientRuntimeNames are the names declared by tsClientRuntime.
+var tsClientRuntimeNames = []string{"ClientOptions", "FieldError", "Problem", "ExoError", "ResponseType", "request"}
+
+// tsClientWriter collects the named types referenced by the client so that they are emitted once as interfaces.
+type tsClientWriter struct {
+	named  map[string]string
+	owners map[string]string // Go declarations the names of the client belong to
+	err    error
+}
+
+// claim reserves the name for the given Go declaration and reports whether it still has to be emitted. All packages share the
+// names of one client, so declarations of different packages cannot have the same name.
+func (w *tsClientWriter) claim(name, owner string) bool {
+	if other, ok := w.owners[name]; ok {
+		if other != owner && w.err == nil {
+			w.err = errors.Join(ErrNameCollision, fmt.Errorf("the client name %s is used by %s and %s", name, other, owner))
+		}
+
+		return false
+	}
+
+	w.owners[name] = owner
+	return true
+}
+
+// GenerateTSClient generates a typed fetch based TypeScript client for all analyzed requests and writes it to the given file.
+func (g *Generator) GenerateTSClient(out string) error {
+	w := &tsClientWriter{named: map[string]string{}, owners: map[string]string{}}
+	for _, name := range tsClientRuntimeNames {
+		w.claim(name, exoPkgPath)
+	}
+
+	functions := strings.Builder{}
+
+	dirs := make([]string, 0, len(g.packages))
+	for dir := range g.packages {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	for _, dir := range dirs {
+		for _, reqFile := range g.packages[dir] {
+			for _, req := range reqFile.Requests {
+				w.writeRequest(&functions, req, reqFile.PkgPath)
+			}
+		}
+	}
+
+	if w.err != nil {
+		return w.err
+	}
+
+	names := make([]string, 0, len(w.named))
+	for name := range w.named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	b := strings.Builder{}
+	b.WriteString("// Code generated by exo. DO NOT EDIT.\n\n")
+	b.WriteString(tsClientRuntime)
+
+	for _, name := range names {
+		fmt.Fprintf(&b, "\nexport interface %s %s\n", name, w.named[name])
+	}
+
+	b.WriteString(functions.String())
+
+	return os.WriteFile(out, []byte(b.String()), 0644)
+}
+
+func (w *tsClientWriter) writeRequest(b *strings.Builder, req Request, pkg string) {
+	groups := map[FieldType][]string{}
+	groupRequired := map[FieldType]bool{}
+	seen := map[string]bool{}
+	var body *Field
+
+	for i, field := range req.Fields {
+		switch field.FieldType {
+		case FieldPath, FieldQuery, FieldHeader, FieldForm:
+			key := string(field.FieldType) + ":" + field.FieldKey
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			t := "string"
+			if field.LoadFromDB == nil {
+				_, info := field.ParamType()
+				t = w.tsType(info)
+			}
+
+			opt := "?"
+			if field.Required() {
+				opt = ""
+				groupRequired[field.FieldType] = true
+			}
+
+			groups[field.FieldType] = append(groups[field.FieldType], fmt.Sprintf("%s%s: %s", tsProp(field.FieldKey), opt, t))
+		case FieldBody:
+			body = &req.Fields[i]
+		case FieldFile:
+			opt := "?"
+			if field.Required() {
+				opt = ""
+				groupRequired[FieldFile] = true
+			}
+
+			groups[FieldFile] = append(groups[FieldFile], fmt.Sprintf("%s%s: %s", tsProp(field.FieldKey), opt, w.tsType(field.Type)))
+		case FieldCookie:
+			// cookies are not part of the params, the browser sends them on its own
+		}
+	}
+
+	paramsName := req.StructName + "Params"
+	w.claim(paramsName, pkg+"."+req.StructName)
+	w.claim(req.Handler.Name, pkg+"."+req.Handler.Name)
+
+	fmt.Fprintf(b, "\nexport interface %s {\n", paramsName)
+	for _, ft := range []FieldType{FieldPath, FieldQuery, FieldHeader, FieldForm, FieldFile} {
+		if len(groups[ft]) == 0 {
+			continue
+		}
+
+		opt := "?"
+		if groupRequired[ft] {
+			opt = ""
+		}
+
+		fmt.Fprintf(b, "  %s%s: { %s };\n", tsGroupName(ft), opt, strings.Join(groups[ft], "; "))
+	}
+
+	if body != nil {
+		fmt.Fprintf(b, "  body: %s;\n", w.tsType(body.Type))
+	}
+	b.WriteString("}\n")
+
+	resultType, responseType := w.tsResponse(req)
+
+	fmt.Fprintf(b, "\nexport async function %s(params: %s, options: ClientOptions = {}): Promise<%s> {\n", req.Handler.Name, paramsName, resultType)
+	fmt.Fprintf(b, "  const path = %s;\n", tsRoutePath(req.Path()))
+	b.WriteString("  const query = new URLSearchParams();\n")
+	b.WriteString("  const headers: Record<string, string> = {};\n")
+	b.WriteString("  let body: BodyInit | undefined;\n")
+
+	// files can only be uploaded as multipart form, the analyzer rejects forms combined with a body
+	hasForm := !req.HasNoBody() && (len(groups[FieldForm]) > 0 || len(groups[FieldFile]) > 0)
+	if hasForm && len(groups[FieldFile]) > 0 {
+		b.WriteString("  const form = new FormData();\n")
+	} else if hasForm {
+		b.WriteString("  const form = new URLSearchParams();\n")
+	}
+
+	// form parameters of requests without a body are sent as query
+	formTarget := "form"
+	if !hasForm {
+		formTarget = "query"
+	}
+
+	for _, ft := range []FieldType{FieldQuery, FieldHeader, FieldForm, FieldFile} {
+		if len(groups[ft]) == 0 {
+			continue
+		}
+
+		for _, field := range req.Fields {
+			if field.FieldType != ft {
+				continue
+			}
+
+			access := fmt.Sprintf("params.%s?.[%s]", tsGroupName(ft), strconv.Quote(field.FieldKey))
+			value := "String(" + access + ")"
+			if field.SliceElem() != nil && field.Separator != "" {
+				value = fmt.Sprintf("%s.map(String).join(%s)", access, strconv.Quote(field.Separator))
+			}
+
+			set := ""
+			switch ft {
+			case FieldQuery:
+				set = fmt.Sprintf("query.set(%s, %s)", strconv.Quote(field.FieldKey), value)
+			case FieldHeader:
+				set = fmt.Sprintf("headers[%s] = %s", strconv.Quote(field.FieldKey), value)
+			case FieldForm:
+				set = fmt.Sprintf("%s.set(%s, %s)", formTarget, strconv.Quote(field.FieldKey), value)
+			case FieldFile:
+				set = fmt.Sprintf("form.set(%s, %s)", strconv.Quote(field.FieldKey), access)
+			}
+
+			// repeated keys are appended once per value
+			if field.SliceElem() != nil && field.Separator == "" && ft != FieldHeader {
+				target, value := "query", "String(v)"
+				switch ft {
+				case FieldForm:
+					target = formTarget
+				case FieldFile:
+					target, value = "form", "v"
+				}
+
+				set = fmt.Sprintf("%s.forEach((v) => %s.append(%s, %s))", access, target, strconv.Quote(field.FieldKey), value)
+			}
+
+			// null is sent like an absent value, e.g. an optional parameter copied from a nullable field
+			fmt.Fprintf(b, "  if (%s != null) {\n    %s;\n  }\n", access, set)
+		}
+	}
+
+	if hasForm {
+		b.WriteString("  body = form;\n")
+	}
+
+	// serialized values are negotiated, the client reads them as JSON
+	if _, kind, _ := req.Handler.Body(); kind == ReturnSerialize {
+		b.WriteString("  headers[\"Accept\"] = \"application/json\";\n")
+	}
+
+	if body != nil {
+		b.WriteString("  headers[\"Content-Type\"] = \"application/json\";\n")
+		b.WriteString("  body = JSON.stringify(params.body);\n")
+	}
+
+	fmt.Fprintf(b, "  return request(options, %s, path, query, headers, body, %s);\n", strconv.Quote(strings.ToUpper(string(req.Method))), strconv.Quote(responseType))
+	b.WriteString("}\n")
+}
+
+func (w *tsClientWriter) tsResponse(req Request) (string, string) {
+	switch _, kind, t := req.Handler.Body(); kind {
+	case ReturnString:
+		return "string", "text"
+	case ReturnBytes:
+		return "Blob", "blob"
+	case ReturnJSON, ReturnSerialize:
+		return w.tsType(t), "json"
+	}
+
+	return "void", "none"
+}
+
+func (w *tsClientWriter) tsType(t *TypeInfo) string {
+	if t == nil {
+		return "unknown"
+	}
+
+	switch t.Kind {
+	case KindString, KindUUID, KindTime, KindBytes:
+		return "string"
+	case KindFile:
+		return "Blob"
+	case KindInt, KindUint, KindFloat:
+		return "number"
+	case KindBool:
+		return "boolean"
+	case KindPointer:
+		return w.tsType(t.Elem) + " | null"
+	case KindSlice:
+		elem := w.tsType(t.Elem)
+		if strings.Contains(elem, " ") {
+			elem = "(" + elem + ")"
+		}
+		return elem + "[]"
+	case KindMap:
+		return "Record<string, " + w.tsType(t.Elem) + ">"
+	case KindStruct:
+		if t.Name == "" {
+			return w.tsStruct(t)
+		}
+
+		if w.claim(t.Name, t.Pkg+"."+t.Name) && t.Fields != nil {
+			w.named[t.Name] = w.tsStruct(t)
+		}
+
+		return t.Name
+	}
+
+	return "unknown"
+}
+
+func (w *tsClientWriter) tsStruct(t *TypeInfo) string {
+	props := []string{}
+	for _, field := range t.Fields {
+		opt := ""
+		if field.Optional {
+			opt = "?"
+		}
+
+		props = append(props, fmt.Sprintf("%s%s: %s", tsProp(field.JSONName), opt, w.tsType(field.Type)))
+	}
+
+	if len(props) == 0 {
+		return "{}"
+	}
+
+	return "{ " + strings.Join(props, "; ") + " }"
+}
+
+func tsGroupName(t FieldType) string {
+	switch t {
+	case FieldHeader:
+		return "headers"
+	case FieldFile:
+		return "files"
+	}
+
+	return string(t)
+}
+
+func tsProp(name string) string {
+	if tsIdentRegex.MatchString(name) {
+		return name
+	}
+
+	return strconv.Quote(name)
+}
+
+func tsRoutePath(route string) string {
+	parts := []string{}
+	last := 0
+
+	for _, m := range routeParamRegex.FindAllStringSubmatchIndex(route, -1) {
+		if m[0] > last {
+			parts = append(parts, strconv.Quote(route[last:m[0]]))
+		}
+
+		name := route[m[2]:m[3]]
+		parts = append(parts, fmt.Sprintf("encodeURIComponent(String(params.path[%s]))", strconv.Quote(name)))
+		last = m[1]
+	}
+
+	if last < len(route) || len(parts) == 0 {
+		parts = append(parts, strconv.Quote(route[last:]))
+	}
+
+	return strings.Join(parts, " + ")
+}
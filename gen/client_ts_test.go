@@ -0,0 +1,200 @@
+package gen
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const tsClientTestSource = `package p
+
+import (
+	"mime/multipart"
+
+	"github.com/exo-framework/exo"
+)
+
+type Item struct {
+	Name string  ` + "`json:\"name\"`" + `
+	Note *string ` + "`json:\"note,omitempty\"`" + `
+}
+
+type Search struct {
+	exo.Get ` + "`route:\"/items/:id\"`" + `
+	Id      int      ` + "`path:\"id\"`" + `
+	Page    *int     ` + "`query:\"page\"`" + `
+	Tags    []string ` + "`query:\"tag\"`" + `
+	Ids     []int    ` + "`query:\"ids\" sep:\",\"`" + `
+	Trace   string   ` + "`header:\"X-Trace\"`" + `
+	Q       string   ` + "`form:\"q\" validate:\"notempty\"`" + `
+}
+
+func search(Search) ([]Item, error) {
+	return nil, nil
+}
+
+type Login struct {
+	exo.Post ` + "`route:\"/login\"`" + `
+	User     string ` + "`form:\"user\" validate:\"notempty\"`" + `
+}
+
+func login(Login) (string, error) {
+	return "", nil
+}
+
+type Upload struct {
+	exo.Post ` + "`route:\"/upload\"`" + `
+	Files    []*multipart.FileHeader ` + "`file:\"files\"`" + `
+	Title    string                  ` + "`form:\"title\"`" + `
+}
+
+func upload(Upload) ([]byte, error) {
+	return nil, nil
+}
+
+type Put struct {
+	exo.Put ` + "`route:\"/items/:id\"`" + `
+	Id      int  ` + "`path:\"id\"`" + `
+	Item    Item ` + "`body:\"\"`" + `
+}
+
+func put(Put) error {
+	return nil
+}
+
+type Export struct {
+	exo.Get ` + "`route:\"/export\"`" + `
+}
+
+func exportItems(Export) (exo.Serialize[[]Item], error) {
+	return exo.Serialize[[]Item]{}, nil
+}
+`
+
+func TestGenerateTSClient(t *testing.T) {
+	gens, _, errs := analyzeSources(t, tsClientTestSource)
+	if errs[0] != nil {
+		t.Fatal(errs[0])
+	}
+
+	out := filepath.Join(t.TempDir(), "client.ts")
+	if err := gens[0].GenerateTSClient(out); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := string(data)
+
+	tests := []struct {
+		name     string
+		snippets []string
+	}{
+		{"named type", []string{`export interface Item { name: string; note?: string | null }`}},
+		{"params", []string{
+			`path: { id: number };`,
+			`query?: { page?: number; tag?: string[]; ids?: number[] };`,
+			`headers?: { "X-Trace"?: string };`,
+			`form: { q: string };`,
+		}},
+		{"route path", []string{`const path = "/items/" + encodeURIComponent(String(params.path["id"]));`}},
+		{"null guard", []string{"if (params.query?.[\"page\"] != null) {\n    query.set(\"page\", String(params.query?.[\"page\"]));"}},
+		{"repeated query", []string{`params.query?.["tag"].forEach((v) => query.append("tag", String(v)));`}},
+		{"delimited query", []string{`query.set("ids", params.query?.["ids"].map(String).join(","));`}},
+		{"header", []string{`headers["X-Trace"] = String(params.headers?.["X-Trace"]);`}},
+		{"form of a GET request as query", []string{`query.set("q", String(params.form?.["q"]));`}},
+		{"json response", []string{`search(params: SearchParams, options: ClientOptions = {}): Promise<Item[]>`, `"GET", path, query, headers, body, "json");`}},
+		{"urlencoded form", []string{"const form = new URLSearchParams();\n  if (params.form?.[\"user\"] != null) {\n    form.set(\"user\", String(params.form?.[\"user\"]));\n  }\n  body = form;"}},
+		{"text response", []string{`Promise<string>`, `"POST", path, query, headers, body, "text");`}},
+		{"multipart form", []string{"const form = new FormData();", `params.files?.["files"].forEach((v) => form.append("files", v));`, `form.set("title", String(params.form?.["title"]));`}},
+		{"files", []string{`files?: { files?: Blob[] };`}},
+		{"blob response", []string{`Promise<Blob>`, `"POST", path, query, headers, body, "blob");`}},
+		{"json body", []string{`body: Item;`, `headers["Content-Type"] = "application/json";`, `body = JSON.stringify(params.body);`}},
+		{"no content", []string{`Promise<void>`, `"PUT", path, query, headers, body, "none");`}},
+		{"serialized response", []string{`headers["Accept"] = "application/json";`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, snippet := range tt.snippets {
+				if !strings.Contains(code, snippet) {
+					t.Errorf("missing %q in:\n%s", snippet, code)
+				}
+			}
+		})
+	}
+}
+
+func TestTSClientNameCollisions(t *testing.T) {
+	src := func(name, typeName, handler string) string {
+		return `package ` + name + `
+
+import "github.com/exo-framework/exo"
+
+type ` + typeName + ` struct {
+	Name string ` + "`json:\"name\"`" + `
+}
+
+type Get` + name + ` struct {
+	exo.Get ` + "`route:\"/" + name + "\"`" + `
+}
+
+func ` + handler + `(Get` + name + `) (` + typeName + `, error) {
+	return ` + typeName + `{}, nil
+}
+`
+	}
+
+	tests := []struct {
+		name string
+		srcs []string
+		msg  string // empty if the client is generated
+	}{
+		{"distinct names", []string{src("a", "Item", "getA"), src("b", "Other", "getB")}, ""},
+		{"same type name", []string{src("a", "Item", "getA"), src("b", "Item", "getB")}, "the client name Item is used by"},
+		{"same function name", []string{src("a", "Item", "get"), src("b", "Other", "get")}, "the client name get is used by"},
+		{"runtime name", []string{src("a", "Problem", "getA")}, "the client name Problem is used by " + exoPkgPath},
+	}
+
+	// the packages of all tests are loaded at once
+	srcs := []string{}
+	for _, tt := range tests {
+		srcs = append(srcs, tt.srcs...)
+	}
+
+	gens, _, errs := analyzeSources(t, srcs...)
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("package %d: %v", i, err)
+		}
+	}
+
+	offset := 0
+	for _, tt := range tests {
+		g := gens[offset]
+		for _, other := range gens[offset+1 : offset+len(tt.srcs)] {
+			for dir, files := range other.packages {
+				g.packages[dir] = files
+			}
+		}
+		offset += len(tt.srcs)
+
+		t.Run(tt.name, func(t *testing.T) {
+			err := g.GenerateTSClient(filepath.Join(t.TempDir(), "client.ts"))
+			if tt.msg == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrNameCollision) || !strings.Contains(err.Error(), tt.msg) {
+				t.Fatalf("expected %v containing %q, got %v", ErrNameCollision, tt.msg, err)
+			}
+		})
+	}
+}
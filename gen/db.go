@@ -0,0 +1,165 @@
+package gen
+
+import (
+	"fmt"
+	"go/types"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+)
+
+// dbColumnRegex matches the column names of db and owner tags, which are written into the generated SQL as they are.
+var dbColumnRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// parseDBLoad parses the db tag of a field and the tags refining the lookup: preload, deleted and owner. The db tag is either a single
+// column, which is compared with the parameter of the field and defaults to id, or a list like org_id=orgId,slug=slug comparing columns with the parameters
+// of other fields. Nil is returned if the field has no db tag.
+func parseDBLoad(tag reflect.StructTag, model types.Type) (*DBLoad, error) {
+	clause, ok := tag.Lookup("db")
+	if !ok {
+		for _, name := range []string{"preload", "deleted", "owner"} {
+			if _, ok := tag.Lookup(name); ok {
+				return nil, fmt.Errorf("%s can only be used for fields loaded from the database", name)
+			}
+		}
+
+		return nil, nil
+	}
+
+	load := &DBLoad{}
+	if slice, ok := model.Underlying().(*types.Slice); ok {
+		load.Many = true
+		model = slice.Elem()
+	}
+
+	if !strings.Contains(clause, "=") {
+		// gorm names the primary key id by default
+		column := strings.TrimSpace(clause)
+		if column == "" {
+			column = "id"
+		}
+
+		load.Keys = []DBKey{{Column: column}}
+	} else {
+		for _, pair := range splitTagList(clause) {
+			column, param, ok := strings.Cut(pair, "=")
+			column, param = strings.TrimSpace(column), strings.TrimSpace(param)
+			if !ok || column == "" || param == "" {
+				return nil, fmt.Errorf("db must be a column or a list like org_id=orgId,slug=slug, got %q", clause)
+			}
+
+			load.Keys = append(load.Keys, DBKey{Column: column, Param: param})
+		}
+	}
+
+	for _, key := range load.Keys {
+		if !dbColumnRegex.MatchString(key.Column) {
+			return nil, fmt.Errorf("db: %q is no plain column name", key.Column)
+		}
+	}
+
+	for _, preload := range splitTagList(tag.Get("preload")) {
+		// nested associations like Author.Company are checked by gorm
+		association, _, _ := strings.Cut(preload, ".")
+		if obj, _, _ := types.LookupFieldOrMethod(model, true, nil, association); obj == nil {
+			return nil, fmt.Errorf("preload: %s has no association %s", types.TypeString(model, (*types.Package).Name), association)
+		}
+
+		load.Preload = append(load.Preload, preload)
+	}
+
+	if value, ok := tag.Lookup("deleted"); ok {
+		if value != "include" {
+			return nil, fmt.Errorf("deleted must be include, got %q", value)
+		}
+
+		if !isSoftDeleted(model) {
+			return nil, fmt.Errorf("deleted: %s has no gorm.DeletedAt field", types.TypeString(model, (*types.Package).Name))
+		}
+
+		load.Unscoped = true
+	}
+
+	if value, ok := tag.Lookup("owner"); ok {
+		if strings.TrimSpace(value) == "" {
+			return nil, fmt.Errorf("owner must name a column")
+		}
+
+		load.Owner = strings.TrimSpace(value)
+		if !dbColumnRegex.MatchString(load.Owner) {
+			return nil, fmt.Errorf("owner: %q is no plain column name", load.Owner)
+		}
+	}
+
+	return load, nil
+}
+
+// isSoftDeleted reports whether gorm soft-deletes records of the model, which it does for models with a gorm.DeletedAt field, e.g. by
+// embedding gorm.Model.
+func isSoftDeleted(model types.Type) bool {
+	obj, _, _ := types.LookupFieldOrMethod(model, true, nil, "DeletedAt")
+	field, ok := obj.(*types.Var)
+	if !ok {
+		return false
+	}
+
+	named, ok := types.Unalias(field.Type()).(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "gorm.io/gorm" && named.Obj().Name() == "DeletedAt"
+}
+
+// generateDBLoad loads the record of a field, or the records if the field is a slice. values are the values the key columns are compared
+// with, returnErr returns database errors.
+func (g *Generator) generateDBLoad(field Field, values []jen.Code, returnErr func(*jen.Statement) jen.Code) []jen.Code {
+	load := field.LoadFromDB
+	codes := []jen.Code{zeroValue("q_"+field.Name, field.GoType)}
+
+	// soft-deleted records are skipped by gorm unless the query is unscoped
+	query := jen.Qual(g.getDbPkg(), "DB").Dot("WithContext").Call(jen.Id("c").Dot("UserContext").Call())
+	if load.Unscoped {
+		query = query.Dot("Unscoped").Call()
+	}
+
+	for _, preload := range load.Preload {
+		query = query.Dot("Preload").Call(jen.Lit(preload))
+	}
+
+	columns := []string{}
+	for _, key := range load.Keys {
+		columns = append(columns, key.Column+"=?")
+	}
+	query = query.Dot("Where").Call(append([]jen.Code{jen.Lit(strings.Join(columns, " AND "))}, values...)...)
+
+	// records of other subjects are not found, so their existence is not revealed
+	if load.Owner != "" {
+		owner := jen.Id("q_" + field.Name + "_owner")
+		ownerErr := jen.Id("q_" + field.Name + "_owner_err")
+		codes = append(codes,
+			jen.List(owner.Clone(), ownerErr.Clone()).Op(":=").Qual(exoPkgPath, "RequireClaims").Call(jen.Id("c")),
+			jen.If(ownerErr.Clone().Op("!=").Nil()).Block(
+				jen.Return(ownerErr.Clone()),
+			))
+		query = query.Dot("Where").Call(jen.Lit(load.Owner+"=?"), owner.Clone().Dot("Subject"))
+	}
+
+	err := jen.Id("q_" + field.Name + "_err")
+	if load.Many {
+		return append(codes,
+			err.Clone().Op(":=").Add(query).Dot("Find").Call(jen.Op("&").Id("q_"+field.Name)).Dot("Error"),
+			returnErr(err),
+		)
+	}
+
+	return append(codes,
+		err.Clone().Op(":=").Add(query).Dot("First").Call(jen.Op("&").Id("q_"+field.Name)).Dot("Error"),
+		jen.If(
+			jen.Qual("errors", "Is").Call(err.Clone(), jen.Qual("gorm.io/gorm", "ErrRecordNotFound")),
+		).Block(
+			jen.Return(
+				jen.Qual(exoPkgPath, "NotFound").Call(jen.Lit(field.Name+" not found")),
+			),
+		),
+		returnErr(err),
+	)
+}
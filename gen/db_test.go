@@ -0,0 +1,157 @@
+package gen
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// dbSource returns a package with a soft-deleted model and a request with the given fields.
+func dbSource(fields string) string {
+	return `package p
+
+import (
+	"github.com/exo-framework/exo"
+	"gorm.io/gorm"
+)
+
+type Tag struct {
+	gorm.Model
+	ItemID uint
+}
+
+type Item struct {
+	gorm.Model
+	Kind    string
+	Slug    string
+	OwnerId string
+	Tags    []Tag
+}
+
+type Plain struct {
+	ID uint
+}
+
+type Req struct {
+	exo.Get ` + "`route:\"/:kind/:slug\"`" + `
+	` + fields + `
+}
+
+func handle(Req) error {
+	return nil
+}
+`
+}
+
+func TestAnalyzeDBFields(t *testing.T) {
+	kind := "Kind string `path:\"kind\"`\n\tSlug string `path:\"slug\"`\n\t"
+
+	tests := []struct {
+		name  string
+		field string
+		load  *DBLoad
+		err   error
+		msg   string
+	}{
+		{"primary key", "Item Item `path:\"slug\" db:\"\"`", &DBLoad{Keys: []DBKey{{Column: "id"}}}, nil, ""},
+		{"column", "Item Item `path:\"slug\" db:\"slug\"`", &DBLoad{Keys: []DBKey{{Column: "slug"}}}, nil, ""},
+		{"columns of other parameters", kind + "Item Item `db:\"kind=kind, slug=slug\"`", &DBLoad{Keys: []DBKey{{Column: "kind", Param: "kind", Field: "Kind"}, {Column: "slug", Param: "slug", Field: "Slug"}}}, nil, ""},
+		{"slice", kind + "Items []Item `db:\"kind=kind\"`", &DBLoad{Keys: []DBKey{{Column: "kind", Param: "kind", Field: "Kind"}}, Many: true}, nil, ""},
+		{"preload", kind + "Item Item `db:\"kind=kind\" preload:\"Tags, Tags.Item\"`", &DBLoad{Keys: []DBKey{{Column: "kind", Param: "kind", Field: "Kind"}}, Preload: []string{"Tags", "Tags.Item"}}, nil, ""},
+		{"preload of a slice", kind + "Items []Item `db:\"kind=kind\" preload:\"Tags\"`", &DBLoad{Keys: []DBKey{{Column: "kind", Param: "kind", Field: "Kind"}}, Preload: []string{"Tags"}, Many: true}, nil, ""},
+		{"deleted records", "Item Item `path:\"slug\" db:\"slug\" deleted:\"include\"`", &DBLoad{Keys: []DBKey{{Column: "slug"}}, Unscoped: true}, nil, ""},
+		{"owner", "Item Item `path:\"slug\" db:\"slug\" owner:\" owner_id \"`", &DBLoad{Keys: []DBKey{{Column: "slug"}}, Owner: "owner_id"}, nil, ""},
+		{"preload without db", "Item Item `path:\"slug\" preload:\"Tags\"`", nil, ErrInvalidDBTag, "preload can only be used for fields loaded from the database"},
+		{"owner without db", "Item Item `path:\"slug\" owner:\"owner_id\"`", nil, ErrInvalidDBTag, "owner can only be used for fields loaded from the database"},
+		{"missing parameter", kind + "Item Item `db:\"kind=\"`", nil, ErrInvalidDBTag, `db must be a column or a list like org_id=orgId,slug=slug, got "kind="`},
+		{"no plain column", "Item Item `path:\"slug\" db:\"slug;drop\"`", nil, ErrInvalidDBTag, `db: "slug;drop" is no plain column name`},
+		{"no association", "Item Item `path:\"slug\" db:\"slug\" preload:\"Owner\"`", nil, ErrInvalidDBTag, "preload: p.Item has no association Owner"},
+		{"deleted value", "Item Item `path:\"slug\" db:\"slug\" deleted:\"only\"`", nil, ErrInvalidDBTag, `deleted must be include, got "only"`},
+		{"deleted without soft delete", "Item Plain `path:\"slug\" db:\"\" deleted:\"include\"`", nil, ErrInvalidDBTag, "deleted: p.Plain has no gorm.DeletedAt field"},
+		{"empty owner", "Item Item `path:\"slug\" db:\"slug\" owner:\"\"`", nil, ErrInvalidDBTag, "owner must name a column"},
+		{"owner no plain column", "Item Item `path:\"slug\" db:\"slug\" owner:\"owner id\"`", nil, ErrInvalidDBTag, `owner: "owner id" is no plain column name`},
+		{"column without parameter", "Item Item `db:\"slug\"`", nil, ErrInvalidDBTag, "the column slug must be compared with a path, query, header, cookie or form parameter"},
+		{"other parameters and own parameter", kind + "Item Item `path:\"slug\" db:\"kind=kind\"`", nil, ErrInvalidDBTag, "fields looked up by other parameters cannot have a path tag"},
+		{"other parameters and rules", kind + "Item Item `db:\"kind=kind\" validate:\"notempty\"`", nil, ErrInvalidDBTag, "fields looked up by other parameters are validated by these parameters"},
+		{"unbound parameter", kind + "Item Item `db:\"kind=type\"`", nil, ErrInvalidDBTag, "the parameter type must be bound to a path, query, header, cookie or form field which is no slice"},
+		{"slice parameter", "Kinds []string `query:\"kind\"`\n\tItem Item `db:\"kind=kind\"`", nil, ErrInvalidDBTag, "the parameter kind must be bound"},
+	}
+
+	srcs := make([]string, len(tests))
+	for i, tt := range tests {
+		srcs[i] = dbSource(tt.field)
+	}
+
+	gens, _, errs := analyzeSources(t, srcs...)
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err != nil {
+				if !errors.Is(errs[i], tt.err) || !strings.Contains(errs[i].Error(), tt.msg) {
+					t.Fatalf("expected %v containing %q, got %v", tt.err, tt.msg, errs[i])
+				}
+				return
+			}
+
+			if errs[i] != nil {
+				t.Fatalf("unexpected error: %v", errs[i])
+			}
+
+			var loads []*DBLoad
+			for _, reqFiles := range gens[i].packages {
+				for _, field := range reqFiles[0].Requests[0].Fields {
+					if field.LoadFromDB != nil {
+						loads = append(loads, field.LoadFromDB)
+					}
+				}
+			}
+
+			if len(loads) != 1 || !reflect.DeepEqual(loads[0], tt.load) {
+				t.Errorf("expected %+v, got %+v", tt.load, loads)
+			}
+		})
+	}
+}
+
+func TestGenerateDBFields(t *testing.T) {
+	kind := "Kind string `path:\"kind\"`\n\tSlug string `path:\"slug\"`\n\t"
+
+	tests := []struct {
+		name     string
+		field    string
+		snippets []string
+	}{
+		{"primary key", "Item Item `path:\"slug\" db:\"\"`", []string{
+			`db.DB.WithContext(c.UserContext()).Where("id=?", raw_Item).First(&q_Item).Error`,
+			"if errors.Is(q_Item_err, gorm.ErrRecordNotFound) {\n\t\treturn exo.NotFound(\"Item not found\")\n\t}",
+		}},
+		{"columns of other parameters", kind + "Item Item `db:\"kind=kind,slug=slug\"`", []string{
+			`Where("kind=? AND slug=?", q_Kind, q_Slug).First(&q_Item).Error`,
+		}},
+		// slices are empty instead of 404 if no record matches
+		{"slice", kind + "Items []Item `db:\"kind=kind\" preload:\"Tags\"`", []string{
+			"q_Items := []Item{}",
+			`Preload("Tags").Where("kind=?", q_Kind).Find(&q_Items).Error`,
+			"if q_Items_err != nil {\n\t\treturn q_Items_err\n\t}",
+		}},
+		{"deleted records", "Item Item `path:\"slug\" db:\"slug\" deleted:\"include\"`", []string{
+			`WithContext(c.UserContext()).Unscoped().Where("slug=?", raw_Item)`,
+		}},
+		{"owner", "Item Item `path:\"slug\" db:\"slug\" owner:\"owner_id\"`", []string{
+			"q_Item_owner, q_Item_owner_err := exo.RequireClaims(c)\n\tif q_Item_owner_err != nil {\n\t\treturn q_Item_owner_err\n\t}",
+			`Where("slug=?", raw_Item).Where("owner_id=?", q_Item_owner.Subject).First(&q_Item).Error`,
+		}},
+	}
+
+	srcs := make([]string, len(tests))
+	for i, tt := range tests {
+		srcs[i] = dbSource(tt.field)
+	}
+
+	codes := generateSources(t, srcs...)
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			containsAll(t, codes[i], tt.snippets...)
+		})
+	}
+}
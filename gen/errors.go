@@ -7,4 +7,13 @@ var (
 	ErrHandlerIllegalSignature = errors.New("handler function has an illegal signature")
 	ErrMultiplePackages        = errors.New("multiple packages in one directory")
 	ErrInvalidNumberBits       = errors.New("invalid number bits")
+	ErrUnsupportedFieldType    = errors.New("unsupported field type")
+	ErrInvalidValidateTag      = errors.New("invalid validate tag")
+	ErrInvalidDefault          = errors.New("invalid default value")
+	ErrInvalidFileTag          = errors.New("invalid maxsize or accept tag")
+	ErrInvalidGroup            = errors.New("invalid route group")
+	ErrInvalidTimeoutTag       = errors.New("invalid timeout tag")
+	ErrInvalidDBTag            = errors.New("invalid db tag")
+	ErrMissingSourceTag        = errors.New("missing source tag")
+	ErrNameCollision           = errors.New("name used by several packages")
 )
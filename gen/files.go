@@ -0,0 +1,162 @@
+package gen
+
+import (
+	"fmt"
+	"go/types"
+	"math"
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+)
+
+// sizeUnits are the units of maxsize tags. They are powers of 1024, as it is common for upload limits.
+var sizeUnits = []struct {
+	name string
+	size int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// isFileHeader reports whether t is *multipart.FileHeader.
+func isFileHeader(t types.Type) bool {
+	ptr, ok := types.Unalias(t).(*types.Pointer)
+	if !ok {
+		return false
+	}
+
+	named, ok := types.Unalias(ptr.Elem()).(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "mime/multipart" && named.Obj().Name() == "FileHeader"
+}
+
+// parseSize parses the value of a maxsize tag like 5MB, 512KB or 1024 into bytes.
+func parseSize(value string) (int64, error) {
+	number := strings.TrimSpace(value)
+	unit := int64(1)
+
+	for _, u := range sizeUnits {
+		if n, ok := strings.CutSuffix(strings.ToUpper(number), u.name); ok {
+			number, unit = strings.TrimSpace(n), u.size
+			break
+		}
+	}
+
+	size, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || size <= 0 || size > math.MaxInt64/unit {
+		return 0, fmt.Errorf("maxsize must be a positive size like 5MB, got %q", value)
+	}
+
+	return size * unit, nil
+}
+
+// formatSize formats a size in bytes for the error messages, using the largest unit it is a multiple of.
+func formatSize(size int64) string {
+	for _, u := range sizeUnits {
+		if size%u.size == 0 && u.size > 1 {
+			return fmt.Sprintf("%d %s", size/u.size, u.name)
+		}
+	}
+
+	return fmt.Sprintf("%d bytes", size)
+}
+
+// parseAccept parses the value of an accept tag, a list of media types like image/png,image/*.
+func parseAccept(value string) ([]string, error) {
+	accept := splitTagList(value)
+	for _, mediaType := range accept {
+		if _, _, err := mime.ParseMediaType(mediaType); err != nil || !strings.Contains(mediaType, "/") {
+			return nil, fmt.Errorf("accept must be a list of media types like image/png, got %q", mediaType)
+		}
+	}
+
+	return accept, nil
+}
+
+// generateFileChecks checks the size and the media type of an uploaded file, which must not be nil.
+func (g *Generator) generateFileChecks(value *jen.Statement, path fieldPath, field Field) []jen.Code {
+	codes := []jen.Code{}
+
+	if field.MaxSize > 0 {
+		codes = append(codes,
+			jen.If(value.Clone().Dot("Size").Op(">").Op(strconv.FormatInt(field.MaxSize, 10))).Block(
+				addFieldError(path, string(FieldFile), "maxsize", " must not be larger than "+formatSize(field.MaxSize)),
+			))
+	}
+
+	if len(field.Accept) > 0 {
+		args := []jen.Code{value.Clone()}
+		for _, mediaType := range field.Accept {
+			args = append(args, jen.Lit(mediaType))
+		}
+
+		codes = append(codes,
+			jen.If(jen.Op("!").Qual(exoPkgPath, "AcceptsFile").Call(args...)).Block(
+				addFieldError(path, string(FieldFile), "accept", " must be of type "+strings.Join(field.Accept, ", ")),
+			))
+	}
+
+	return codes
+}
+
+// generateFileField retrieves the uploaded files of a file field and checks them.
+func (g *Generator) generateFileField(field Field, regexes *[]jen.Code, prefix string) []jen.Code {
+	path := fieldPath{field.FieldKey}
+	value := jen.Id("q_" + field.Name)
+	codes := []jen.Code{}
+
+	// notempty requires a file, which is checked before the files are
+	rules := []ValidationRule{}
+	for _, rule := range field.Rules {
+		if rule.Name != "notempty" {
+			rules = append(rules, rule)
+		}
+	}
+
+	absent := value.Clone().Op("==").Nil()
+	checks := g.generateFileChecks(value, path, field)
+	if field.SliceElem() != nil {
+		index, file := "i_"+field.Name, "s_"+field.Name
+		codes = append(codes, value.Clone().Op(":=").Qual(exoPkgPath, "FormFiles").Call(jen.Id("c"), jen.Lit(field.FieldKey)))
+
+		absent = jen.Len(value.Clone()).Op("==").Lit(0)
+		checks = []jen.Code{}
+		if fileChecks := g.generateFileChecks(jen.Id(file), path.index(index), field); len(fileChecks) > 0 {
+			checks = append(checks, jen.For(jen.List(jen.Id(index), jen.Id(file)).Op(":=").Range().Add(value.Clone())).Block(fileChecks...))
+		}
+	} else {
+		codes = append(codes, value.Clone().Op(":=").Qual(exoPkgPath, "FormFile").Call(jen.Id("c"), jen.Lit(field.FieldKey)))
+	}
+
+	switch {
+	case field.NotEmpty:
+		check := jen.If(absent).Block(addFieldError(path, string(FieldFile), "required", " is required"))
+		if len(checks) > 0 {
+			check = check.Else().Block(checks...)
+		}
+
+		codes = append(codes, check)
+	case field.SliceElem() != nil:
+		codes = append(codes, checks...)
+	case len(checks) > 0:
+		// absent files are nil
+		codes = append(codes, jen.If(value.Clone().Op("!=").Nil()).Block(checks...))
+	}
+
+	codes = append(codes, g.generateRules(value, path, string(FieldFile), field.GoType, rules, regexes, prefix)...)
+
+	if field.Validator != nil {
+		codes = append(codes,
+			jen.If(
+				jen.Id("q_"+field.Name+"_validator_errmsg").Op(":=").Id(*field.Validator).Call(value.Clone()),
+				jen.Id("q_"+field.Name+"_validator_errmsg").Op("!=").Lit(""),
+			).Block(
+				jen.Id("v_errs").Dot("Add").Call(path.code(""), jen.Lit(string(FieldFile)), jen.Lit("invalid"), jen.Id("q_"+field.Name+"_validator_errmsg")),
+			))
+	}
+
+	return codes
+}
@@ -0,0 +1,119 @@
+package gen
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		value string
+		size  int64
+		err   bool
+	}{
+		{"1024", 1024, false},
+		{"512B", 512, false},
+		{"512KB", 512 << 10, false},
+		{"5MB", 5 << 20, false},
+		{"5 mb", 5 << 20, false},
+		{"2GB", 2 << 30, false},
+		{"", 0, true},
+		{"0", 0, true},
+		{"-1MB", 0, true},
+		{"1.5MB", 0, true},
+		{"5TB", 0, true},
+		{"MB", 0, true},
+		{"9000000000GB", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			size, err := parseSize(tt.value)
+			if (err != nil) != tt.err || size != tt.size {
+				t.Errorf("expected %d, %v, got %d, %v", tt.size, tt.err, size, err)
+			}
+		})
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		size int64
+		want string
+	}{
+		{1, "1 bytes"},
+		{1000, "1000 bytes"},
+		{1024, "1 KB"},
+		{1536, "1536 bytes"},
+		{5 << 20, "5 MB"},
+		{3 << 30, "3 GB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := formatSize(tt.size); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseAccept(t *testing.T) {
+	tests := []struct {
+		value  string
+		accept []string
+		err    bool
+	}{
+		{"image/png", []string{"image/png"}, false},
+		{"image/png, image/jpeg", []string{"image/png", "image/jpeg"}, false},
+		{"image/*,application/pdf", []string{"image/*", "application/pdf"}, false},
+		{"", []string{}, false},
+		{"png", nil, true},
+		{"image/png,.jpg", nil, true},
+		{"image/", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			accept, err := parseAccept(tt.value)
+			if (err != nil) != tt.err || !reflect.DeepEqual(accept, tt.accept) {
+				t.Errorf("expected %q, %v, got %q, %v", tt.accept, tt.err, accept, err)
+			}
+		})
+	}
+}
+
+func TestAnalyzeFiles(t *testing.T) {
+	src := func(field string) string {
+		return `package p
+
+import (
+	"mime/multipart"
+
+	"github.com/exo-framework/exo"
+)
+
+var _ *multipart.FileHeader
+
+type Req struct {
+	exo.Post ` + "`route:\"/\"`" + `
+	` + field + `
+}
+
+func handle(Req) error {
+	return nil
+}
+`
+	}
+
+	analyzeErrors(t, []analyzeTest{
+		{name: "file", src: src("V *multipart.FileHeader `file:\"v\" maxsize:\"5MB\" accept:\"image/*\"`")},
+		{name: "files", src: src("V []*multipart.FileHeader `file:\"v\" validate:\"max=3\"`")},
+		{name: "file value", src: src("V multipart.FileHeader `file:\"v\"`"), err: ErrUnsupportedFieldType, msg: "files must be *multipart.FileHeader or []*multipart.FileHeader"},
+		{name: "file name", src: src("V string `file:\"v\"`"), err: ErrUnsupportedFieldType, msg: "files must be *multipart.FileHeader or []*multipart.FileHeader"},
+		{name: "invalid maxsize", src: src("V *multipart.FileHeader `file:\"v\" maxsize:\"5TB\"`"), err: ErrInvalidFileTag, msg: `maxsize must be a positive size like 5MB, got "5TB"`},
+		{name: "invalid accept", src: src("V *multipart.FileHeader `file:\"v\" accept:\"png\"`"), err: ErrInvalidFileTag, msg: `accept must be a list of media types like image/png, got "png"`},
+		{name: "maxsize of a parameter", src: src("V string `form:\"v\" maxsize:\"1MB\"`"), err: ErrInvalidFileTag, msg: "maxsize and accept can only be used for files"},
+		{name: "accept of a parameter", src: src("V string `form:\"v\" accept:\"image/png\"`"), err: ErrInvalidFileTag, msg: "maxsize and accept can only be used for files"},
+	})
+}
@@ -1,10 +1,15 @@
 package gen
 
 import (
+	"bytes"
+	"go/types"
 	"os"
 	"path"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/dave/jennifer/jen"
 	"github.com/exo-framework/exo/common"
@@ -15,6 +20,12 @@ type Generator struct {
 	packages map[string][]RequestsFile
 	rc       map[string]string
 	module   string
+	root     string
+	ignore   []*regexp.Regexp
+	check    bool
+	outdated []string
+	saved    map[string]bool // files generated by the last check
+	existing []string        // files generated by exo found by Analyze
 }
 
 // NewGenerator creates a new Generator struct.
@@ -24,13 +35,22 @@ func NewGenerator() *Generator {
 		panic(err)
 	}
 
+	root, err := os.Getwd()
+	if err != nil {
+		panic(err)
+	}
+
 	for _, line := range strings.Split(string(gomod), "\n") {
 		if strings.HasPrefix(line, "module ") {
-			return &Generator{
+			g := &Generator{
 				packages: make(map[string][]RequestsFile),
 				rc:       common.LoadRuntimeConfig(),
-				module:   strings.TrimPrefix(line, "module "),
+				module:   strings.TrimSpace(strings.TrimPrefix(line, "module ")),
+				root:     root,
 			}
+
+			g.Ignore(strings.Split(g.rc["GEN_IGNORE"], ",")...)
+			return g
 		}
 	}
 
@@ -52,43 +72,198 @@ func (g *Generator) Generate() error {
 	return nil
 }
 
+// Check generates the glue code in memory and returns the generated files which differ from the files on disk, along with files generated
+// by exo which would no longer be generated, e.g. because their requests file was removed. Go clients are only checked in packages which
+// have one. Nothing is written.
+func (g *Generator) Check() ([]string, error) {
+	g.check = true
+	g.outdated = []string{}
+	g.saved = map[string]bool{}
+	defer func() { g.check = false }()
+
+	if err := g.Generate(); err != nil {
+		return nil, err
+	}
+
+	for dir, files := range g.packages {
+		if _, err := os.Stat(path.Join(dir, "client_gen.go")); err != nil || len(files) == 0 {
+			continue
+		}
+
+		if err := g.generateGoClientPackage(dir, files); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, filename := range g.existing {
+		if !g.saved[filename] {
+			g.outdated = append(g.outdated, filename)
+		}
+	}
+
+	sort.Strings(g.outdated)
+	return g.outdated, nil
+}
+
+func (g *Generator) save(file *jen.File, filename string) error {
+	if !g.check {
+		return file.Save(filename)
+	}
+
+	g.saved[filename] = true
+
+	buf := &bytes.Buffer{}
+	if err := file.Render(buf); err != nil {
+		return err
+	}
+
+	current, err := os.ReadFile(filename)
+	if err != nil || !bytes.Equal(current, buf.Bytes()) {
+		g.outdated = append(g.outdated, filename)
+	}
+
+	return nil
+}
+
 func (g *Generator) generatePackage(dir, pkg string, files []RequestsFile) error {
-	indexFile := jen.NewFile(pkg)
+	indexFile := jen.NewFilePathName(files[0].PkgPath, pkg)
 	indexFile.PackageComment("Code generated by exo. DO NOT EDIT.")
 
 	registers := []jen.Code{}
+	controller, pointer := Controller(files)
 
 	for _, reqFile := range files {
-		file := jen.NewFile(reqFile.Package)
+		file := jen.NewFilePathName(reqFile.PkgPath, reqFile.Package)
 		file.PackageComment("Code generated by exo. DO NOT EDIT.")
 
 		for _, req := range reqFile.Requests {
 			file.Add(g.generateHandler(req))
 
-			registers = append(registers, jen.Id("r").Dot(string(req.Method)).Call(jen.Lit(req.Route), jen.Id("exog_"+req.Handler.Name)))
+			handlers := []jen.Code{}
+			for _, middleware := range req.Middleware {
+				handlers = append(handlers, jen.Id(middleware))
+			}
+			if req.Handler.Receiver != "" {
+				ctrl := jen.Id("ctrl")
+				if pointer && !req.Handler.PointerReceiver {
+					ctrl = jen.Op("*").Id("ctrl")
+				}
+
+				handlers = append(handlers, jen.Id("exog_"+req.Handler.Name).Call(ctrl))
+			} else {
+				handlers = append(handlers, jen.Id("exog_"+req.Handler.Name))
+			}
+
+			args := append([]jen.Code{jen.Lit(req.Path())}, handlers...)
+			if reqFile.Group != nil {
+				// the middleware of the group runs before the middleware of the route
+				args = []jen.Code{jen.Lit(req.Path()), jen.Id(reqFile.Group.Var).Dot("Handlers").Call(handlers...).Op("...")}
+			}
+
+			registers = append(registers, jen.Id("r").Dot(string(req.Method)).Call(args...))
 		}
 
-		if err := file.Save(strings.Replace(reqFile.FileName, ".go", "_gen.go", 1)); err != nil {
+		if err := g.save(file, strings.TrimSuffix(reqFile.FileName, ".go")+"_gen.go"); err != nil {
 			return err
 		}
 	}
 
+	params := []jen.Code{jen.Id("r").Qual("github.com/gofiber/fiber/v2", "Router")}
+	comment := "RegisterRoutes registers the routes of the package. r may be an app or a group, e.g. app.Group(\"/api/v1\")."
+	if controller != "" {
+		param := jen.Id("ctrl")
+		if pointer {
+			param = param.Op("*")
+		}
+
+		params = append(params, param.Id(controller))
+		comment += " The handlers are called on ctrl."
+	}
+
+	indexFile.Comment(comment)
 	indexFile.Add(
 		jen.Func().Id("RegisterRoutes").Params(
-			jen.Id("r").Op("*").Qual("github.com/gofiber/fiber/v2", "App"),
+			params...,
 		).Block(
 			registers...,
 		))
 
-	return indexFile.Save(path.Join(dir, "index_gen.go"))
+	return g.save(indexFile, path.Join(dir, "index_gen.go"))
 }
 
 func (g *Generator) generateHandler(req Request) jen.Code {
-	mainCodes := []jen.Code{}
+	mainCodes := g.generateGuards(req)
+	loadCodes := []jen.Code{}
+
+	// the user context is cancelled once the client disconnects or the deadline of the timeout tag passes. It is passed to the database
+	// and returned by req.Context()
+	var timeout jen.Code = jen.Lit(0)
+	if req.Timeout > 0 {
+		timeout = durationCode(req.Timeout)
+	}
+
+	mainCodes = append(mainCodes,
+		jen.Id("context_stop").Op(":=").Qual(exoPkgPath, "WatchContext").Call(jen.Id("c"), timeout),
+		jen.Defer().Id("context_stop").Call(),
+	)
+
+	// errors caused by the exceeded deadline, e.g. of cancelled database lookups, are answered with 504
+	returnErr := func(err *jen.Statement) jen.Code {
+		if req.Timeout == 0 {
+			return jen.If(err.Clone().Op("!=").Nil()).Block(jen.Return(err))
+		}
+
+		return jen.If(err.Clone().Op("!=").Nil()).Block(
+			jen.Return(jen.Qual(exoPkgPath, "TimeoutError").Call(jen.Id("c").Dot("UserContext").Call(), err)),
+		)
+	}
+	regexes := []jen.Code{}
+	regexPrefix := "exov_" + req.Handler.Name
+	validates := false
+
+	// notempty of string and optional parameters is already checked on the raw value
+	paramRules := func(field Field) []ValidationRule {
+		rules := []ValidationRule{}
+		for _, rule := range field.Rules {
+			if rule.Name != "notempty" || (field.LoadFromDB == nil && paramKindOf(field.GoType) != paramString && !field.Optional()) {
+				rules = append(rules, rule)
+			}
+		}
+		return rules
+	}
 
 	for _, field := range req.Fields {
 		codes := []jen.Code{}
 
+		if field.FieldType == FieldAuth {
+			mainCodes = append(mainCodes, g.generateAuthField(field)...)
+			continue
+		}
+
+		// the parameters are parsed and validated by their own fields
+		if field.FieldType == FieldDB {
+			values := []jen.Code{}
+			for _, key := range field.LoadFromDB.Keys {
+				values = append(values, jen.Id("q_"+key.Field))
+			}
+
+			loadCodes = append(loadCodes, g.generateDBLoad(field, values, returnErr)...)
+			continue
+		}
+
+		if !validates {
+			validates = true
+			mainCodes = append(mainCodes, jen.Id("v_errs").Op(":=").Qual(exoPkgPath, "ValidationErrors").Values())
+		}
+
+		if field.FieldType == FieldFile {
+			mainCodes = append(mainCodes, g.generateFileField(field, &regexes, regexPrefix)...)
+			continue
+		}
+
+		source := string(field.FieldType)
+		path := fieldPath{field.FieldKey}
+
 		if field.FieldType != FieldBody {
 			rvPrefix := "raw_"
 			if field.DataType == "string" {
@@ -96,156 +271,175 @@ func (g *Generator) generateHandler(req Request) jen.Code {
 			}
 
 			varname := rvPrefix + field.Name
-			codes = append(codes, jen.Id(varname).Op(":=").Id("c").Dot(field.FieldType.SimpleRetriever()).Call(jen.Lit(field.FieldKey)))
+			if field.SliceElem() != nil {
+				codes = append(codes, jen.Id(varname).Op(":=").Qual(exoPkgPath, field.FieldType.MultiRetriever()).Call(jen.Id("c"), jen.Lit(field.FieldKey), jen.Lit(field.Separator)))
+
+				if field.Default != nil {
+					defaults := []jen.Code{jen.Lit(*field.Default)}
+					if field.Separator != "" {
+						defaults = []jen.Code{}
+						for _, value := range strings.Split(*field.Default, field.Separator) {
+							defaults = append(defaults, jen.Lit(value))
+						}
+					}
 
-			if field.Validator != nil {
-				codes = append(codes,
-					jen.If(
-						jen.Id(varname+"_validator_errmsg").Op(":=").Id(*field.Validator).Call(jen.Id(varname)),
-						jen.Id(varname+"_validator_errmsg").Op("!=").Lit(""),
-					).Block(
-						jen.Return(
-							jen.Id("c").Dot("Status").Call(jen.Lit(400)).Dot("SendString").Call(jen.Id(varname+"_validator_errmsg")),
-						),
+					codes = append(codes, jen.If(jen.Len(jen.Id(varname)).Op("==").Lit(0)).Block(
+						jen.Id(varname).Op("=").Index().String().Values(defaults...),
 					))
-			} else if field.NotEmpty && field.DataType == "string" {
+				}
+			} else {
+				retrieve := []jen.Code{jen.Lit(field.FieldKey)}
+				if field.Default != nil {
+					retrieve = append(retrieve, jen.Lit(*field.Default))
+				}
+
+				codes = append(codes, jen.Id(varname).Op(":=").Id("c").Dot(field.FieldType.SimpleRetriever()).Call(retrieve...))
+			}
+
+			if field.NotEmpty && paramKindOf(field.GoType) == paramString {
 				codes = append(codes,
 					jen.If(
 						jen.Id(varname).Op("==").Lit(""),
 					).Block(
-						jen.Return(
-							jen.Id("c").Dot("Status").Call(jen.Lit(400)).Dot("SendString").Call(jen.Lit(field.Name+" must not be empty")),
-						),
+						addFieldError(path, source, "required", " must not be empty"),
 					))
 			}
 
-			if field.DataType == "uuid.UUID" {
-				codes = append(codes,
-					jen.List(jen.Id("q_"+field.Name), jen.Id("q_"+field.Name+"_err")).Op(":=").Qual("github.com/google/uuid", "Parse").Call(jen.Id(varname)),
-					jen.If(
-						jen.Id("q_"+field.Name+"_err").Op("!=").Nil(),
-					).Block(
-						jen.Return(
-							jen.Id("c").Dot("Status").Call(jen.Lit(400)).Dot("SendString").Call(jen.Id("q_"+field.Name+"_err").Dot("Error").Call()),
-						),
-					),
-				)
-			} else if field.DataType == "bool" {
-				codes = append(codes,
-					jen.List(jen.Id("q_"+field.Name), jen.Id("q_"+field.Name+"_err")).Op(":=").Qual("strconv", "ParseBool").Call(jen.Id(varname)),
-					jen.If(
-						jen.Id("q_"+field.Name+"_err").Op("!=").Nil(),
-					).Block(
-						jen.Return(
-							jen.Id("c").Dot("Status").Call(jen.Lit(400)).Dot("SendString").Call(jen.Id("q_"+field.Name+"_err").Dot("Error").Call()),
-						),
-					),
-				)
-			} else if field.DataType == "int" {
+			if field.Validator != nil {
 				codes = append(codes,
-					jen.List(jen.Id("q_"+field.Name), jen.Id("q_"+field.Name+"_err")).Op(":=").Qual("strconv", "Atoi").Call(jen.Id(varname)),
 					jen.If(
-						jen.Id("q_"+field.Name+"_err").Op("!=").Nil(),
+						jen.Id(varname+"_validator_errmsg").Op(":=").Id(*field.Validator).Call(jen.Id(varname)),
+						jen.Id(varname+"_validator_errmsg").Op("!=").Lit(""),
 					).Block(
-						jen.Return(
-							jen.Id("c").Dot("Status").Call(jen.Lit(400)).Dot("SendString").Call(jen.Id("q_"+field.Name+"_err").Dot("Error").Call()),
-						),
-					),
-				)
-			} else if strings.HasPrefix(field.DataType, "int") {
-				b, err := strconv.Atoi(strings.TrimPrefix(field.DataType, "int"))
-				if err != nil || (b != 8 && b != 16 && b != 32 && b != 64) {
-					panic(ErrInvalidNumberBits)
+						jen.Id("v_errs").Dot("Add").Call(path.code(""), jen.Lit(source), jen.Lit("invalid"), jen.Id(varname+"_validator_errmsg")),
+					))
+			}
+
+			if field.LoadFromDB != nil {
+				codes = append(codes, g.generateRules(jen.Id(varname), path, source, types.Typ[types.String], paramRules(field), &regexes, regexPrefix)...)
+
+				// records are only loaded once all fields are valid
+				loadCodes = append(loadCodes, g.generateDBLoad(field, []jen.Code{jen.Id(varname)}, returnErr)...)
+			} else if elem := field.SliceElem(); elem != nil {
+				codes = append(codes, g.generateSliceParam(field, elem, varname, path, source, paramRules(field), &regexes, regexPrefix)...)
+			} else if paramKindOf(field.GoType) == paramString {
+				// named string types are converted, plain strings are used as they are
+				if field.DataType != "string" {
+					codes = append(codes, jen.Id("q_"+field.Name).Op(":=").Add(typeCode(field.GoType)).Call(jen.Id(varname)))
 				}
-				codes = append(codes,
-					jen.List(jen.Id("q_"+field.Name), jen.Id("q_"+field.Name+"_err")).Op(":=").Qual("strconv", "ParseInt").Call(jen.Id(varname), jen.Lit(10), jen.Lit(b)),
-					jen.If(
-						jen.Id("q_"+field.Name+"_err").Op("!=").Nil(),
-					).Block(
-						jen.Return(
-							jen.Id("c").Dot("Status").Call(jen.Lit(400)).Dot("SendString").Call(jen.Id("q_"+field.Name+"_err").Dot("Error").Call()),
-						),
-					),
-				)
-			} else if strings.HasPrefix(field.DataType, "float") {
-				b, err := strconv.Atoi(strings.TrimPrefix(field.DataType, "float"))
-				if err != nil || (b != 32 && b != 64) {
-					panic(ErrInvalidNumberBits)
+
+				codes = append(codes, g.generateRules(jen.Id("q_"+field.Name), path, source, field.GoType, paramRules(field), &regexes, regexPrefix)...)
+			} else {
+				valueType, _ := field.ParamType()
+
+				codes = append(codes, jen.Var().Id("q_"+field.Name).Add(typeCode(field.GoType)))
+
+				// value is the parsed value, converted to the type of the field if needed
+				value := jen.Id(varname)
+				parse, convert := g.parseCall(valueType, field.Format, varname)
+				if parse != nil {
+					value = jen.Id("p_" + field.Name)
 				}
-				codes = append(codes,
-					jen.List(jen.Id("q_"+field.Name), jen.Id("q_"+field.Name+"_err")).Op(":=").Qual("strconv", "ParseFloat").Call(jen.Id(varname), jen.Lit(b)),
-					jen.If(
-						jen.Id("q_"+field.Name+"_err").Op("!=").Nil(),
-					).Block(
-						jen.Return(
-							jen.Id("c").Dot("Status").Call(jen.Lit(400)).Dot("SendString").Call(jen.Id("q_"+field.Name+"_err").Dot("Error").Call()),
-						),
-					),
-				)
-			} else if strings.HasPrefix(field.DataType, "uint") {
-				b, err := strconv.Atoi(strings.TrimPrefix(field.DataType, "uint"))
-				if err != nil || (b != 8 && b != 16 && b != 32 && b != 64) {
-					panic(ErrInvalidNumberBits)
+
+				if convert {
+					value = typeCode(valueType).Call(value)
 				}
-				codes = append(codes,
-					jen.List(jen.Id("q_"+field.Name), jen.Id("q_"+field.Name+"_err")).Op(":=").Qual("strconv", "ParseUint").Call(jen.Id(varname), jen.Lit(10), jen.Lit(b)),
-					jen.If(
-						jen.Id("q_"+field.Name+"_err").Op("!=").Nil(),
-					).Block(
-						jen.Return(
-							jen.Id("c").Dot("Status").Call(jen.Lit(400)).Dot("SendString").Call(jen.Id("q_"+field.Name+"_err").Dot("Error").Call()),
-						),
-					),
-				)
-			} else if field.LoadFromDB != nil {
-				ptr := "&"
-				if !strings.HasPrefix(field.DataType, "*") {
-					ptr = ""
+
+				assign := []jen.Code{}
+				checked := jen.Id("q_" + field.Name)
+				if field.Optional() {
+					if convert {
+						assign = append(assign, jen.Id("e_"+field.Name).Op(":=").Add(value))
+						value = jen.Id("e_" + field.Name)
+					}
+
+					assign = append(assign, jen.Id("q_"+field.Name).Op("=").Op("&").Add(value.Clone()))
+					checked = value
+				} else {
+					assign = append(assign, jen.Id("q_"+field.Name).Op("=").Add(value))
 				}
-				codes = append(codes,
-					jen.Id("q_"+field.Name).Op(":=").Op(ptr).Id(field.DataType).Values(),
-					jen.Id("q_"+field.Name+"_err").Op(":=").Qual(g.getDbPkg(), "DB").Dot("Where").Call(jen.Lit(*field.LoadFromDB+"=?"), jen.Id("raw_"+field.Name)).Dot("First").Call(jen.Op("&").Id("q_"+field.Name)).Dot("Error"),
-					jen.If(
-						jen.Id("q_"+field.Name+"_err").Op("==").Qual("gorm.io/gorm", "ErrRecordNotFound"),
-					).Block(
-						jen.Return(
-							jen.Id("c").Dot("Status").Call(jen.Lit(404)).Dot("SendString").Call(jen.Lit(field.Name+" not found")),
-						),
-					),
-					jen.If(
-						jen.Id("q_"+field.Name+"_err").Op("!=").Nil(),
+
+				// the rules are only checked for values which could be parsed
+				assign = append(assign, g.generateRules(checked, path, source, valueType, paramRules(field), &regexes, regexPrefix)...)
+
+				present := assign
+				if parse != nil {
+					present = []jen.Code{
+						jen.If(
+							jen.List(jen.Id("p_"+field.Name), jen.Id("p_"+field.Name+"_err")).Op(":=").Add(parse),
+							jen.Id("p_"+field.Name+"_err").Op("!=").Nil(),
+						).Block(
+							addFieldError(path, source, "invalid", " must be "+parseDescription(valueType, field.Format)),
+						).Else().Block(assign...),
+					}
+				}
+
+				switch {
+				case field.Default != nil:
+					// the retriever falls back to the default value, so the parameter is never empty
+					codes = append(codes, present...)
+				case !field.Optional() || field.NotEmpty:
+					absent := jen.If(
+						jen.Id(varname).Op("==").Lit(""),
 					).Block(
-						jen.Return(
-							jen.Id("q_"+field.Name+"_err"),
-						),
-					),
-				)
+						addFieldError(path, source, "required", " is required"),
+					).Else()
+
+					if parse != nil {
+						absent.Add(present...)
+					} else {
+						absent.Block(present...)
+					}
+
+					codes = append(codes, absent)
+				default:
+					// optional fields stay nil if the parameter is absent
+					codes = append(codes,
+						jen.If(
+							jen.Id(varname).Op("!=").Lit(""),
+						).Block(present...))
+				}
 			}
 		} else {
-			ptr := "&"
-			if !strings.HasPrefix(field.DataType, "*") {
-				ptr = ""
-			}
-			codes = append(codes,
-				jen.Id("q_"+field.Name).Op(":=").Op(ptr).Id(field.DataType).Values(),
-				jen.If(
-					jen.Id("q_"+field.Name+"_err").Op(":=").Id("c").Dot("BodyParser").Call(jen.Op("&").Id("q_"+field.Name)),
-					jen.Id("q_"+field.Name+"_err").Op("!=").Nil(),
-				).Block(
-					jen.Return(
-						jen.Id("c").Dot("Status").Call(jen.Lit(400)).Dot("SendString").Call(jen.Id("q_"+field.Name+"_err").Dot("Error").Call()),
-					),
-				),
+			// errors of body fields are reported by their path in the body
+			path = fieldPath{}
+
+			codes = append(codes, zeroValue("q_"+field.Name, field.GoType))
+
+			check := jen.If(
+				jen.Id("q_"+field.Name+"_err").Op(":=").Id("c").Dot("BodyParser").Call(jen.Op("&").Id("q_"+field.Name)),
+				jen.Id("q_"+field.Name+"_err").Op("!=").Nil(),
+			).Block(
+				jen.Id("v_errs").Dot("Add").Call(jen.Lit(""), jen.Lit(source), jen.Lit("invalid"), jen.Lit("body could not be parsed: ").Op("+").Id("q_"+field.Name+"_err").Dot("Error").Call()),
 			)
+
+			rules := g.generateRules(jen.Id("q_"+field.Name), path, source, field.GoType, field.Rules, &regexes, regexPrefix)
+			rules = append(rules, g.generateNestedRules(jen.Id("q_"+field.Name), path, source, field.GoType, 0, &regexes, regexPrefix, map[string]bool{})...)
+			if len(rules) > 0 {
+				check = check.Else().Block(rules...)
+			}
+
+			codes = append(codes, check)
 		}
 
 		mainCodes = append(mainCodes, codes...)
 	}
 
+	if validates {
+		mainCodes = append(mainCodes,
+			jen.If(
+				jen.Len(jen.Id("v_errs")).Op(">").Lit(0),
+			).Block(
+				jen.Return(jen.Qual(exoPkgPath, "SendValidationErrors").Call(jen.Id("c"), jen.Id("v_errs"))),
+			))
+	}
+
+	mainCodes = append(mainCodes, loadCodes...)
+
 	mainCodes = append(mainCodes, jen.Id("req").Op(":=").Id(req.StructName).Values(
 		jen.DictFunc(func(d jen.Dict) {
-			d[jen.Id(string(req.Method))] = jen.Qual("github.com/exo-framework/exo", string(req.Method)).Values(
-				jen.Id("Ctx").Op(":").Id("c"),
+			d[jen.Id(string(req.Method))] = jen.Qual(exoPkgPath, string(req.Method)).Values(
+				jen.Id("Request").Op(":").Qual(exoPkgPath, "Request").Values(jen.Id("Ctx").Op(":").Id("c")),
 			)
 
 			for _, field := range req.Fields {
@@ -254,17 +448,44 @@ func (g *Generator) generateHandler(req Request) jen.Code {
 		}),
 	))
 
+	handlerCall := jen.Id(req.Handler.Name)
+	if req.Handler.Receiver != "" {
+		handlerCall = jen.Id("ctrl").Dot(req.Handler.Name)
+	}
+
 	finish := func() jen.Code {
-		return jen.Func().Id("exog_" + req.Handler.Name).Params(
+		handler := jen.Func().Id("exog_" + req.Handler.Name).Params(
 			jen.Id("c").Op("*").Qual("github.com/gofiber/fiber/v2", "Ctx"),
 		).Error().Block(
 			mainCodes...,
 		)
+
+		// methods are bound to the controller when the routes are registered
+		if req.Handler.Receiver != "" {
+			ctrl := jen.Id("ctrl")
+			if req.Handler.PointerReceiver {
+				ctrl = ctrl.Op("*")
+			}
+
+			handler = jen.Func().Id("exog_"+req.Handler.Name).Params(ctrl.Id(req.Handler.Receiver)).Qual("github.com/gofiber/fiber/v2", "Handler").Block(
+				jen.Return(jen.Func().Params(
+					jen.Id("c").Op("*").Qual("github.com/gofiber/fiber/v2", "Ctx"),
+				).Error().Block(
+					mainCodes...,
+				)),
+			)
+		}
+
+		if len(regexes) == 0 {
+			return handler
+		}
+
+		return jen.Var().Defs(regexes...).Line().Line().Add(handler)
 	}
 
 	if len(req.Handler.Returns) == 0 {
+		mainCodes = append(mainCodes, handlerCall.Clone().Call(jen.Id("req")))
 		mainCodes = append(mainCodes,
-			jen.Id(req.Handler.Name).Call(jen.Id("req")),
 			jen.Return(
 				jen.Id("c").Dot("SendStatus").Call(jen.Lit(204)),
 			))
@@ -272,115 +493,254 @@ func (g *Generator) generateHandler(req Request) jen.Code {
 		return finish()
 	}
 
-	returns := map[string]string{} // type -> name
-	hadContentRet := false
+	// only the first value of every kind is sent, the others are ignored
+	returns := map[ReturnKind]int{} // kind -> index
+	bodyIndex, bodyKind, _ := req.Handler.Body()
 
-	mainCodes = append(mainCodes,
-		jen.ListFunc(func(l *jen.Group) {
-			for i, ret := range req.Handler.Returns {
-				if ret == "string" || ret == "[]byte" || ret == "interface{}" || ret == "any" {
-					if hadContentRet {
-						l.Id("_")
-						continue
-					}
+	results := jen.ListFunc(func(l *jen.Group) {
+		for i, kind := range req.Handler.ReturnKinds {
+			if _, ok := returns[kind]; ok || (kind.Content() && i != bodyIndex) {
+				l.Id("_")
+				continue
+			}
 
-					hadContentRet = true
-				}
+			returns[kind] = i
+			l.Id("r_" + strconv.Itoa(i))
+		}
+	})
 
-				rname := "r_" + strconv.Itoa(i)
-				returns[ret] = rname
+	mainCodes = append(mainCodes, results.Op(":=").Add(handlerCall).Call(jen.Id("req")))
 
-				l.Id(rname)
-			}
-		}).Op(":=").Id(req.Handler.Name).Call(jen.Id("req")))
+	result := func(kind ReturnKind) (*jen.Statement, bool) {
+		i, ok := returns[kind]
+		return jen.Id("r_" + strconv.Itoa(i)), ok
+	}
 
-	if rErrName, ok := returns["error"]; ok {
-		mainCodes = append(mainCodes,
-			jen.If(jen.Id(rErrName).Op("!=").Nil()).Block(
-				jen.Return(jen.Id(rErrName)),
-			))
+	if rErr, ok := result(ReturnError); ok {
+		mainCodes = append(mainCodes, returnErr(rErr))
+	}
+
+	if rResponse, ok := result(ReturnResponse); ok {
+		mainCodes = append(mainCodes, jen.Return(jen.Qual(exoPkgPath, "SendResponse").Call(jen.Id("c"), rResponse)))
 
-		delete(returns, "error") // handled
+		return finish()
 	}
 
-	extractStatus := func() (string, bool) {
-		n := ""
-		t2 := ""
-		b := false
+	// cookies are set before the status and the content are sent
+	if rCookies, ok := result(ReturnCookies); ok {
+		mainCodes = append(mainCodes,
+			jen.Qual(exoPkgPath, "SetCookies").Call(jen.Id("c"), rCookies),
+		)
+	}
 
-		for t, name := range returns {
-			if strings.HasPrefix(t, "int") {
-				t2 = t
-				n = name
-				b = true
-				break
-			}
+	if rStatus, ok := result(ReturnStatus); ok {
+		// fiber expects the status code as int
+		if req.Handler.Returns[returns[ReturnStatus]] != "int" {
+			rStatus = jen.Int().Call(rStatus)
 		}
 
-		if b {
-			delete(returns, t2)
+		if bodyIndex < 0 {
+			mainCodes = append(mainCodes, jen.Return(jen.Id("c").Dot("SendStatus").Call(rStatus)))
+
+			return finish()
 		}
 
-		return n, b
+		mainCodes = append(mainCodes, jen.Id("c").Dot("Status").Call(rStatus))
 	}
 
-	hasContent := func() bool {
-		for t := range returns {
-			if t == "interface{}" || t == "any" || t == "string" || t == "[]byte" {
-				return true
-			}
+	rBody, _ := result(bodyKind)
+	switch bodyKind {
+	case ReturnJSON:
+		mainCodes = append(mainCodes, jen.Return(jen.Id("c").Dot("JSON").Call(rBody)))
+	case ReturnString:
+		mainCodes = append(mainCodes, jen.Return(jen.Id("c").Dot("SendString").Call(rBody)))
+	case ReturnBytes:
+		mainCodes = append(mainCodes, jen.Return(jen.Id("c").Dot("Send").Call(rBody)))
+	case ReturnSerialize:
+		mainCodes = append(mainCodes, jen.Return(jen.Qual(exoPkgPath, "SendSerialize").Call(jen.Id("c"), rBody)))
+	default:
+		mainCodes = append(mainCodes, jen.Return(jen.Id("c").Dot("SendStatus").Call(jen.Lit(204))))
+	}
+
+	return finish()
+}
+
+func (g *Generator) generateGuards(req Request) []jen.Code {
+	if len(req.Roles) == 0 && len(req.Scopes) == 0 {
+		return []jen.Code{}
+	}
+
+	codes := []jen.Code{
+		jen.List(jen.Id("guard_claims"), jen.Id("guard_err")).Op(":=").Qual(exoPkgPath, "RequireClaims").Call(jen.Id("c")),
+		jen.If(
+			jen.Id("guard_err").Op("!=").Nil(),
+		).Block(
+			jen.Return(jen.Id("guard_err")),
+		),
+	}
+
+	lits := func(values []string) []jen.Code {
+		codes := []jen.Code{}
+		for _, v := range values {
+			codes = append(codes, jen.Lit(v))
 		}
+		return codes
+	}
 
-		return false
+	if len(req.Roles) > 0 {
+		codes = append(codes,
+			jen.If(
+				jen.Op("!").Id("guard_claims").Dot("HasRole").Call(lits(req.Roles)...),
+			).Block(
+				jen.Return(
+					jen.Qual(exoPkgPath, "Forbidden").Call(jen.Lit("missing role")),
+				),
+			))
 	}
 
-	rStatusName, hasStatus := extractStatus()
-	if hasStatus {
-		if hasContent() {
-			mainCodes = append(mainCodes,
-				jen.Id("c").Dot("Status").Call(jen.Id(rStatusName)),
-			)
-		} else {
-			mainCodes = append(mainCodes,
-				jen.Return(jen.Id("c").Dot("SendStatus").Call(jen.Id(rStatusName))),
-			)
+	if len(req.Scopes) > 0 {
+		codes = append(codes,
+			jen.If(
+				jen.Op("!").Id("guard_claims").Dot("HasScopes").Call(lits(req.Scopes)...),
+			).Block(
+				jen.Return(
+					jen.Qual(exoPkgPath, "Forbidden").Call(jen.Lit("missing scope")),
+				),
+			))
+	}
+
+	return codes
+}
+
+func (g *Generator) generateAuthField(field Field) []jen.Code {
+	// optional claims are nil for anonymous requests and requests with an invalid token
+	codes := []jen.Code{
+		jen.Id("raw_"+field.Name).Op(":=").Qual(exoPkgPath, "GetClaims").Call(jen.Id("c")),
+	}
+
+	if !field.AuthOptional {
+		codes = []jen.Code{
+			jen.List(jen.Id("raw_"+field.Name), jen.Id("raw_"+field.Name+"_err")).Op(":=").Qual(exoPkgPath, "RequireClaims").Call(jen.Id("c")),
+			jen.If(
+				jen.Id("raw_" + field.Name + "_err").Op("!=").Nil(),
+			).Block(
+				jen.Return(jen.Id("raw_" + field.Name + "_err")),
+			),
 		}
 	}
 
-	hasReturned := false
+	switch field.DataType {
+	case "*exo.Claims":
+		return append(codes, jen.Id("q_"+field.Name).Op(":=").Id("raw_"+field.Name))
+	case "exo.Claims":
+		if !field.AuthOptional {
+			return append(codes, jen.Id("q_"+field.Name).Op(":=").Op("*").Id("raw_"+field.Name))
+		}
 
-	for t, name := range returns {
-		if t == "interface{}" || t == "any" {
-			mainCodes = append(mainCodes,
-				jen.Return(jen.Id("c").Dot("JSON").Call(jen.Id(name))),
-			)
+		return append(codes,
+			jen.Id("q_"+field.Name).Op(":=").Qual(exoPkgPath, "Claims").Values(),
+			jen.If(
+				jen.Id("raw_"+field.Name).Op("!=").Nil(),
+			).Block(
+				jen.Id("q_"+field.Name).Op("=").Op("*").Id("raw_"+field.Name),
+			))
+	}
 
-			hasReturned = true
-			break
-		} else if t == "string" {
-			mainCodes = append(mainCodes,
-				jen.Return(jen.Id("c").Dot("SendString").Call(jen.Id(name))),
-			)
+	// custom claims types are decoded from the verified token claims
+	target := jen.Op("&").Id("q_" + field.Name)
+	decl := zeroValue("q_"+field.Name, field.GoType)
+	init := []jen.Code{}
+	if ptr, ok := field.GoType.(*types.Pointer); ok {
+		target = jen.Id("q_" + field.Name)
+		decl = jen.Var().Id("q_" + field.Name).Add(typeCode(ptr))
+		init = append(init, jen.Id("q_"+field.Name).Op("=").Add(newValue(ptr.Elem())))
+	}
 
-			hasReturned = true
-			break
-		} else if t == "[]byte" {
-			mainCodes = append(mainCodes,
-				jen.Return(jen.Id("c").Dot("Send").Call(jen.Id(name))),
-			)
+	decode := append(init,
+		jen.If(
+			jen.Id("q_"+field.Name+"_err").Op(":=").Id("raw_"+field.Name).Dot("Decode").Call(target),
+			jen.Id("q_"+field.Name+"_err").Op("!=").Nil(),
+		).Block(
+			jen.Return(
+				jen.Qual(exoPkgPath, "Unauthorized").Call(jen.Lit("invalid claims")).Dot("Wrap").Call(jen.Id("q_"+field.Name+"_err")),
+			),
+		),
+	)
+
+	if !field.AuthOptional {
+		return append(append(codes, decl), decode...)
+	}
+
+	return append(codes,
+		decl,
+		jen.If(
+			jen.Id("raw_"+field.Name).Op("!=").Nil(),
+		).Block(decode...))
+}
 
-			hasReturned = true
-			break
+// generateSliceParam generates the parsing of the values of a slice parameter, which are retrieved into the []string raw.
+// The values are parsed and checked element by element, errors refer to the index of the value like ids[2].
+func (g *Generator) generateSliceParam(field Field, elem types.Type, raw string, path fieldPath, source string, rules []ValidationRule, regexes *[]jen.Code, prefix string) []jen.Code {
+	own, elemRules := splitDive(rules)
+	index, value, parsed := "i_"+field.Name, "s_"+field.Name, "e_"+field.Name
+	elemPath := path.index(index)
+
+	converted := jen.Id(value)
+	parse, convert := g.parseCall(elem, field.Format, value)
+	if parse != nil {
+		converted = jen.Id("p_" + field.Name)
+	}
+
+	if convert {
+		converted = typeCode(elem).Call(converted)
+	}
+
+	checks := g.generateRules(jen.Id(parsed), elemPath, source, elem, elemRules, regexes, prefix)
+
+	add := []jen.Code{jen.Id("q_"+field.Name).Op("=").Append(jen.Id("q_"+field.Name), converted)}
+	if len(checks) > 0 {
+		add = append([]jen.Code{jen.Id(parsed).Op(":=").Add(converted)}, jen.Id("q_"+field.Name).Op("=").Append(jen.Id("q_"+field.Name), jen.Id(parsed)))
+		add = append(add, checks...)
+	}
+
+	if parse != nil {
+		add = []jen.Code{
+			jen.If(
+				jen.List(jen.Id("p_"+field.Name), jen.Id("p_"+field.Name+"_err")).Op(":=").Add(parse),
+				jen.Id("p_"+field.Name+"_err").Op("!=").Nil(),
+			).Block(
+				addFieldError(elemPath, source, "invalid", " must be "+parseDescription(elem, field.Format)),
+			).Else().Block(add...),
 		}
+	} else if len(checks) == 0 {
+		// the index is only needed for the errors of the values
+		index = "_"
 	}
 
-	if !hasReturned {
-		mainCodes = append(mainCodes,
-			jen.Return(jen.Id("c").Dot("SendStatus").Call(jen.Lit(204))),
-		)
+	return append([]jen.Code{
+		jen.Var().Id("q_" + field.Name).Add(typeCode(field.GoType)),
+		jen.For(jen.List(jen.Id(index), jen.Id(value)).Op(":=").Range().Id(raw)).Block(add...),
+	}, g.generateRules(jen.Id("q_"+field.Name), path, source, field.GoType, own, regexes, prefix)...)
+}
+
+// durationCode returns a duration in the largest unit it is a multiple of, e.g. 5 * time.Second.
+func durationCode(d time.Duration) jen.Code {
+	for _, unit := range []struct {
+		name string
+		size time.Duration
+	}{
+		{"Hour", time.Hour},
+		{"Minute", time.Minute},
+		{"Second", time.Second},
+		{"Millisecond", time.Millisecond},
+		{"Microsecond", time.Microsecond},
+	} {
+		if d%unit.size == 0 {
+			return jen.Lit(int(d/unit.size)).Op("*").Qual("time", unit.name)
+		}
 	}
 
-	return finish()
+	return jen.Qual("time", "Duration").Call(jen.Lit(int(d)))
 }
 
 func (g *Generator) getDbPkg() string {
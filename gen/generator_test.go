@@ -0,0 +1,580 @@
+package gen
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// generateSources generates the glue code of packages consisting of the given requests files, builds the packages and returns the
+// generated code of each.
+func generateSources(t *testing.T, srcs ...string) []string {
+	t.Helper()
+
+	return generateFile(t, "requests_gen.go", srcs...)
+}
+
+// generateFile is like generateSources, but returns the generated file with the given name, e.g. index_gen.go.
+func generateFile(t *testing.T, name string, srcs ...string) []string {
+	t.Helper()
+
+	gens, dirs, errs := analyzeSources(t, srcs...)
+
+	codes := make([]string, len(srcs))
+	for i, g := range gens {
+		if errs[i] != nil {
+			t.Fatalf("package %d: %v", i, errs[i])
+		}
+
+		if err := g.Generate(); err != nil {
+			t.Fatal(err)
+		}
+
+		code, err := os.ReadFile(filepath.Join(dirs[i], name))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		codes[i] = string(code)
+	}
+
+	args := []string{"build"}
+	for _, dir := range dirs {
+		args = append(args, "./"+filepath.ToSlash(dir))
+	}
+
+	if out, err := exec.Command("go", args...).CombinedOutput(); err != nil {
+		t.Fatalf("generated code does not build: %v\n%s\n%v", err, out, codes)
+	}
+
+	return codes
+}
+
+// containsAll reports the snippets which are missing in the generated code.
+func containsAll(t *testing.T, code string, snippets ...string) {
+	t.Helper()
+
+	for _, snippet := range snippets {
+		if !strings.Contains(code, snippet) {
+			t.Errorf("expected %q in\n%s", snippet, code)
+		}
+	}
+}
+
+func TestGenerateAuthFields(t *testing.T) {
+	code := generateSources(t, `package p
+
+import "github.com/exo-framework/exo"
+
+type TenantClaims struct {
+	Subject string `+"`json:\"sub\"`"+`
+	Tenant  string `+"`json:\"tenant\"`"+`
+}
+
+type Get struct {
+	exo.Get        `+"`route:\"/\"`"+`
+	Claims         exo.Claims    `+"`auth:\"\"`"+`
+	ClaimsPtr      *exo.Claims   `+"`auth:\"\"`"+`
+	Optional       exo.Claims    `+"`auth:\"optional\"`"+`
+	OptionalPtr    *exo.Claims   `+"`auth:\"optional\"`"+`
+	Tenant         TenantClaims  `+"`auth:\"\"`"+`
+	OptionalTenant *TenantClaims `+"`auth:\"optional\"`"+`
+}
+
+func get(Get) error {
+	return nil
+}
+`)[0]
+
+	tests := []struct {
+		name     string
+		snippets []string
+	}{
+		{"required claims", []string{"raw_Claims, raw_Claims_err := exo.RequireClaims(c)", "return raw_Claims_err", "q_Claims := *raw_Claims"}},
+		{"required pointer", []string{"raw_ClaimsPtr, raw_ClaimsPtr_err := exo.RequireClaims(c)", "q_ClaimsPtr := raw_ClaimsPtr"}},
+		{"optional claims", []string{"raw_Optional := exo.GetClaims(c)", "q_Optional := exo.Claims{}", "if raw_Optional != nil {\n\t\tq_Optional = *raw_Optional"}},
+		{"optional pointer", []string{"raw_OptionalPtr := exo.GetClaims(c)", "q_OptionalPtr := raw_OptionalPtr"}},
+		{"custom claims", []string{"raw_Tenant.Decode(&q_Tenant)", `exo.Unauthorized("invalid claims").Wrap(q_Tenant_err)`}},
+		{"optional custom pointer", []string{"raw_OptionalTenant := exo.GetClaims(c)", "if raw_OptionalTenant != nil {", "q_OptionalTenant = &TenantClaims{}", "raw_OptionalTenant.Decode(q_OptionalTenant)"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			containsAll(t, code, tt.snippets...)
+		})
+	}
+}
+
+func TestGenerateGuards(t *testing.T) {
+	codes := generateSources(t, `package p
+
+import "github.com/exo-framework/exo"
+
+type Delete struct {
+	exo.Delete `+"`route:\"/:id\" roles:\"admin, editor\" scopes:\"items:write,items:delete\"`"+`
+	Id         int `+"`path:\"id\"`"+`
+}
+
+func del(Delete) error {
+	return nil
+}
+`, `package p
+
+import "github.com/exo-framework/exo"
+
+type Get struct {
+	exo.Get `+"`route:\"/\" roles:\"admin\"`"+`
+}
+
+func get(Get) error {
+	return nil
+}
+`, `package p
+
+import "github.com/exo-framework/exo"
+
+type Get struct {
+	exo.Get `+"`route:\"/\"`"+`
+}
+
+func get(Get) error {
+	return nil
+}
+`)
+
+	tests := []struct {
+		name     string
+		code     string
+		snippets []string
+		missing  []string
+	}{
+		{"roles and scopes", codes[0], []string{`guard_claims.HasRole("admin", "editor")`, `guard_claims.HasScopes("items:write", "items:delete")`, `exo.Forbidden("missing role")`, `exo.Forbidden("missing scope")`}, nil},
+		{"roles only", codes[1], []string{"guard_claims, guard_err := exo.RequireClaims(c)", `guard_claims.HasRole("admin")`}, []string{"HasScopes"}},
+		{"no guards", codes[2], nil, []string{"guard_claims"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			containsAll(t, tt.code, tt.snippets...)
+
+			for _, snippet := range tt.missing {
+				if strings.Contains(tt.code, snippet) {
+					t.Errorf("unexpected %q in\n%s", snippet, tt.code)
+				}
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	gens, dirs, errs := analyzeSources(t, `package p
+
+import "github.com/exo-framework/exo"
+
+type Get struct {
+	exo.Get `+"`route:\"/\"`"+`
+}
+
+func get(Get) error {
+	return nil
+}
+`)
+	if errs[0] != nil {
+		t.Fatal(errs[0])
+	}
+
+	g, dir := gens[0], dirs[0]
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	write := func(name, content string) func() {
+		return func() {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// the steps change the generated files one after the other
+	tests := []struct {
+		name     string
+		change   func()
+		outdated []string
+	}{
+		{"up to date", func() {}, []string{}},
+		{"edited file", write("requests_gen.go", generatedHeader+"\npackage p\n"), []string{"requests_gen.go"}},
+		{"regenerated", func() {
+			if err := g.Generate(); err != nil {
+				t.Fatal(err)
+			}
+		}, []string{}},
+		{"stale client", write("client_gen.go", generatedHeader+"\npackage p\n"), []string{"client_gen.go"}},
+		{"current client", func() {
+			if err := g.GenerateGoClient(); err != nil {
+				t.Fatal(err)
+			}
+		}, []string{}},
+		{"orphaned file", write("old_gen.go", generatedHeader+"\npackage p\n"), []string{"old_gen.go"}},
+		{"file of another generator", func() {
+			write("old_gen.go", "// Code generated by github.com/tinylib/msgp DO NOT EDIT.\npackage p\n")()
+		}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.change()
+
+			// Analyze collects the generated files found on disk
+			g.existing = nil
+			if err := g.collectDirs(dir, &[]string{}); err != nil {
+				t.Fatal(err)
+			}
+
+			outdated, err := g.Check()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			names := []string{}
+			for _, file := range outdated {
+				names = append(names, filepath.Base(file))
+			}
+
+			if !reflect.DeepEqual(names, tt.outdated) {
+				t.Errorf("expected %v, got %v", tt.outdated, names)
+			}
+		})
+	}
+}
+
+func TestGenerateTypes(t *testing.T) {
+	code := generateSources(t, `package p
+
+import (
+	"time"
+
+	web "github.com/exo-framework/exo"
+	"github.com/google/uuid"
+)
+
+type Status string
+
+type Level int8
+
+type Req struct {
+	web.Get `+"`route:\"/items/:id\"`"+`
+	Id      uuid.UUID     `+"`path:\"id\"`"+`
+	Status  Status        `+"`query:\"status\"`"+`
+	Level   *Level        `+"`query:\"level\"`"+`
+	Wait    time.Duration `+"`query:\"wait\"`"+`
+	Ids     []uint16      `+"`query:\"ids\"`"+`
+}
+
+func handle(Req) (map[string][]int, error) {
+	return nil, nil
+}
+`)[0]
+
+	containsAll(t, code,
+		`Get:    exo.Get{Request: exo.Request{Ctx: c}},`,
+		`uuid.Parse(raw_Id)`,
+		`q_Status := Status(raw_Status)`,
+		`strconv.ParseInt(raw_Level, 10, 8)`,
+		`e_Level := Level(p_Level)`,
+		`time.ParseDuration(raw_Wait)`,
+		`strconv.ParseUint(s_Ids, 10, 16)`,
+		`return c.JSON(r_0)`,
+	)
+}
+
+func TestGenerateDefaults(t *testing.T) {
+	code := generateSources(t, `package p
+
+import (
+	"time"
+
+	"github.com/exo-framework/exo"
+	"github.com/google/uuid"
+)
+
+type Req struct {
+	exo.Get `+"`route:\"/\"`"+`
+	Limit   int        `+"`query:\"limit\" default:\"20\"`"+`
+	Ratio   float32    `+"`query:\"ratio\" default:\"0.5\"`"+`
+	Sort    string     `+"`query:\"sort\" default:\"name\"`"+`
+	Dark    bool       `+"`cookie:\"dark\" default:\"true\"`"+`
+	Since   time.Time  `+"`query:\"since\" format:\"date\" default:\"2024-01-01\"`"+`
+	Page    *int       `+"`query:\"page\"`"+`
+	Active  *bool      `+"`query:\"active\"`"+`
+	Ref     *uuid.UUID `+"`header:\"X-Ref\"`"+`
+	Count   uint       `+"`query:\"count\"`"+`
+}
+
+func handle(Req) error {
+	return nil
+}
+`)[0]
+
+	tests := []struct {
+		name     string
+		snippets []string
+	}{
+		{"int default", []string{`raw_Limit := c.Query("limit", "20")`, `strconv.Atoi(raw_Limit)`}},
+		{"float default", []string{`raw_Ratio := c.Query("ratio", "0.5")`, `strconv.ParseFloat(raw_Ratio, 32)`}},
+		{"string default", []string{`q_Sort := c.Query("sort", "name")`}},
+		{"cookie default", []string{`raw_Dark := c.Cookies("dark", "true")`}},
+		{"time default", []string{`raw_Since := c.Query("since", "2024-01-01")`}},
+		{"optional int", []string{"var q_Page *int\n\tif raw_Page != \"\" {", `q_Page = &p_Page`}},
+		{"optional bool", []string{"var q_Active *bool\n\tif raw_Active != \"\" {", `q_Active = &p_Active`}},
+		{"optional uuid", []string{"var q_Ref *uuid.UUID\n\tif raw_Ref != \"\" {", `q_Ref = &p_Ref`}},
+		{"required without default", []string{`v_errs.Add("count", "query", "required", "count is required")`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			containsAll(t, code, tt.snippets...)
+		})
+	}
+
+	// only parameters without a default or pointer are required
+	if n := strings.Count(code, `"required"`); n != 1 {
+		t.Errorf("expected 1 required parameter, got %d", n)
+	}
+}
+
+func TestGenerateCookies(t *testing.T) {
+	code := generateSources(t, `package p
+
+import "github.com/exo-framework/exo"
+
+type Req struct {
+	exo.Post `+"`route:\"/\"`"+`
+	Session  string `+"`cookie:\"session\" validate:\"notempty\"`"+`
+	Theme    string `+"`cookie:\"theme\" default:\"light\"`"+`
+	Visits   *int   `+"`cookie:\"visits\"`"+`
+}
+
+func handle(Req) (exo.Cookies, string, int, error) {
+	return nil, "", 0, nil
+}
+`)[0]
+
+	containsAll(t, code,
+		`q_Session := c.Cookies("session")`,
+		`v_errs.Add("session", "cookie", "required", "session must not be empty")`,
+		`q_Theme := c.Cookies("theme", "light")`,
+		`strconv.Atoi(raw_Visits)`,
+		// cookies are set after the error check and before the body is sent
+		"if r_3 != nil {\n\t\treturn r_3\n\t}\n\texo.SetCookies(c, r_0)\n\tc.Status(r_2)\n\treturn c.SendString(r_1)",
+	)
+}
+
+func TestGenerateResponses(t *testing.T) {
+	handler := func(name, returns, value string) string {
+		return `
+type ` + name + ` struct {
+	exo.Get ` + "`route:\"/" + name + "\"`" + `
+}
+
+func handle` + name + `(` + name + `) (` + returns + `) {
+	return ` + value + `
+}
+`
+	}
+
+	code := generateSources(t, `package p
+
+import "github.com/exo-framework/exo"
+
+type Item struct {
+	Name string `+"`json:\"name\"`"+`
+}
+`+handler("Value", "Item, error", "Item{}, nil")+
+		handler("Pointer", "*Item, int, error", "nil, 0, nil")+
+		handler("Map", "map[string]Item", "nil")+
+		handler("Any", "any, error", "nil, nil")+
+		handler("Bytes", "[]byte, error", "nil, nil")+
+		handler("Full", "exo.Response[[]Item], error", "exo.Response[[]Item]{}, nil")+
+		handler("Serialized", "exo.Serialize[Item], error", "exo.Serialize[Item]{}, nil")+
+		handler("Status", "int", "204"))[0]
+
+	tests := []struct {
+		name    string
+		snippet string
+	}{
+		{"struct", "r_0, r_1 := handleValue(req)\n\tif r_1 != nil {\n\t\treturn r_1\n\t}\n\treturn c.JSON(r_0)"},
+		{"pointer and status", "\tc.Status(r_1)\n\treturn c.JSON(r_0)"},
+		{"map without error", "r_0 := handleMap(req)\n\treturn c.JSON(r_0)"},
+		{"any", "r_0, r_1 := handleAny(req)\n\tif r_1 != nil {\n\t\treturn r_1\n\t}\n\treturn c.JSON(r_0)"},
+		{"bytes", "return c.Send(r_0)"},
+		{"response", "return exo.SendResponse(c, r_0)"},
+		{"serialized value", "return exo.SendSerialize(c, r_0)"},
+		{"status only", "r_0 := handleStatus(req)\n\treturn c.SendStatus(r_0)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			containsAll(t, code, tt.snippet)
+		})
+	}
+}
+
+func TestGenerateGroups(t *testing.T) {
+	route := func(decl string) string {
+		return `package p
+
+import (
+	"github.com/exo-framework/exo"
+	"github.com/gofiber/fiber/v2"
+)
+
+func audit(c *fiber.Ctx) error {
+	return c.Next()
+}
+
+var _ = audit
+
+func limit(c *fiber.Ctx) error {
+	return c.Next()
+}
+
+` + decl + `
+
+type Req struct {
+	exo.Get ` + "`route:\"/:id\" use:\"limit\"`" + `
+	Id int ` + "`path:\"id\"`" + `
+}
+
+func handle(Req) error {
+	return nil
+}
+`
+	}
+
+	tests := []struct {
+		name     string
+		decl     string
+		register string
+	}{
+		{"no group", "", `r.Get("/:id", limit, exog_handle)`},
+		// the middleware of the group runs before the middleware of the route
+		{"prefix and middleware", `var Routes = exo.Group{Prefix: "/orders", Middleware: []fiber.Handler{audit}}`, `r.Get("/orders/:id", Routes.Handlers(limit, exog_handle)...)`},
+		{"middleware only", `var Routes = exo.Group{Middleware: []fiber.Handler{audit}}`, `r.Get("/:id", Routes.Handlers(limit, exog_handle)...)`},
+	}
+
+	srcs := make([]string, len(tests))
+	for i, tt := range tests {
+		srcs[i] = route(tt.decl)
+	}
+
+	codes := generateFile(t, "index_gen.go", srcs...)
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			containsAll(t, codes[i], tt.register)
+		})
+	}
+}
+
+func TestGenerateControllers(t *testing.T) {
+	src := func(handlers string) string {
+		return `package p
+
+import "github.com/exo-framework/exo"
+
+type Ctl struct{}
+
+type A struct {
+	exo.Get ` + "`route:\"/a\"`" + `
+}
+
+type B struct {
+	exo.Get ` + "`route:\"/b\"`" + `
+}
+
+` + handlers + `
+`
+	}
+
+	tests := []struct {
+		name     string
+		handlers string
+		snippets []string
+	}{
+		{"functions", "func a(A) error {\n\treturn nil\n}\n\nfunc b(B) error {\n\treturn nil\n}", []string{
+			"func RegisterRoutes(r v2.Router) {",
+			`r.Get("/a", exog_a)`,
+		}},
+		{"value receivers", "func (Ctl) a(A) error {\n\treturn nil\n}\n\nfunc (Ctl) b(B) error {\n\treturn nil\n}", []string{
+			"func RegisterRoutes(r v2.Router, ctrl Ctl) {",
+			`r.Get("/a", exog_a(ctrl))`,
+		}},
+		// a pointer is passed if any handler has a pointer receiver, the others are called on a copy
+		{"pointer receiver", "func (*Ctl) a(A) error {\n\treturn nil\n}\n\nfunc (Ctl) b(B) error {\n\treturn nil\n}", []string{
+			"func RegisterRoutes(r v2.Router, ctrl *Ctl) {",
+			`r.Get("/a", exog_a(ctrl))`,
+			`r.Get("/b", exog_b(*ctrl))`,
+		}},
+		{"functions and methods", "func a(A) error {\n\treturn nil\n}\n\nfunc (*Ctl) b(B) error {\n\treturn nil\n}", []string{
+			"func RegisterRoutes(r v2.Router, ctrl *Ctl) {",
+			`r.Get("/a", exog_a)`,
+			`r.Get("/b", exog_b(ctrl))`,
+		}},
+	}
+
+	srcs := make([]string, len(tests))
+	for i, tt := range tests {
+		srcs[i] = src(tt.handlers)
+	}
+
+	codes := generateFile(t, "index_gen.go", srcs...)
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			containsAll(t, codes[i], tt.snippets...)
+		})
+	}
+}
+
+func TestGenerateTimeouts(t *testing.T) {
+	src := func(tag string) string {
+		return `package p
+
+import "github.com/exo-framework/exo"
+
+type Req struct {
+	exo.Get ` + "`route:\"/\"" + tag + "`" + `
+}
+
+func handle(req Req) (string, error) {
+	return "", req.Context().Err()
+}
+`
+	}
+
+	tests := []struct {
+		name     string
+		tag      string
+		snippets []string
+	}{
+		// the context is cancelled on disconnects without a timeout as well
+		{"no timeout", "", []string{"context_stop := exo.WatchContext(c, 0)\n\tdefer context_stop()", "if r_1 != nil {\n\t\treturn r_1\n\t}"}},
+		{"timeout", ` timeout:"5s"`, []string{"exo.WatchContext(c, 5*time.Second)", "if r_1 != nil {\n\t\treturn exo.TimeoutError(c.UserContext(), r_1)\n\t}"}},
+		{"minutes", ` timeout:"2m"`, []string{"exo.WatchContext(c, 2*time.Minute)"}},
+		{"fractions", ` timeout:"1.5s"`, []string{"exo.WatchContext(c, 1500*time.Millisecond)"}},
+	}
+
+	srcs := make([]string, len(tests))
+	for i, tt := range tests {
+		srcs[i] = src(tt.tag)
+	}
+
+	codes := generateSources(t, srcs...)
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			containsAll(t, codes[i], tt.snippets...)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package gen
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Ignore adds glob patterns of directories and files which are skipped by Analyze. The patterns are matched against the slash separated path
+// relative to the module root and against the base name. * matches within a path segment, ** matches across segments.
+func (g *Generator) Ignore(patterns ...string) {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+
+		g.ignore = append(g.ignore, globRegexp(strings.TrimSuffix(filepath.ToSlash(pattern), "/")))
+	}
+}
+
+// Roots returns the directories configured in the .exorc file using GEN_ROOTS (comma separated). If none are configured, the module root is returned.
+func (g *Generator) Roots() []string {
+	roots := splitTagList(g.rc["GEN_ROOTS"])
+	if len(roots) == 0 {
+		return []string{"."}
+	}
+
+	return roots
+}
+
+func (g *Generator) skip(p string, entry os.DirEntry) bool {
+	name := entry.Name()
+	if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+		return true
+	}
+
+	if entry.IsDir() {
+		if name == "vendor" || name == "testdata" {
+			return true
+		}
+
+		// nested modules are generated on their own
+		if _, err := os.Stat(filepath.Join(p, "go.mod")); err == nil {
+			return true
+		}
+	}
+
+	rel := filepath.ToSlash(filepath.Clean(p))
+	if abs, err := filepath.Abs(p); err == nil {
+		if r, err := filepath.Rel(g.root, abs); err == nil {
+			rel = filepath.ToSlash(r)
+		}
+	}
+
+	for _, re := range g.ignore {
+		if re.MatchString(rel) || re.MatchString(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func globRegexp(pattern string) *regexp.Regexp {
+	b := strings.Builder{}
+	b.WriteString("^")
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					// **/ also matches no directory at all
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
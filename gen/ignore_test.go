@@ -0,0 +1,99 @@
+package gen
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGlobRegexp(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"*.go", "a.go", true},
+		{"*.go", "dir/a.go", false},
+		{"legacy", "legacy", true},
+		{"legacy", "legacy/api", false},
+		{"internal/**", "internal/a/b", true},
+		{"internal/**", "internal", false},
+		{"**/mocks", "mocks", true},
+		{"**/mocks", "a/b/mocks", true},
+		{"**/mocks", "a/mocks2", false},
+		{"a?c", "abc", true},
+		{"a?c", "a/c", false},
+		{"a.b", "axb", false},
+		{"(a)+", "(a)+", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			if got := globRegexp(tt.pattern).MatchString(tt.path); got != tt.match {
+				t.Errorf("expected %v, got %v", tt.match, got)
+			}
+		})
+	}
+}
+
+func TestCollectDirs(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"api/requests.go":        "package api",
+		"api/requests_gen.go":    generatedHeader + "\npackage api",
+		"api/msgp_gen.go":        "// Code generated by github.com/tinylib/msgp DO NOT EDIT.\npackage api",
+		"tests/a_test.go":        "package tests",
+		"generated/index_gen.go": generatedHeader + "\npackage generated",
+		"vendor/v/v.go":          "package v",
+		"testdata/p/p.go":        "package p",
+		".hidden/h.go":           "package h",
+		"_tmp/t.go":              "package t",
+		"nested/go.mod":          "module nested",
+		"nested/n.go":            "package nested",
+		"internal/mocks/m.go":    "package mocks",
+		"legacy/l.go":            "package legacy",
+		"svc/legacy/l.go":        "package legacy",
+		"svc/s.go":               "package svc",
+		"archive/a_old.go":       "package archive",
+	}
+
+	for name, content := range files {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	g := &Generator{root: root}
+	g.Ignore("**/mocks", " legacy ", "", "*_old.go")
+
+	dirs := []string{}
+	if err := g.collectDirs(root, &dirs); err != nil {
+		t.Fatal(err)
+	}
+
+	rel := func(paths []string) []string {
+		out := []string{}
+		for _, p := range paths {
+			r, err := filepath.Rel(root, p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out = append(out, filepath.ToSlash(r))
+		}
+		return out
+	}
+
+	if want := []string{"api", "svc"}; !reflect.DeepEqual(rel(dirs), want) {
+		t.Errorf("expected dirs %v, got %v", want, rel(dirs))
+	}
+
+	if want := []string{"api/requests_gen.go", "generated/index_gen.go"}; !reflect.DeepEqual(rel(g.existing), want) {
+		t.Errorf("expected generated files %v, got %v", want, rel(g.existing))
+	}
+}
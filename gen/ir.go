@@ -1,5 +1,10 @@
 package gen
 
+import (
+	"go/types"
+	"time"
+)
+
 type Method string
 
 const (
@@ -19,57 +24,258 @@ const (
 	FieldPath   FieldType = "path"
 	FieldQuery  FieldType = "query"
 	FieldHeader FieldType = "header"
+	FieldCookie FieldType = "cookie"
 	FieldBody   FieldType = "body"
 	FieldForm   FieldType = "form"
+	FieldFile   FieldType = "file"
+	FieldAuth   FieldType = "auth"
+	FieldDB     FieldType = "db" // loaded from the database by the parameters of other fields
+)
+
+// TypeKind is the kind of a Go type as far as the generators need to know it.
+type TypeKind string
+
+const (
+	KindString  TypeKind = "string"
+	KindInt     TypeKind = "int"
+	KindUint    TypeKind = "uint"
+	KindFloat   TypeKind = "float"
+	KindBool    TypeKind = "bool"
+	KindBytes   TypeKind = "bytes"
+	KindFile    TypeKind = "file" // uploaded file of a multipart form
+	KindUUID    TypeKind = "uuid"
+	KindTime    TypeKind = "time"
+	KindStruct  TypeKind = "struct"
+	KindSlice   TypeKind = "slice"
+	KindMap     TypeKind = "map"
+	KindPointer TypeKind = "pointer"
+	KindAny     TypeKind = "any"
 )
 
+// TypeInfo describes the shape of a Go type for generators which need more than its name, e.g. the OpenAPI generator.
+type TypeInfo struct {
+	Kind   TypeKind
+	Name   string      // Name of the type if it is a named type, e.g. "GetTestDto" or "int64"
+	Pkg    string      // Import path of the package declaring a named type. Empty for predeclared types
+	Elem   *TypeInfo   // Element type of pointers, slices and maps
+	Fields []TypeField // Fields of structs. Nil for a struct which is currently being resolved (recursive types)
+	Format string      // Format of time and duration parameters, e.g. "date" or "duration". Empty for RFC 3339 timestamps
+}
+
+type TypeField struct {
+	Name     string
+	JSONName string
+	Optional bool // omitempty or pointer fields
+	Type     *TypeInfo
+	Rules    []ValidationRule
+}
+
+// ValidationRule is a built-in rule of a validate tag, e.g. min=3 or email.
+type ValidationRule struct {
+	Name  string // min, max, gte, lte, gt, lt, oneof, regex, email, url, uuid, notempty or dive
+	Param string // e.g. "3" for min=3. The values of oneof are separated by |
+}
+
 type Field struct {
 	Name          string
-	DataType      string
+	DataType      string     // Type as written in the requests file, e.g. "uuid.UUID"
+	GoType        types.Type // Type checked type of the field
+	Type          *TypeInfo
 	FieldType     FieldType
 	FieldKey      string
 	Validator     *string
 	ValidaotrFunc *Function
-	LoadFromDB    *string // If not nil, the field will be loaded from the database using the given string as WHERE clause
+	Rules         []ValidationRule
+	LoadFromDB    *DBLoad  // If not nil, the field will be loaded from the database
+	Default       *string  // Value of the default tag, used if the parameter is absent
+	Separator     string   // Separator of delimited slice parameters like ?ids=1,2,3. Empty for repeated keys like ?tag=a&tag=b
+	Format        string   // Value of the format tag of time parameters: rfc3339 (default), date, unix, unixmilli or a layout of the time package
+	MaxSize       int64    // Maximum size of uploaded files in bytes, 0 if unlimited
+	Accept        []string // Media types uploaded files may have, e.g. image/png or image/*. Empty if every type is accepted
 	NotEmpty      bool
+	AuthOptional  bool // If true, unauthenticated requests are passed to the handler instead of being rejected
+}
+
+// DBLoad describes how a field is loaded from the database.
+type DBLoad struct {
+	Keys     []DBKey  // Columns the records are looked up by
+	Preload  []string // Associations loaded along with the records, e.g. Author or Author.Company
+	Owner    string   // Column which must equal the subject of the claims, empty if the records are not scoped to their owner
+	Unscoped bool     // If true, soft-deleted records are loaded too
+	Many     bool     // If true, the field is a slice loaded with Find, which is empty instead of 404 if no record matches
+}
+
+// DBKey is a column the records of a field are looked up by.
+type DBKey struct {
+	Column string
+	Param  string // Key of the parameter the column is compared with, empty for the parameter of the field itself
+	Field  string // Name of the field bound to the parameter
 }
 
 type Request struct {
 	StructName string
 	Route      string
+	Prefix     string // Prefix of the exo.Group of the package, which is prepended to the route
 	Method     Method
+	Roles      []string      // The claims must contain at least one of these roles
+	Scopes     []string      // The claims must contain all of these scopes
+	Middleware []string      // Package-level fiber handlers of the use tag, which run before the handler
+	Timeout    time.Duration // Deadline of the request context from the timeout tag, 0 if the route has none
 	Fields     []Field
 	Handler    *Function
 }
 
+// ReturnKind describes how the generated handlers send a value returned by a handler.
+type ReturnKind string
+
+const (
+	ReturnError     ReturnKind = "error"     // passed to the error handler if not nil
+	ReturnStatus    ReturnKind = "status"    // signed integers, sent as status code
+	ReturnString    ReturnKind = "string"    // sent as plain text
+	ReturnBytes     ReturnKind = "bytes"     // sent as binary
+	ReturnJSON      ReturnKind = "json"      // interface{}, any, structs, slices, maps and pointers to them, sent as JSON
+	ReturnSerialize ReturnKind = "serialize" // exo.Serialize, sent in the format the client accepts
+	ReturnCookies   ReturnKind = "cookies"   // exo.Cookies, set on the response
+	ReturnResponse  ReturnKind = "response"  // exo.Response, which carries status, headers, cookies and body
+)
+
+// Content reports whether the value is sent as body of the response.
+func (k ReturnKind) Content() bool {
+	return k == ReturnString || k == ReturnBytes || k == ReturnJSON || k == ReturnSerialize
+}
+
 type Function struct {
-	Name    string
-	Params  map[string]string
-	Returns []string
+	Name            string
+	Receiver        string // Type name of the controller if the function is a method, empty otherwise
+	PointerReceiver bool
+	Params          map[string]string
+	Returns         []string
+	ReturnKinds     []ReturnKind
+	ReturnTypes     []*TypeInfo // For exo.Response and exo.Serialize, the type of the value they send
+	ReturnGoTypes   []types.Type
+	ReturnErr       error // Invalid validate tags of the returned types, reported once the function is linked to a request
+}
+
+// Body returns the index of the return value holding the body of the response, how it is sent and its type. The index is -1 if
+// the handler returns no body. Only the first content is sent.
+func (f Function) Body() (int, ReturnKind, *TypeInfo) {
+	for i, kind := range f.ReturnKinds {
+		switch {
+		case kind.Content():
+			return i, kind, f.ReturnTypes[i]
+		case kind == ReturnResponse:
+			return i, bodyKind(exoTypeArg(f.ReturnGoTypes[i], "Response")), f.ReturnTypes[i]
+		}
+	}
+
+	return -1, "", nil
+}
+
+// Controller returns the type name of the controller whose methods handle requests of the package, and whether the methods need
+// a pointer to it. The name is empty if all handlers are functions.
+func Controller(files []RequestsFile) (string, bool) {
+	name, pointer := "", false
+	for _, reqFile := range files {
+		for _, req := range reqFile.Requests {
+			if req.Handler.Receiver != "" {
+				name = req.Handler.Receiver
+				pointer = pointer || req.Handler.PointerReceiver
+			}
+		}
+	}
+
+	return name, pointer
+}
+
+// Group is the exo.Group variable of a package.
+type Group struct {
+	Var    string // Name of the variable
+	Prefix string
+}
+
+// Path returns the path of the request including the prefix of its group.
+func (r Request) Path() string {
+	return r.Prefix + r.Route
+}
+
+// HasNoBody reports whether the method of the request is sent without a body, like GET and HEAD.
+func (r Request) HasNoBody() bool {
+	return r.Method == MethodGet || r.Method == MethodHead
 }
 
 type RequestsFile struct {
 	FileName  string
 	Package   string
+	PkgPath   string
 	Imports   map[string]string
 	Requests  []Request
 	Functions []Function
+	Group     *Group // Group of the package, nil if the routes are registered without prefix and middleware
+}
+
+// Required reports whether a client must send the field. Non-string values fail to parse when absent, unless they are optional
+// or have a default value.
+func (f Field) Required() bool {
+	switch {
+	case f.FieldType == FieldPath || f.FieldType == FieldBody:
+		return true
+	case f.Default != nil:
+		return false
+	case f.Optional() || f.SliceElem() != nil:
+		return f.NotEmpty
+	}
+
+	return paramKindOf(f.GoType) != paramString || f.NotEmpty
+}
+
+// Optional reports whether the field is a pointer parameter, which is nil if the parameter is absent.
+func (f Field) Optional() bool {
+	_, ok := f.GoType.(*types.Pointer)
+	return ok && f.LoadFromDB == nil && f.FieldType != FieldBody && f.FieldType != FieldAuth
+}
+
+// SliceElem returns the element type of a slice parameter, or nil if the field is no slice parameter.
+func (f Field) SliceElem() types.Type {
+	if f.LoadFromDB != nil || f.FieldType == FieldBody || f.FieldType == FieldAuth {
+		return nil
+	}
+
+	if slice, ok := f.GoType.Underlying().(*types.Slice); ok {
+		return slice.Elem()
+	}
+
+	return nil
+}
+
+// ParamType returns the type a parameter is parsed as, which is the element type of optional fields.
+func (f Field) ParamType() (types.Type, *TypeInfo) {
+	if f.Optional() {
+		return f.GoType.(*types.Pointer).Elem(), f.Type.Elem
+	}
+
+	return f.GoType, f.Type
 }
 
 func (t FieldType) Priority() int {
 	switch t {
-	case FieldHeader:
+	case FieldAuth:
 		return 0
-	case FieldPath:
+	case FieldHeader:
 		return 1
-	case FieldQuery:
+	case FieldCookie:
 		return 2
-	case FieldBody:
+	case FieldPath:
 		return 3
-	case FieldForm:
+	case FieldQuery:
 		return 4
-	default:
+	case FieldBody:
 		return 5
+	case FieldForm:
+		return 6
+	case FieldFile:
+		return 7
+	default:
+		return 8
 	}
 }
 
@@ -77,6 +283,8 @@ func (t FieldType) SimpleRetriever() string {
 	switch t {
 	case FieldHeader:
 		return "Get"
+	case FieldCookie:
+		return "Cookies"
 	case FieldPath:
 		return "Params"
 	case FieldQuery:
@@ -87,3 +295,17 @@ func (t FieldType) SimpleRetriever() string {
 		return ""
 	}
 }
+
+// MultiRetriever returns the exo function which retrieves all values of a slice parameter.
+func (t FieldType) MultiRetriever() string {
+	switch t {
+	case FieldHeader:
+		return "HeaderValues"
+	case FieldQuery:
+		return "QueryValues"
+	case FieldForm:
+		return "FormValues"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,639 @@
+package gen
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-json"
+)
+
+var routeParamRegex = regexp.MustCompile(`:([A-Za-z0-9_\-.]+)(<[^>]*>)?\??`)
+
+type openAPIObject map[string]any
+
+// openAPISchemas collects the component schemas of a document. Schemas are named after their Go type only, so the package of each
+// type is kept to report types of different packages with the same name.
+type openAPISchemas struct {
+	objects openAPIObject
+	pkgs    map[string]string
+	err     error
+}
+
+// claim reserves the schema name for the type of the given package and reports whether the schema still has to be added.
+func (s *openAPISchemas) claim(name, pkg string) bool {
+	if owner, ok := s.pkgs[name]; ok {
+		if owner != pkg && s.err == nil {
+			s.err = errors.Join(ErrNameCollision, fmt.Errorf("the schema %s is used by the types of %s and %s", name, owner, pkg))
+		}
+
+		return false
+	}
+
+	s.pkgs[name] = pkg
+	return true
+}
+
+// GenerateOpenAPI generates an OpenAPI 3.1 document describing all analyzed requests and writes it to the given file.
+func (g *Generator) GenerateOpenAPI(out string) error {
+	doc, err := g.buildOpenAPI()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(out, append(data, '\n'), 0644)
+}
+
+func (g *Generator) buildOpenAPI() (openAPIObject, error) {
+	title, ok := g.rc["OPENAPI_TITLE"]
+	if !ok {
+		title = g.module
+	}
+
+	version, ok := g.rc["OPENAPI_VERSION"]
+	if !ok {
+		version = "1.0.0"
+	}
+
+	schemas := &openAPISchemas{objects: openAPIObject{}, pkgs: map[string]string{}}
+	paths := openAPIObject{}
+	operations := map[string]string{}
+	usesAuth := false
+
+	dirs := make([]string, 0, len(g.packages))
+	for dir := range g.packages {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	for _, dir := range dirs {
+		for _, reqFile := range g.packages[dir] {
+			for _, req := range reqFile.Requests {
+				if other, ok := operations[req.Handler.Name]; ok && other != reqFile.PkgPath {
+					return nil, errors.Join(ErrNameCollision, fmt.Errorf("the operation %s is handled in %s and %s", req.Handler.Name, other, reqFile.PkgPath))
+				}
+				operations[req.Handler.Name] = reqFile.PkgPath
+
+				route := openAPIRoute(req.Path())
+
+				item, ok := paths[route].(openAPIObject)
+				if !ok {
+					item = openAPIObject{}
+					paths[route] = item
+				}
+
+				op := g.openAPIOperation(req, filepath.Base(dir), schemas)
+				if _, ok := op["security"]; ok {
+					usesAuth = true
+				}
+
+				item[strings.ToLower(string(req.Method))] = op
+			}
+		}
+	}
+
+	if schemas.err != nil {
+		return nil, schemas.err
+	}
+
+	components := openAPIObject{"schemas": schemas.objects}
+	if usesAuth {
+		components["securitySchemes"] = openAPIObject{
+			"bearerAuth": openAPIObject{
+				"type":         "http",
+				"scheme":       "bearer",
+				"bearerFormat": "JWT",
+			},
+		}
+	}
+
+	return openAPIObject{
+		"openapi":    "3.1.0",
+		"info":       openAPIObject{"title": title, "version": version},
+		"paths":      paths,
+		"components": components,
+	}, nil
+}
+
+func (g *Generator) openAPIOperation(req Request, tag string, schemas *openAPISchemas) openAPIObject {
+	op := openAPIObject{
+		"operationId": req.Handler.Name,
+		"tags":        []string{tag},
+	}
+
+	params := []openAPIObject{}
+	seenParams := map[string]bool{}
+	formProps := openAPIObject{}
+	formRequired := []string{}
+	fileEncoding := openAPIObject{}
+	hasFiles := false
+	limitsSize := false
+	requiresAuth := len(req.Roles) > 0 || len(req.Scopes) > 0
+	loadsFromDB := false
+
+	for _, field := range req.Fields {
+		// slices are empty instead of 404 if no record matches, records of other owners are not found
+		if field.LoadFromDB != nil {
+			loadsFromDB = loadsFromDB || !field.LoadFromDB.Many
+			requiresAuth = requiresAuth || field.LoadFromDB.Owner != ""
+		}
+
+		switch field.FieldType {
+		case FieldAuth:
+			if !field.AuthOptional {
+				requiresAuth = true
+			}
+		case FieldBody:
+			op["requestBody"] = openAPIObject{
+				"required": true,
+				"content": openAPIObject{
+					"application/json": openAPIObject{"schema": openAPISchema(field.Type, schemas)},
+				},
+			}
+		case FieldForm:
+			formProps[field.FieldKey] = openAPIParamSchema(field, schemas)
+			if field.Required() {
+				formRequired = append(formRequired, field.FieldKey)
+			}
+		case FieldFile:
+			formProps[field.FieldKey] = openAPIRules(openAPISchema(field.Type, schemas), field.Type, field.Rules)
+			if field.Required() {
+				formRequired = append(formRequired, field.FieldKey)
+			}
+
+			if len(field.Accept) > 0 {
+				fileEncoding[field.FieldKey] = openAPIObject{"contentType": strings.Join(field.Accept, ", ")}
+			}
+
+			hasFiles = true
+			limitsSize = limitsSize || field.MaxSize > 0
+		case FieldPath, FieldQuery, FieldHeader, FieldCookie:
+			schema := openAPIParamSchema(field, schemas)
+
+			key := string(field.FieldType) + ":" + field.FieldKey
+			if seenParams[key] {
+				continue
+			}
+			seenParams[key] = true
+
+			param := openAPIObject{
+				"name":     field.FieldKey,
+				"in":       string(field.FieldType),
+				"required": field.Required(),
+				"schema":   schema,
+			}
+
+			if field.SliceElem() != nil && field.FieldType == FieldQuery {
+				openAPIQueryStyle(param, field.Separator)
+			}
+
+			params = append(params, param)
+		}
+	}
+
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+
+	if _, ok := op["requestBody"]; !ok && len(formProps) > 0 {
+		schema := openAPIObject{"type": "object", "properties": formProps}
+		if len(formRequired) > 0 {
+			schema["required"] = formRequired
+		}
+
+		content := openAPIObject{
+			"application/x-www-form-urlencoded": openAPIObject{"schema": schema},
+			"multipart/form-data":               openAPIObject{"schema": schema},
+		}
+
+		// files can only be uploaded as multipart form
+		if hasFiles {
+			multipart := openAPIObject{"schema": schema}
+			if len(fileEncoding) > 0 {
+				multipart["encoding"] = fileEncoding
+			}
+
+			content = openAPIObject{"multipart/form-data": multipart}
+		}
+
+		op["requestBody"] = openAPIObject{
+			"required": len(formRequired) > 0,
+			"content":  content,
+		}
+	}
+
+	if requiresAuth {
+		op["security"] = []openAPIObject{{"bearerAuth": []string{}}}
+	}
+
+	if len(req.Roles) > 0 {
+		op["x-exo-roles"] = req.Roles
+	}
+
+	if len(req.Scopes) > 0 {
+		op["x-exo-scopes"] = req.Scopes
+	}
+
+	if req.Timeout > 0 {
+		op["x-exo-timeout"] = req.Timeout.String()
+	}
+
+	responses := g.openAPIResponses(req, schemas)
+	if len(params) > 0 || op["requestBody"] != nil {
+		responses["400"] = openAPIProblemResponse("Bad Request", schemas)
+	}
+
+	if requiresAuth {
+		responses["401"] = openAPIProblemResponse("Unauthorized", schemas)
+	}
+
+	if len(req.Roles) > 0 || len(req.Scopes) > 0 {
+		responses["403"] = openAPIProblemResponse("Forbidden", schemas)
+	}
+
+	if loadsFromDB {
+		responses["404"] = openAPIProblemResponse("Not Found", schemas)
+	}
+
+	if limitsSize {
+		responses["413"] = openAPIProblemResponse("Content Too Large", schemas)
+	}
+
+	if req.Timeout > 0 {
+		responses["504"] = openAPIProblemResponse("Gateway Timeout", schemas)
+	}
+
+	op["responses"] = responses
+
+	return op
+}
+
+// openAPIQueryStyle describes how the values of a slice query parameter are serialized. Separators without an OpenAPI style are
+// given by the x-exo-separator extension.
+func openAPIQueryStyle(param openAPIObject, separator string) {
+	switch separator {
+	case "":
+		param["style"], param["explode"] = "form", true
+	case ",":
+		param["style"], param["explode"] = "form", false
+	case "|":
+		param["style"], param["explode"] = "pipeDelimited", false
+	case " ":
+		param["style"], param["explode"] = "spaceDelimited", false
+	default:
+		param["style"], param["explode"] = "form", false
+		param["x-exo-separator"] = separator
+	}
+}
+
+// openAPIParamSchema returns the schema of a path, query, header or form parameter including its rules and default value.
+func openAPIParamSchema(field Field, schemas *openAPISchemas) openAPIObject {
+	if field.LoadFromDB != nil {
+		schema := openAPIRules(openAPIObject{"type": "string"}, &TypeInfo{Kind: KindString}, field.Rules)
+		if field.Default != nil {
+			schema["default"] = *field.Default
+		}
+
+		return schema
+	}
+
+	_, info := field.ParamType()
+	schema := openAPIRules(openAPISchema(info, schemas), info, field.Rules)
+
+	if field.Default == nil {
+		return schema
+	}
+
+	if info.Kind != KindSlice {
+		schema["default"] = openAPIDefault(info, *field.Default)
+		return schema
+	}
+
+	values := []string{*field.Default}
+	if field.Separator != "" {
+		values = strings.Split(*field.Default, field.Separator)
+	}
+
+	defaults := []any{}
+	for _, value := range values {
+		defaults = append(defaults, openAPIDefault(info.Elem, value))
+	}
+	schema["default"] = defaults
+
+	return schema
+}
+
+// openAPIDefault converts the value of a default tag to the JSON type of the parameter.
+func openAPIDefault(t *TypeInfo, value string) any {
+	switch t.Kind {
+	case KindInt, KindUint, KindFloat:
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return i
+		} else if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case KindBool:
+		b, _ := strconv.ParseBool(value)
+		return b
+	}
+
+	return value
+}
+
+// openAPIProblemResponse describes a response with an RFC 9457 problem document like the ones sent by exo.SendValidationErrors and the
+// default error handler.
+func openAPIProblemResponse(description string, schemas *openAPISchemas) openAPIObject {
+	if schemas.claim("Problem", exoPkgPath) && schemas.claim("FieldError", exoPkgPath) {
+		schemas.objects["FieldError"] = openAPIObject{
+			"type": "object",
+			"properties": openAPIObject{
+				"field":   openAPIObject{"type": "string"},
+				"source":  openAPIObject{"type": "string", "enum": []string{"path", "query", "header", "cookie", "body", "form", "file"}},
+				"code":    openAPIObject{"type": "string"},
+				"message": openAPIObject{"type": "string"},
+			},
+			"required": []string{"field", "source", "code", "message"},
+		}
+		schemas.objects["Problem"] = openAPIObject{
+			"type": "object",
+			"properties": openAPIObject{
+				"type":     openAPIObject{"type": "string"},
+				"title":    openAPIObject{"type": "string"},
+				"status":   openAPIObject{"type": "integer"},
+				"detail":   openAPIObject{"type": "string"},
+				"instance": openAPIObject{"type": "string"},
+				"errors":   openAPIObject{"type": "array", "items": openAPIObject{"$ref": "#/components/schemas/FieldError"}},
+			},
+			"required": []string{"title", "status"},
+		}
+	}
+
+	return openAPIObject{
+		"description": description,
+		"content": openAPIObject{
+			"application/problem+json": openAPIObject{"schema": openAPIObject{"$ref": "#/components/schemas/Problem"}},
+		},
+	}
+}
+
+func (g *Generator) openAPIResponses(req Request, schemas *openAPISchemas) openAPIObject {
+	var content openAPIObject
+	hasStatus := false
+	hasError := false
+	hasCookies := false
+
+	for _, kind := range req.Handler.ReturnKinds {
+		switch kind {
+		case ReturnError:
+			hasError = true
+		case ReturnStatus:
+			hasStatus = true
+		case ReturnCookies:
+			hasCookies = true
+		case ReturnResponse:
+			// the status and the cookies are chosen by the handler at runtime
+			hasStatus, hasCookies = true, true
+		}
+	}
+
+	switch _, kind, t := req.Handler.Body(); kind {
+	case ReturnString:
+		content = openAPIObject{"text/plain": openAPIObject{"schema": openAPIObject{"type": "string"}}}
+	case ReturnBytes:
+		content = openAPIObject{"application/octet-stream": openAPIObject{"schema": openAPIObject{"type": "string", "format": "binary"}}}
+	case ReturnJSON:
+		content = openAPIObject{"application/json": openAPIObject{"schema": openAPISchema(t, schemas)}}
+	case ReturnSerialize:
+		schema := openAPISchema(t, schemas)
+		content = openAPIObject{
+			"application/json":    openAPIObject{"schema": schema},
+			"application/xml":     openAPIObject{"schema": schema},
+			"application/msgpack": openAPIObject{"schema": schema},
+			"application/cbor":    openAPIObject{"schema": schema},
+		}
+	}
+
+	response := openAPIObject{"description": "Successful Response"}
+	code := "200"
+	if content != nil {
+		response["content"] = content
+	} else {
+		code = "204"
+		response["description"] = "No Content"
+	}
+
+	if hasCookies {
+		response["headers"] = openAPIObject{
+			"Set-Cookie": openAPIObject{"description": "Cookies set by the handler", "schema": openAPIObject{"type": "string"}},
+		}
+	}
+
+	// the status code is chosen by the handler at runtime
+	if hasStatus {
+		code = "default"
+		response["description"] = "Response"
+	}
+
+	responses := openAPIObject{code: response}
+	if hasError && !hasStatus {
+		responses["500"] = openAPIProblemResponse("Internal Server Error", schemas)
+	}
+
+	if _, kind, _ := req.Handler.Body(); kind == ReturnSerialize {
+		responses["406"] = openAPIProblemResponse("Not Acceptable", schemas)
+	}
+
+	return responses
+}
+
+func openAPIRoute(route string) string {
+	return routeParamRegex.ReplaceAllString(route, "{$1}")
+}
+
+func openAPISchema(t *TypeInfo, schemas *openAPISchemas) openAPIObject {
+	if t == nil {
+		return openAPIObject{}
+	}
+
+	switch t.Kind {
+	case KindString:
+		return openAPIObject{"type": "string"}
+	case KindInt:
+		switch t.Name {
+		case "int8", "int16", "int32", "rune":
+			return openAPIObject{"type": "integer", "format": "int32"}
+		}
+		return openAPIObject{"type": "integer", "format": "int64"}
+	case KindUint:
+		return openAPIObject{"type": "integer", "minimum": 0}
+	case KindFloat:
+		if t.Name == "float32" {
+			return openAPIObject{"type": "number", "format": "float"}
+		}
+		return openAPIObject{"type": "number", "format": "double"}
+	case KindBool:
+		return openAPIObject{"type": "boolean"}
+	case KindBytes:
+		return openAPIObject{"type": "string", "contentEncoding": "base64"}
+	case KindFile:
+		return openAPIObject{"type": "string", "format": "binary"}
+	case KindUUID:
+		return openAPIObject{"type": "string", "format": "uuid"}
+	case KindTime:
+		switch t.Format {
+		case "", "rfc3339":
+			return openAPIObject{"type": "string", "format": "date-time"}
+		case "date":
+			return openAPIObject{"type": "string", "format": "date"}
+		}
+
+		return openAPIObject{"type": "string"}
+	case KindPointer:
+		return openAPISchema(t.Elem, schemas)
+	case KindSlice:
+		return openAPIObject{"type": "array", "items": openAPISchema(t.Elem, schemas)}
+	case KindMap:
+		return openAPIObject{"type": "object", "additionalProperties": openAPISchema(t.Elem, schemas)}
+	case KindStruct:
+		if t.Name == "" {
+			return openAPIStructSchema(t, schemas)
+		}
+
+		ref := openAPIObject{"$ref": "#/components/schemas/" + t.Name}
+		if !schemas.claim(t.Name, t.Pkg) || t.Fields == nil {
+			return ref
+		}
+
+		schemas.objects[t.Name] = openAPIStructSchema(t, schemas)
+		return ref
+	}
+
+	return openAPIObject{}
+}
+
+func openAPIStructSchema(t *TypeInfo, schemas *openAPISchemas) openAPIObject {
+	props := openAPIObject{}
+	required := []string{}
+
+	for _, field := range t.Fields {
+		schema := openAPIRules(openAPISchema(field.Type, schemas), field.Type, field.Rules)
+
+		// nil pointers are marshalled as null
+		if field.Type.Kind == KindPointer {
+			schema = openAPIObject{"anyOf": []any{schema, openAPIObject{"type": "null"}}}
+		}
+
+		props[field.JSONName] = schema
+		if !field.Optional {
+			required = append(required, field.JSONName)
+		}
+	}
+
+	schema := openAPIObject{"type": "object", "properties": props}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+// openAPIRules adds the constraints of validation rules to the schema of a value of type t.
+func openAPIRules(schema openAPIObject, t *TypeInfo, rules []ValidationRule) openAPIObject {
+	for t != nil && t.Kind == KindPointer {
+		t = t.Elem
+	}
+
+	// the rules of durations compare nanoseconds, which cannot be expressed for strings like 1h30m
+	if t == nil || len(rules) == 0 || t.Format == "duration" {
+		return schema
+	}
+
+	number := func(v string) any {
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return i
+		}
+
+		f, _ := strconv.ParseFloat(v, 64)
+		return f
+	}
+
+	for i, rule := range rules {
+		// the rules after dive apply to the items of a slice
+		if rule.Name == "dive" {
+			if items, ok := schema["items"].(openAPIObject); ok && t.Kind == KindSlice && items["$ref"] == nil {
+				schema["items"] = openAPIRules(items, t.Elem, rules[i+1:])
+			}
+
+			break
+		}
+
+		switch t.Kind {
+		case KindString:
+			switch rule.Name {
+			case "notempty":
+				if _, ok := schema["minLength"]; !ok {
+					schema["minLength"] = 1
+				}
+			case "min":
+				schema["minLength"] = number(rule.Param)
+			case "max":
+				schema["maxLength"] = number(rule.Param)
+			case "email":
+				schema["format"] = "email"
+			case "url":
+				schema["format"] = "uri"
+			case "uuid":
+				schema["format"] = "uuid"
+			case "regex":
+				schema["pattern"] = rule.Param
+			case "oneof":
+				schema["enum"] = strings.Split(rule.Param, "|")
+			}
+		case KindInt, KindUint, KindFloat:
+			switch rule.Name {
+			case "min", "gte":
+				schema["minimum"] = number(rule.Param)
+			case "max", "lte":
+				schema["maximum"] = number(rule.Param)
+			case "gt":
+				schema["exclusiveMinimum"] = number(rule.Param)
+			case "lt":
+				schema["exclusiveMaximum"] = number(rule.Param)
+			case "oneof":
+				values := []any{}
+				for _, v := range strings.Split(rule.Param, "|") {
+					values = append(values, number(v))
+				}
+				schema["enum"] = values
+			}
+		case KindSlice, KindMap:
+			prefix := "Items"
+			if t.Kind == KindMap {
+				prefix = "Properties"
+			}
+
+			switch rule.Name {
+			case "notempty":
+				if _, ok := schema["min"+prefix]; !ok {
+					schema["min"+prefix] = 1
+				}
+			case "min":
+				schema["min"+prefix] = number(rule.Param)
+			case "max":
+				schema["max"+prefix] = number(rule.Param)
+			}
+		}
+	}
+
+	return schema
+}
@@ -0,0 +1,260 @@
+package gen
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// jsonPointer returns the value at the RFC 6901 pointer in the JSON encoding of doc, e.g. /paths/~1items/get.
+func jsonPointer(t *testing.T, doc any, pointer string) (any, bool) {
+	t.Helper()
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, token := range strings.Split(pointer, "/")[1:] {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch v := value.(type) {
+		case map[string]any:
+			var ok bool
+			if value, ok = v[token]; !ok {
+				return nil, false
+			}
+		case []any:
+			i := -1
+			if err := json.Unmarshal([]byte(token), &i); err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			value = v[i]
+		default:
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+const openAPITestSource = `package p
+
+import (
+	"mime/multipart"
+	"time"
+
+	"github.com/exo-framework/exo"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Level int
+
+func (l Level) MarshalText() ([]byte, error) {
+	return nil, nil
+}
+
+type Raw struct {
+	X int
+}
+
+func (r Raw) MarshalJSON() ([]byte, error) {
+	return nil, nil
+}
+
+type Item struct {
+	ID        uuid.UUID      ` + "`json:\"id\"`" + `
+	Name      string         ` + "`json:\"name\" validate:\"min=1,max=64\"`" + `
+	Note      *string        ` + "`json:\"note\"`" + `
+	Tags      []string       ` + "`json:\"tags,omitempty\"`" + `
+	Level     Level          ` + "`json:\"level\"`" + `
+	Raw       Raw            ` + "`json:\"raw\"`" + `
+	DeletedAt gorm.DeletedAt ` + "`json:\"deletedAt\"`" + `
+	Created   time.Time      ` + "`json:\"created\"`" + `
+	Ignored   string         ` + "`json:\"-\"`" + `
+}
+
+type GetItem struct {
+	exo.Get ` + "`route:\"/items/:id\" timeout:\"2s\"`" + `
+	Id      int        ` + "`path:\"id\"`" + `
+	Limit   int        ` + "`query:\"limit\" default:\"20\" validate:\"min=1,max=100\"`" + `
+	Tags    []string   ` + "`query:\"tag\"`" + `
+	Ids     []int      ` + "`query:\"ids\" sep:\",\"`" + `
+	Auth    exo.Claims ` + "`auth:\"\"`" + `
+}
+
+func getItem(GetItem) (Item, error) {
+	return Item{}, nil
+}
+
+type PutItem struct {
+	exo.Put ` + "`route:\"/items/:id\" roles:\"admin\"`" + `
+	Id      int  ` + "`path:\"id\"`" + `
+	Item    Item ` + "`body:\"\"`" + `
+}
+
+func putItem(PutItem) error {
+	return nil
+}
+
+type Upload struct {
+	exo.Post ` + "`route:\"/upload\"`" + `
+	File     *multipart.FileHeader ` + "`file:\"file\" maxsize:\"1MB\" accept:\"image/png\"`" + `
+	Title    string                ` + "`form:\"title\"`" + `
+}
+
+func upload(Upload) (exo.Cookies, error) {
+	return nil, nil
+}
+
+type Export struct {
+	exo.Get ` + "`route:\"/export\"`" + `
+}
+
+func export(Export) (exo.Serialize[[]Item], error) {
+	return exo.Serialize[[]Item]{}, nil
+}
+`
+
+func TestOpenAPI(t *testing.T) {
+	gens, _, errs := analyzeSources(t, openAPITestSource)
+	if errs[0] != nil {
+		t.Fatal(errs[0])
+	}
+
+	doc, err := gens[0].buildOpenAPI()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		pointer string
+		value   string // JSON encoding of the expected value, empty if the pointer must not exist
+	}{
+		{"version", "/openapi", `"3.1.0"`},
+		{"operation", "/paths/~1items~1{id}/get/operationId", `"getItem"`},
+		{"path parameter", "/paths/~1items~1{id}/get/parameters/0", `{"in":"path","name":"id","required":true,"schema":{"format":"int64","type":"integer"}}`},
+		{"default and rules", "/paths/~1items~1{id}/get/parameters/1", `{"in":"query","name":"limit","required":false,"schema":{"default":20,"format":"int64","maximum":100,"minimum":1,"type":"integer"}}`},
+		{"repeated query", "/paths/~1items~1{id}/get/parameters/2/explode", `true`},
+		{"delimited query", "/paths/~1items~1{id}/get/parameters/3/explode", `false`},
+		{"auth field", "/paths/~1items~1{id}/get/security", `[{"bearerAuth":[]}]`},
+		{"json response", "/paths/~1items~1{id}/get/responses/200/content/application~1json/schema", `{"$ref":"#/components/schemas/Item"}`},
+		{"validation response", "/paths/~1items~1{id}/get/responses/400/content/application~1problem+json/schema", `{"$ref":"#/components/schemas/Problem"}`},
+		{"unauthorized response", "/paths/~1items~1{id}/get/responses/401/description", `"Unauthorized"`},
+		{"error response", "/paths/~1items~1{id}/get/responses/500/description", `"Internal Server Error"`},
+		{"timeout", "/paths/~1items~1{id}/get/x-exo-timeout", `"2s"`},
+		{"timeout response", "/paths/~1items~1{id}/get/responses/504/description", `"Gateway Timeout"`},
+		{"body", "/paths/~1items~1{id}/put/requestBody", `{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/Item"}}},"required":true}`},
+		{"no content", "/paths/~1items~1{id}/put/responses/204/description", `"No Content"`},
+		{"roles", "/paths/~1items~1{id}/put/x-exo-roles", `["admin"]`},
+		{"forbidden response", "/paths/~1items~1{id}/put/responses/403/description", `"Forbidden"`},
+		{"files", "/paths/~1upload/post/requestBody/content/multipart~1form-data/schema/properties/file", `{"format":"binary","type":"string"}`},
+		{"accepted file types", "/paths/~1upload/post/requestBody/content/multipart~1form-data/encoding/file/contentType", `"image/png"`},
+		{"no urlencoded form with files", "/paths/~1upload/post/requestBody/content/application~1x-www-form-urlencoded", ``},
+		{"size limit response", "/paths/~1upload/post/responses/413/description", `"Content Too Large"`},
+		{"cookies", "/paths/~1upload/post/responses/204/headers/Set-Cookie/schema", `{"type":"string"}`},
+		{"public operation", "/paths/~1upload/post/security", ``},
+		{"serialized formats", "/paths/~1export/get/responses/200/content/application~1cbor/schema", `{"items":{"$ref":"#/components/schemas/Item"},"type":"array"}`},
+		{"uuid", "/components/schemas/Item/properties/id", `{"format":"uuid","type":"string"}`},
+		{"string rules", "/components/schemas/Item/properties/name", `{"maxLength":64,"minLength":1,"type":"string"}`},
+		{"pointer", "/components/schemas/Item/properties/note", `{"anyOf":[{"type":"string"},{"type":"null"}]}`},
+		{"text marshaler", "/components/schemas/Item/properties/level", `{"type":"string"}`},
+		{"json marshaler", "/components/schemas/Item/properties/raw", `{}`},
+		{"soft delete", "/components/schemas/Item/properties/deletedAt", `{"anyOf":[{"format":"date-time","type":"string"},{"type":"null"}]}`},
+		{"time", "/components/schemas/Item/properties/created", `{"format":"date-time","type":"string"}`},
+		{"ignored field", "/components/schemas/Item/properties/Ignored", ``},
+		{"required fields", "/components/schemas/Item/required", `["id","name","level","raw","created"]`},
+		{"security scheme", "/components/securitySchemes/bearerAuth", `{"bearerFormat":"JWT","scheme":"bearer","type":"http"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, ok := jsonPointer(t, doc, tt.pointer)
+			if tt.value == "" {
+				if ok {
+					t.Fatalf("unexpected %s: %v", tt.pointer, value)
+				}
+				return
+			}
+
+			if !ok {
+				t.Fatalf("missing %s", tt.pointer)
+			}
+
+			var want any
+			if err := json.Unmarshal([]byte(tt.value), &want); err != nil {
+				t.Fatal(err)
+			}
+
+			got, _ := json.Marshal(value)
+			wantJSON, _ := json.Marshal(want)
+			if string(got) != string(wantJSON) {
+				t.Errorf("expected %s, got %s", wantJSON, got)
+			}
+		})
+	}
+}
+
+func TestOpenAPINameCollisions(t *testing.T) {
+	src := func(name, handler string) string {
+		return `package ` + name + `
+
+import "github.com/exo-framework/exo"
+
+type Item struct {
+	Name string ` + "`json:\"name\"`" + `
+}
+
+type Get struct {
+	exo.Get ` + "`route:\"/" + name + "\"`" + `
+}
+
+func ` + handler + `(Get) (Item, error) {
+	return Item{}, nil
+}
+`
+	}
+
+	tests := []struct {
+		name string
+		srcs []string
+		msg  string
+	}{
+		{"same schema name", []string{src("a", "getA"), src("b", "getB")}, "the schema Item is used by the types of"},
+		{"same operation", []string{src("a", "get"), src("b", "get")}, "the operation get is handled in"},
+	}
+
+	// the packages of all tests are loaded at once
+	srcs := []string{}
+	for _, tt := range tests {
+		srcs = append(srcs, tt.srcs...)
+	}
+
+	gens, _, errs := analyzeSources(t, srcs...)
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("package %d: %v", i, err)
+		}
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gens[2*i]
+			for dir, files := range gens[2*i+1].packages {
+				g.packages[dir] = files
+			}
+
+			_, err := g.buildOpenAPI()
+			if !errors.Is(err, ErrNameCollision) || !strings.Contains(err.Error(), tt.msg) {
+				t.Fatalf("expected %v containing %q, got %v", ErrNameCollision, tt.msg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package gen
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dave/jennifer/jen"
+)
+
+// paramKind describes how the raw value of a path, query, header or form parameter is parsed.
+type paramKind int
+
+const (
+	paramUnsupported paramKind = iota
+	paramString                // strings are used as they are
+	paramBasic                 // numbers and booleans are parsed by strconv
+	paramUUID
+	paramTime     // parsed according to the format tag
+	paramDuration // parsed by time.ParseDuration
+	paramText     // types implementing encoding.TextUnmarshaler
+)
+
+// textUnmarshaler is the type of encoding.TextUnmarshaler.
+var textUnmarshaler = types.NewInterfaceType([]*types.Func{
+	types.NewFunc(token.NoPos, nil, "UnmarshalText", types.NewSignatureType(nil, nil, nil,
+		types.NewTuple(types.NewVar(token.NoPos, nil, "text", types.NewSlice(types.Typ[types.Byte]))),
+		types.NewTuple(types.NewVar(token.NoPos, nil, "", types.Universe.Lookup("error").Type())),
+		false,
+	)),
+}, nil).Complete()
+
+// textMarshaler is the type of encoding.TextMarshaler.
+var textMarshaler = types.NewInterfaceType([]*types.Func{
+	types.NewFunc(token.NoPos, nil, "MarshalText", types.NewSignatureType(nil, nil, nil, nil,
+		types.NewTuple(
+			types.NewVar(token.NoPos, nil, "text", types.NewSlice(types.Typ[types.Byte])),
+			types.NewVar(token.NoPos, nil, "err", types.Universe.Lookup("error").Type()),
+		),
+		false,
+	)),
+}, nil).Complete()
+
+func paramKindOf(t types.Type) paramKind {
+	switch t.String() {
+	case "github.com/google/uuid.UUID":
+		return paramUUID
+	case "time.Time":
+		return paramTime
+	case "time.Duration":
+		return paramDuration
+	}
+
+	// enums implementing UnmarshalText are checked by it, even if they are strings
+	if _, ok := t.Underlying().(*types.Interface); !ok && types.Implements(types.NewPointer(t), textUnmarshaler) {
+		return paramText
+	}
+
+	switch basicName(t) {
+	case "":
+		return paramUnsupported
+	case "string":
+		return paramString
+	}
+
+	return paramBasic
+}
+
+// timeLayout returns the layout of a format tag of a time.Time parameter. The unix formats have no layout.
+func timeLayout(format string) (string, bool) {
+	switch strings.ToLower(format) {
+	case "", "rfc3339":
+		return time.RFC3339, true
+	case "date":
+		return time.DateOnly, true
+	case "unix", "unixmilli":
+		return "", false
+	}
+
+	return format, true
+}
+
+// checkTimeFormat reports an error if format is neither a known format nor a layout of the time package.
+func checkTimeFormat(format string) error {
+	switch strings.ToLower(format) {
+	case "", "rfc3339", "date", "unix", "unixmilli":
+		return nil
+	}
+
+	// a layout without any element would only accept itself
+	if time.Unix(0, 0).UTC().Format(format) == format {
+		return fmt.Errorf("format %q is neither rfc3339, date, unix, unixmilli nor a time layout", format)
+	}
+
+	return nil
+}
+
+// paramTypeInfo describes how parameters which are not sent as their Go type are sent, e.g. durations as strings like 1h30m.
+// It returns nil for all other parameters.
+func paramTypeInfo(kind paramKind, format string) *TypeInfo {
+	switch kind {
+	case paramTime:
+		if _, ok := timeLayout(format); !ok {
+			return &TypeInfo{Kind: KindInt, Name: "int64"}
+		}
+
+		return &TypeInfo{Kind: KindTime, Name: "time.Time", Format: strings.ToLower(format)}
+	case paramDuration:
+		return &TypeInfo{Kind: KindString, Format: "duration"}
+	case paramText:
+		return &TypeInfo{Kind: KindString}
+	}
+
+	return nil
+}
+
+// parseCall returns the call which parses the raw value of a parameter of type t. If the call does not result in t,
+// e.g. for int32 or named number types, convert is true and the result must be converted. Strings are not parsed,
+// parse is nil for them.
+func (g *Generator) parseCall(t types.Type, format string, raw string) (parse jen.Code, convert bool) {
+	switch paramKindOf(t) {
+	case paramString:
+		return nil, !types.Identical(t, types.Typ[types.String])
+	case paramUUID:
+		return jen.Qual("github.com/google/uuid", "Parse").Call(jen.Id(raw)), false
+	case paramTime:
+		switch strings.ToLower(format) {
+		case "", "rfc3339":
+			return jen.Qual("time", "Parse").Call(jen.Qual("time", "RFC3339"), jen.Id(raw)), false
+		case "date":
+			return jen.Qual("time", "Parse").Call(jen.Qual("time", "DateOnly"), jen.Id(raw)), false
+		case "unix":
+			return jen.Qual(exoPkgPath, "ParseUnix").Call(jen.Id(raw)), false
+		case "unixmilli":
+			return jen.Qual(exoPkgPath, "ParseUnixMilli").Call(jen.Id(raw)), false
+		}
+
+		return jen.Qual("time", "Parse").Call(jen.Lit(format), jen.Id(raw)), false
+	case paramDuration:
+		return jen.Qual("time", "ParseDuration").Call(jen.Id(raw)), false
+	case paramText:
+		return jen.Qual(exoPkgPath, "ParseText").Types(typeCode(t)).Call(jen.Id(raw)), false
+	}
+
+	dataType := basicName(t)
+	result := func(name string) bool {
+		return !types.Identical(t, types.Universe.Lookup(name).Type())
+	}
+
+	switch {
+	case dataType == "bool":
+		return jen.Qual("strconv", "ParseBool").Call(jen.Id(raw)), result("bool")
+	case dataType == "int":
+		return jen.Qual("strconv", "Atoi").Call(jen.Id(raw)), result("int")
+	case strings.HasPrefix(dataType, "int"):
+		return jen.Qual("strconv", "ParseInt").Call(jen.Id(raw), jen.Lit(10), jen.Lit(numberBits(dataType, "int"))), result("int64")
+	case strings.HasPrefix(dataType, "uint"):
+		return jen.Qual("strconv", "ParseUint").Call(jen.Id(raw), jen.Lit(10), jen.Lit(numberBits(dataType, "uint"))), result("uint64")
+	case strings.HasPrefix(dataType, "float"):
+		return jen.Qual("strconv", "ParseFloat").Call(jen.Id(raw), jen.Lit(numberBits(dataType, "float"))), result("float64")
+	}
+
+	panic(ErrUnsupportedFieldType)
+}
+
+// numberBits returns the size of a number type like int32 for the bitSize parameters of strconv. Plain int and uint result in 0.
+func numberBits(dataType, prefix string) int {
+	if dataType == prefix {
+		return 0
+	}
+
+	b, err := strconv.Atoi(strings.TrimPrefix(dataType, prefix))
+	if err != nil {
+		panic(ErrInvalidNumberBits)
+	}
+	return b
+}
+
+// parseDescription describes the values parseCall accepts for error messages, e.g. "an integer".
+func parseDescription(t types.Type, format string) string {
+	switch paramKindOf(t) {
+	case paramUUID:
+		return "a valid UUID"
+	case paramTime:
+		switch strings.ToLower(format) {
+		case "", "rfc3339":
+			return "an RFC 3339 timestamp"
+		case "date":
+			return "a date like 2006-01-02"
+		case "unix":
+			return "a unix timestamp in seconds"
+		case "unixmilli":
+			return "a unix timestamp in milliseconds"
+		}
+
+		return "a time like " + format
+	case paramDuration:
+		return "a duration like 1h30m"
+	case paramText:
+		name := t.String()
+		if named, ok := types.Unalias(t).(*types.Named); ok {
+			name = named.Obj().Name()
+		}
+
+		return "a valid " + name
+	}
+
+	switch dataType := basicName(t); {
+	case dataType == "bool":
+		return "a boolean"
+	case strings.HasPrefix(dataType, "uint"):
+		return "a non-negative integer"
+	case strings.HasPrefix(dataType, "int"):
+		return "an integer"
+	}
+
+	return "a number"
+}
@@ -0,0 +1,137 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckTimeFormat(t *testing.T) {
+	tests := []struct {
+		format string
+		layout string // empty for the unix formats
+		err    bool
+	}{
+		{"", "2006-01-02T15:04:05Z07:00", false},
+		{"RFC3339", "2006-01-02T15:04:05Z07:00", false},
+		{"date", "2006-01-02", false},
+		{"unix", "", false},
+		{"UnixMilli", "", false},
+		{"02.01.2006", "02.01.2006", false},
+		{"15:04", "15:04", false},
+		{"iso", "", true},
+		{"yyyy-mm-dd", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			err := checkTimeFormat(tt.format)
+			if tt.err {
+				if err == nil || !strings.Contains(err.Error(), "is neither rfc3339, date, unix, unixmilli nor a time layout") {
+					t.Fatalf("expected the format to be rejected, got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			layout, ok := timeLayout(tt.format)
+			if layout != tt.layout || ok != (tt.layout != "") {
+				t.Errorf("expected layout %q, got %q, %v", tt.layout, layout, ok)
+			}
+		})
+	}
+}
+
+func TestGenerateTimeParameters(t *testing.T) {
+	code := generateSources(t, `package p
+
+import (
+	"errors"
+	"time"
+
+	"github.com/exo-framework/exo"
+)
+
+type Level int
+
+func (l *Level) UnmarshalText(text []byte) error {
+	if string(text) != "low" {
+		return errors.New("unknown level")
+	}
+	return nil
+}
+
+type Req struct {
+	exo.Get `+"`route:\"/\"`"+`
+	At      time.Time      `+"`query:\"at\"`"+`
+	Day     time.Time      `+"`query:\"day\" format:\"date\"`"+`
+	Unix    time.Time      `+"`query:\"unix\" format:\"unix\"`"+`
+	Milli   *time.Time     `+"`header:\"X-Milli\" format:\"unixmilli\"`"+`
+	Clock   time.Time      `+"`query:\"clock\" format:\"15:04\"`"+`
+	Wait    time.Duration  `+"`query:\"wait\" default:\"1m\" validate:\"max=1h\"`"+`
+	Level   Level          `+"`query:\"level\" default:\"low\"`"+`
+	Levels  []Level        `+"`query:\"levels\" sep:\",\"`"+`
+}
+
+func handle(Req) error {
+	return nil
+}
+`)[0]
+
+	tests := []struct {
+		name     string
+		snippets []string
+	}{
+		{"rfc3339", []string{`time.Parse(time.RFC3339, raw_At)`, `"at must be an RFC 3339 timestamp"`}},
+		{"date", []string{`time.Parse(time.DateOnly, raw_Day)`, `"day must be a date like 2006-01-02"`}},
+		{"unix", []string{`exo.ParseUnix(raw_Unix)`, `"unix must be a unix timestamp in seconds"`}},
+		{"optional unix milliseconds", []string{`exo.ParseUnixMilli(raw_Milli)`, `q_Milli = &p_Milli`}},
+		{"layout", []string{`time.Parse("15:04", raw_Clock)`, `"clock must be a time like 15:04"`}},
+		{"duration", []string{`time.ParseDuration(raw_Wait)`, `if q_Wait > 3600000000000 {`, `"wait must be at most 1h"`}},
+		{"text unmarshaler", []string{`raw_Level := c.Query("level", "low")`, `exo.ParseText[Level](raw_Level)`, `"level must be a valid Level"`}},
+		{"text unmarshaler elements", []string{`exo.ParseText[Level](s_Levels)`, `q_Levels = append(q_Levels, p_Levels)`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			containsAll(t, code, tt.snippets...)
+		})
+	}
+}
+
+func TestAnalyzeTimeParameters(t *testing.T) {
+	src := func(field string) string {
+		return `package p
+
+import (
+	"time"
+
+	"github.com/exo-framework/exo"
+)
+
+var _ time.Time
+
+type Req struct {
+	exo.Post ` + "`route:\"/\"`" + `
+	` + field + `
+}
+
+func handle(Req) error {
+	return nil
+}
+`
+	}
+
+	analyzeErrors(t, []analyzeTest{
+		{name: "layout", src: src("V time.Time `query:\"v\" format:\"2006-01\"`")},
+		{name: "duration bound", src: src("V time.Duration `query:\"v\" validate:\"min=1s,max=90m\"`")},
+		{name: "format of a duration", src: src("V time.Duration `query:\"v\" format:\"date\"`"), err: ErrUnsupportedFieldType, msg: "format can only be used for time.Time"},
+		{name: "format of a string", src: src("V string `query:\"v\" format:\"date\"`"), err: ErrUnsupportedFieldType, msg: "format can only be used for time.Time"},
+		{name: "unknown format", src: src("V time.Time `query:\"v\" format:\"iso\"`"), err: ErrUnsupportedFieldType, msg: `format "iso" is neither rfc3339, date, unix, unixmilli nor a time layout`},
+		{name: "format of a body", src: src("V time.Time `body:\"\" format:\"date\"`"), err: ErrUnsupportedFieldType, msg: "only path, query, header, cookie and form parameters can have a format"},
+		{name: "invalid default of a layout", src: src("V time.Time `query:\"v\" format:\"2006-01\" default:\"2024\"`"), err: ErrInvalidDefault},
+		{name: "unsupported type", src: src("V struct{ A int } `query:\"v\"`"), err: ErrUnsupportedFieldType, msg: "cannot be parsed from query"},
+	})
+}
@@ -0,0 +1,107 @@
+package gen
+
+import (
+	"go/types"
+	"strconv"
+
+	"github.com/dave/jennifer/jen"
+)
+
+// typeCode renders a type checked type. Named types of other packages are qualified with their import path, so the generated
+// files import them on their own no matter how the requests file imports them.
+func typeCode(t types.Type) *jen.Statement {
+	switch t := t.(type) {
+	case *types.Alias:
+		if t.Obj().Pkg() == nil {
+			return jen.Id(t.Obj().Name())
+		}
+
+		return jen.Qual(t.Obj().Pkg().Path(), t.Obj().Name())
+	case *types.Basic:
+		return jen.Id(t.Name())
+	case *types.Named:
+		code := jen.Id(t.Obj().Name())
+		if t.Obj().Pkg() != nil {
+			code = jen.Qual(t.Obj().Pkg().Path(), t.Obj().Name())
+		}
+
+		if t.TypeArgs().Len() > 0 {
+			args := []jen.Code{}
+			for i := 0; i < t.TypeArgs().Len(); i++ {
+				args = append(args, typeCode(t.TypeArgs().At(i)))
+			}
+
+			code = code.Types(args...)
+		}
+
+		return code
+	case *types.Pointer:
+		return jen.Op("*").Add(typeCode(t.Elem()))
+	case *types.Slice:
+		return jen.Index().Add(typeCode(t.Elem()))
+	case *types.Array:
+		return jen.Index(jen.Lit(int(t.Len()))).Add(typeCode(t.Elem()))
+	case *types.Map:
+		return jen.Map(typeCode(t.Key())).Add(typeCode(t.Elem()))
+	case *types.Interface:
+		if t.Empty() {
+			return jen.Any()
+		}
+	case *types.Struct:
+		fields := []jen.Code{}
+		for i := 0; i < t.NumFields(); i++ {
+			field := t.Field(i)
+
+			code := jen.Id(field.Name()).Add(typeCode(field.Type()))
+			if field.Embedded() {
+				code = typeCode(field.Type())
+			}
+
+			// the tag is part of the type identity, so it is kept as it is instead of being rebuilt by jen.Tag
+			if tag := t.Tag(i); strconv.CanBackquote(tag) && tag != "" {
+				code = code.Op("`" + tag + "`")
+			} else if tag != "" {
+				code = code.Lit(tag)
+			}
+
+			fields = append(fields, code)
+		}
+
+		return jen.Struct(fields...)
+	case *types.TypeParam:
+		return jen.Id(t.Obj().Name())
+	}
+
+	return jen.Id(types.TypeString(t, func(p *types.Package) string { return p.Name() }))
+}
+
+// zeroValue declares name as a new value of t. Pointers point to a new empty value.
+func zeroValue(name string, t types.Type) jen.Code {
+	if ptr, ok := t.(*types.Pointer); ok {
+		return jen.Id(name).Op(":=").Add(newValue(ptr.Elem()))
+	}
+
+	if isComposite(t) {
+		return jen.Id(name).Op(":=").Add(typeCode(t)).Values()
+	}
+
+	return jen.Var().Id(name).Add(typeCode(t))
+}
+
+// newValue returns a pointer to a new empty value of t.
+func newValue(t types.Type) jen.Code {
+	if isComposite(t) {
+		return jen.Op("&").Add(typeCode(t)).Values()
+	}
+
+	return jen.New(typeCode(t))
+}
+
+func isComposite(t types.Type) bool {
+	switch t.Underlying().(type) {
+	case *types.Struct, *types.Slice, *types.Map, *types.Array:
+		return true
+	}
+
+	return false
+}
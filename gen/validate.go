@@ -0,0 +1,516 @@
+package gen
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"go/types"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dave/jennifer/jen"
+)
+
+// parseValidateTag parses a validate tag like "min=3,max=64,email". A name which is no built-in rule refers to a validator function of the
+// package. A regex rule takes the rest of the tag, so commas may be used in the expression, e.g. "min=1,regex=^[a-z]{2,8}$".
+// The format rules email, url, uuid, regex and oneof accept empty strings, notempty has to be added to require a value.
+// The rules after dive are applied to the elements of a slice, e.g. "max=10,dive,email".
+// If strict is false, unknown rules are ignored instead of being reported, which is used for the nested fields of bodies as their tags
+// may be meant for other validation libraries.
+func parseValidateTag(value string, strict bool) ([]ValidationRule, *string, error) {
+	rules := []ValidationRule{}
+	var validator *string
+
+	for value != "" {
+		part := value
+		if strings.HasPrefix(value, "regex=") {
+			value = ""
+		} else {
+			part, value, _ = strings.Cut(value, ",")
+		}
+
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, param, hasParam := strings.Cut(part, "=")
+
+		switch rule := strings.ToLower(name); rule {
+		case "min", "max", "gte", "lte", "gt", "lt":
+			// durations are checked by checkRules, as they can only be compared with time.Duration
+			if _, err := strconv.ParseFloat(param, 64); err != nil {
+				if _, err := time.ParseDuration(param); err != nil {
+					return nil, nil, fmt.Errorf("%s: %q is not a number", rule, param)
+				}
+			}
+		case "oneof":
+			if param == "" {
+				return nil, nil, errors.New("oneof: no values given")
+			}
+		case "regex":
+			if _, err := regexp.Compile(param); err != nil {
+				return nil, nil, fmt.Errorf("regex: %w", err)
+			}
+		case "email", "url", "uuid", "notempty", "dive":
+			if hasParam {
+				return nil, nil, fmt.Errorf("%s: takes no parameter", rule)
+			}
+		default:
+			if !strict {
+				continue
+			}
+
+			if hasParam || !token.IsIdentifier(name) {
+				return nil, nil, fmt.Errorf("unknown rule %q", part)
+			}
+
+			if validator != nil {
+				return nil, nil, fmt.Errorf("only one validator function is allowed, got %s and %s", *validator, name)
+			}
+
+			validator = &name
+			continue
+		}
+
+		rules = append(rules, ValidationRule{Name: strings.ToLower(name), Param: param})
+	}
+
+	return rules, validator, nil
+}
+
+// splitDive splits rules at dive into the rules of a slice and the rules of its elements.
+func splitDive(rules []ValidationRule) (own, elem []ValidationRule) {
+	for i, rule := range rules {
+		if rule.Name == "dive" {
+			return rules[:i], rules[i+1:]
+		}
+	}
+
+	return rules, nil
+}
+
+// checkRules reports an error if a rule cannot be applied to a value of type t. Rules of pointers are applied to the value pointed to,
+// except for notempty, which requires the pointer to be set.
+func checkRules(t types.Type, rules []ValidationRule) error {
+	pointer := false
+	if ptr, ok := t.Underlying().(*types.Pointer); ok {
+		t = ptr.Elem()
+		pointer = true
+	}
+
+	basic := basicName(t)
+	isNumber := basic != "" && basic != "string" && basic != "bool"
+	isInteger := isNumber && !strings.HasPrefix(basic, "float")
+
+	for i, rule := range rules {
+		if rule.Name == "dive" {
+			var elem types.Type
+			switch u := t.Underlying().(type) {
+			case *types.Slice:
+				elem = u.Elem()
+			case *types.Array:
+				elem = u.Elem()
+			default:
+				return fmt.Errorf("rule dive cannot be applied to %s", t)
+			}
+
+			return checkRules(elem, rules[i+1:])
+		}
+
+		ok := false
+		switch rule.Name {
+		case "notempty":
+			// pointers must not be nil, other values must not be their zero value. Bools and structs have no empty value besides it
+			ok = pointer || isNumber || basic == "string" || isCollection(t) || t.String() == "github.com/google/uuid.UUID"
+		case "min", "max":
+			ok = isNumber || basic == "string" || isCollection(t)
+		case "gte", "lte", "gt", "lt":
+			ok = isNumber
+		case "oneof":
+			ok = isNumber || basic == "string"
+		case "email", "url", "uuid", "regex":
+			ok = basic == "string"
+		}
+
+		if !ok {
+			return fmt.Errorf("rule %s cannot be applied to %s", rule.Name, t)
+		}
+
+		switch rule.Name {
+		case "min", "max", "gte", "lte", "gt", "lt":
+			// durations may be compared with durations like 1m30s as well
+			if _, err := time.ParseDuration(rule.Param); err == nil && paramKindOf(t) == paramDuration {
+				continue
+			}
+
+			// lengths are integers as well
+			if err := checkNumber(rule, rule.Param, isInteger || !isNumber, strings.HasPrefix(basic, "uint") || !isNumber, bitSize(basic), t); err != nil {
+				return err
+			}
+		case "oneof":
+			if basic == "string" {
+				continue
+			}
+
+			for _, param := range strings.Split(rule.Param, "|") {
+				if err := checkNumber(rule, param, isInteger, strings.HasPrefix(basic, "uint"), bitSize(basic), t); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkNumber reports an error if param is no constant of the number type t, which the generated code could not compile,
+// e.g. 300 for int8 or NaN for float64.
+func checkNumber(rule ValidationRule, param string, integer, unsigned bool, bits int, t types.Type) error {
+	var err error
+	switch {
+	case unsigned:
+		_, err = strconv.ParseUint(param, 10, bits)
+	case integer:
+		_, err = strconv.ParseInt(param, 10, bits)
+	default:
+		var f float64
+		if f, err = strconv.ParseFloat(param, bits); err == nil && (math.IsNaN(f) || math.IsInf(f, 0)) {
+			err = strconv.ErrSyntax
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("rule %s: %q cannot be compared with %s", rule.Name, param, t)
+	}
+
+	return nil
+}
+
+// bitSize returns the size of the basic number type in bits. Lengths and int are 64 bits.
+func bitSize(basic string) int {
+	switch basic {
+	case "int8", "uint8":
+		return 8
+	case "int16", "uint16":
+		return 16
+	case "int32", "uint32", "float32":
+		return 32
+	}
+
+	return 64
+}
+
+func isCollection(t types.Type) bool {
+	switch t.Underlying().(type) {
+	case *types.Slice, *types.Array, *types.Map:
+		return true
+	}
+
+	return false
+}
+
+// fieldPath is the path of a field in the errors of a request, e.g. addresses[0].zip. Parts are either strings or expressions which
+// are evaluated at runtime, like the indices of slices. The path of a body itself is empty.
+type fieldPath []any
+
+// child returns the path of the field name of the value at p.
+func (p fieldPath) child(name string) fieldPath {
+	if len(p) == 0 {
+		return fieldPath{name}
+	}
+
+	return p.append("." + name)
+}
+
+// index returns the path of the element of the slice at p whose index is stored in the variable index.
+func (p fieldPath) index(index string) fieldPath {
+	return p.append("[", jen.Qual("strconv", "Itoa").Call(jen.Id(index)), "]")
+}
+
+// depth returns the number of indices in the path, which is used to name the index variables of nested loops.
+func (p fieldPath) depth() int {
+	depth := 0
+	for _, part := range p {
+		if _, ok := part.(string); !ok {
+			depth++
+		}
+	}
+
+	return depth
+}
+
+func (p fieldPath) append(parts ...any) fieldPath {
+	path := append(fieldPath{}, p...)
+	for _, part := range parts {
+		// adjacent strings are merged, so the generated code concatenates as few strings as possible
+		if s, ok := part.(string); ok && len(path) > 0 {
+			if last, ok := path[len(path)-1].(string); ok {
+				path[len(path)-1] = last + s
+				continue
+			}
+		}
+
+		path = append(path, part)
+	}
+
+	return path
+}
+
+// code returns an expression evaluating to the path with suffix appended.
+func (p fieldPath) code(suffix string) *jen.Statement {
+	path := p.append(suffix)
+	if len(path) == 0 {
+		return jen.Lit("")
+	}
+
+	code := &jen.Statement{}
+	for i, part := range path {
+		if i > 0 {
+			code.Op("+")
+		}
+
+		if s, ok := part.(string); ok {
+			code.Lit(s)
+		} else {
+			code.Add(part.(jen.Code))
+		}
+	}
+
+	return code
+}
+
+// message returns an expression evaluating to an error message about the field, e.g. "name must not be empty".
+func (p fieldPath) message(suffix string) *jen.Statement {
+	if len(p) == 0 {
+		return jen.Lit("body" + suffix)
+	}
+
+	return p.code(suffix)
+}
+
+// addFieldError adds an error about the field at path to the errors of the request.
+func addFieldError(path fieldPath, source, code, suffix string) jen.Code {
+	return jen.Id("v_errs").Dot("Add").Call(path.code(""), jen.Lit(source), jen.Lit(code), path.message(suffix))
+}
+
+// generateRules generates the checks of the rules for value, which is of type t. Failing checks add an error with the name of the
+// rule as code to the errors of the request. Regular expressions are compiled once, their package level variables are added to regexes.
+func (g *Generator) generateRules(value *jen.Statement, path fieldPath, source string, t types.Type, rules []ValidationRule, regexes *[]jen.Code, prefix string) []jen.Code {
+	codes := []jen.Code{}
+
+	fail := func(cond jen.Code, code, suffix string) {
+		codes = append(codes,
+			jen.If(cond).Block(
+				addFieldError(path, source, code, suffix),
+			))
+	}
+
+	if ptr, ok := t.Underlying().(*types.Pointer); ok {
+		rest := []ValidationRule{}
+		for _, rule := range rules {
+			if rule.Name == "notempty" {
+				fail(value.Clone().Op("==").Nil(), "required", " must not be empty")
+				continue
+			}
+
+			rest = append(rest, rule)
+		}
+
+		inner := g.generateRules(jen.Parens(jen.Op("*").Add(value.Clone())), path, source, ptr.Elem(), rest, regexes, prefix)
+		if len(inner) > 0 {
+			codes = append(codes, jen.If(value.Clone().Op("!=").Nil()).Block(inner...))
+		}
+
+		return codes
+	}
+
+	basic := basicName(t)
+
+	// strings are passed to the checks as plain strings, named string types are converted
+	str := value.Clone()
+	if basic == "string" && !types.Identical(t, types.Typ[types.String]) {
+		str = jen.String().Call(value.Clone())
+	}
+
+	// format rules of strings are only checked if the string is not empty, use notempty to require a value
+	nonEmpty := func(cond *jen.Statement) jen.Code {
+		if basic != "string" {
+			return cond
+		}
+
+		return value.Clone().Op("!=").Lit("").Op("&&").Add(cond)
+	}
+
+	// number renders the bound of a comparison as untyped constant, durations like 1m are compared in nanoseconds.
+	// checkRules ensures the bound is a finite number fitting the type
+	number := func(param string) jen.Code {
+		if d, err := time.ParseDuration(param); err == nil && paramKindOf(t) == paramDuration {
+			return jen.Op(strconv.FormatInt(int64(d), 10))
+		}
+
+		if i, err := strconv.ParseInt(param, 10, 64); err == nil {
+			return jen.Op(strconv.FormatInt(i, 10))
+		}
+
+		if u, err := strconv.ParseUint(param, 10, 64); err == nil {
+			return jen.Op(strconv.FormatUint(u, 10))
+		}
+
+		f, _ := strconv.ParseFloat(param, 64)
+		return jen.Op(strconv.FormatFloat(f, 'g', -1, 64))
+	}
+
+	for i, rule := range rules {
+		switch rule.Name {
+		case "dive":
+			index := fmt.Sprintf("i_%d", path.depth())
+			elem := t.Underlying().(interface{ Elem() types.Type }).Elem()
+
+			inner := g.generateRules(value.Clone().Index(jen.Id(index)), path.index(index), source, elem, rules[i+1:], regexes, prefix)
+			if len(inner) > 0 {
+				codes = append(codes, jen.For(jen.Id(index).Op(":=").Range().Add(value.Clone())).Block(inner...))
+			}
+
+			return codes
+		case "notempty":
+			switch {
+			case basic == "string":
+				fail(value.Clone().Op("==").Lit(""), "required", " must not be empty")
+			case isCollection(t):
+				fail(jen.Len(value.Clone()).Op("==").Lit(0), "required", " must not be empty")
+			case basic != "" && basic != "bool":
+				fail(value.Clone().Op("==").Lit(0), "required", " must not be empty")
+			case basic == "" && t.String() == "github.com/google/uuid.UUID":
+				fail(value.Clone().Op("==").Qual("github.com/google/uuid", "Nil"), "required", " must not be empty")
+			}
+		case "min", "max":
+			op, bound := "<", "at least "
+			if rule.Name == "max" {
+				op, bound = ">", "at most "
+			}
+
+			switch {
+			case basic == "string":
+				fail(jen.Qual("unicode/utf8", "RuneCountInString").Call(str.Clone()).Op(op).Op(rule.Param), rule.Name, " must be "+bound+rule.Param+" characters long")
+			case isCollection(t):
+				items := " items"
+				if rule.Param == "1" {
+					items = " item"
+				}
+
+				fail(jen.Len(value.Clone()).Op(op).Op(rule.Param), rule.Name, " must contain "+bound+rule.Param+items)
+			default:
+				fail(value.Clone().Op(op).Add(number(rule.Param)), rule.Name, " must be "+bound+rule.Param)
+			}
+		case "gte":
+			fail(value.Clone().Op("<").Add(number(rule.Param)), rule.Name, " must be at least "+rule.Param)
+		case "lte":
+			fail(value.Clone().Op(">").Add(number(rule.Param)), rule.Name, " must be at most "+rule.Param)
+		case "gt":
+			fail(value.Clone().Op("<=").Add(number(rule.Param)), rule.Name, " must be greater than "+rule.Param)
+		case "lt":
+			fail(value.Clone().Op(">=").Add(number(rule.Param)), rule.Name, " must be less than "+rule.Param)
+		case "oneof":
+			values := strings.Split(rule.Param, "|")
+			conds := []jen.Code{}
+			for i, v := range values {
+				lit := number(v)
+				if basic == "string" {
+					lit = jen.Lit(v)
+				}
+
+				cond := value.Clone().Op("!=").Add(lit)
+				if i > 0 {
+					cond = jen.Op("&&").Add(cond)
+				}
+				conds = append(conds, cond)
+			}
+
+			fail(nonEmpty(jen.Parens(jen.Add(conds...))), rule.Name, " must be one of "+strings.Join(values, ", "))
+		case "email":
+			fail(nonEmpty(jen.Op("!").Qual(exoPkgPath, "IsEmail").Call(str.Clone())), rule.Name, " must be a valid email address")
+		case "url":
+			fail(nonEmpty(jen.Op("!").Qual(exoPkgPath, "IsURL").Call(str.Clone())), rule.Name, " must be a valid URL")
+		case "uuid":
+			fail(nonEmpty(jen.Qual("github.com/google/uuid", "Validate").Call(str.Clone()).Op("!=").Nil()), rule.Name, " must be a valid UUID")
+		case "regex":
+			regex := fmt.Sprintf("%s_%d", prefix, len(*regexes))
+			*regexes = append(*regexes, jen.Id(regex).Op("=").Qual("regexp", "MustCompile").Call(jen.Lit(rule.Param)))
+
+			fail(nonEmpty(jen.Op("!").Id(regex).Dot("MatchString").Call(str.Clone())), rule.Name, " must match "+rule.Param)
+		}
+	}
+
+	return codes
+}
+
+// generateNestedRules generates the checks of the validate tags of the fields of a body value, descending into nested structs,
+// pointers, slices and arrays.
+func (g *Generator) generateNestedRules(value *jen.Statement, path fieldPath, source string, t types.Type, depth int, regexes *[]jen.Code, prefix string, seen map[string]bool) []jen.Code {
+	switch u := t.Underlying().(type) {
+	case *types.Pointer:
+		codes := g.generateNestedRules(value, path, source, u.Elem(), depth, regexes, prefix, seen)
+		if len(codes) == 0 {
+			return nil
+		}
+
+		return []jen.Code{jen.If(value.Clone().Op("!=").Nil()).Block(codes...)}
+	case *types.Slice, *types.Array:
+		elem := u.(interface{ Elem() types.Type }).Elem()
+		index := fmt.Sprintf("i_%d", depth)
+
+		codes := g.generateNestedRules(value.Clone().Index(jen.Id(index)), path.index(index), source, elem, depth+1, regexes, prefix, seen)
+		if len(codes) == 0 {
+			return nil
+		}
+
+		return []jen.Code{jen.For(jen.Id(index).Op(":=").Range().Add(value.Clone())).Block(codes...)}
+	case *types.Struct:
+		key := types.TypeString(t, nil)
+		if seen[key] {
+			// recursive types are only validated up to the first repetition
+			return nil
+		}
+
+		seen[key] = true
+		defer delete(seen, key)
+
+		codes := []jen.Code{}
+		for i := 0; i < u.NumFields(); i++ {
+			field := u.Field(i)
+			if !field.Exported() {
+				continue
+			}
+
+			tag := reflect.StructTag(u.Tag(i))
+			jsonName, jsonOpts, _ := strings.Cut(tag.Get("json"), ",")
+			if jsonName == "-" && jsonOpts == "" {
+				continue
+			}
+
+			childPath := path.child(field.Name())
+			if jsonName != "" {
+				childPath = path.child(jsonName)
+			} else if field.Embedded() {
+				childPath = path
+			}
+
+			fieldValue := value.Clone().Dot(field.Name())
+
+			if rules, _, err := parseValidateTag(tag.Get("validate"), false); err == nil {
+				codes = append(codes, g.generateRules(fieldValue, childPath, source, field.Type(), rules, regexes, prefix)...)
+			}
+
+			codes = append(codes, g.generateNestedRules(fieldValue, childPath, source, field.Type(), depth, regexes, prefix, seen)...)
+		}
+
+		return codes
+	}
+
+	return nil
+}
@@ -0,0 +1,205 @@
+package gen
+
+import (
+	"go/types"
+	"strings"
+	"testing"
+)
+
+func TestParseValidateTag(t *testing.T) {
+	tests := []struct {
+		name      string
+		tag       string
+		strict    bool
+		rules     string // rules as name=param separated by spaces
+		validator string
+		err       string
+	}{
+		{"empty", "", true, "", "", ""},
+		{"rules", "min=3, max=64,email", true, "min=3 max=64 email=", "", ""},
+		{"case of rule names", "NotEmpty,UUID", true, "notempty= uuid=", "", ""},
+		{"oneof", "oneof=a|b|c", true, "oneof=a|b|c", "", ""},
+		{"regex takes the rest", "min=1,regex=^[a-z]{2,8}$", true, "min=1 regex=^[a-z]{2,8}$", "", ""},
+		{"dive", "max=10,dive,url", true, "max=10 dive= url=", "", ""},
+		{"duration bound", "max=1m30s", true, "max=1m30s", "", ""},
+		{"validator function", "notempty,onValidator", true, "notempty=", "onValidator", ""},
+		{"two validator functions", "a,b", true, "", "", "only one validator function is allowed, got a and b"},
+		{"unknown rule with parameter", "len=3", true, "", "", `unknown rule "len=3"`},
+		{"unknown rule of nested field", "required,len=3,min=1", false, "min=1", "", ""},
+		{"bound without number", "min=x", true, "", "", `min: "x" is not a number`},
+		{"oneof without values", "oneof=", true, "", "", "oneof: no values given"},
+		{"invalid regex", "regex=[", true, "", "", "regex: error parsing regexp"},
+		{"parameter of a format rule", "email=1", true, "", "", "email: takes no parameter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, validator, err := parseValidateTag(tt.tag, tt.strict)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := []string{}
+			for _, rule := range rules {
+				got = append(got, rule.Name+"="+rule.Param)
+			}
+
+			if strings.Join(got, " ") != tt.rules {
+				t.Errorf("expected rules %q, got %q", tt.rules, strings.Join(got, " "))
+			}
+
+			if (validator == nil && tt.validator != "") || (validator != nil && *validator != tt.validator) {
+				t.Errorf("expected validator %q, got %v", tt.validator, validator)
+			}
+		})
+	}
+}
+
+func TestCheckRules(t *testing.T) {
+	uuidType := types.NewNamed(types.NewTypeName(0, types.NewPackage("github.com/google/uuid", "uuid"), "UUID", nil), types.NewArray(types.Typ[types.Byte], 16), nil)
+	durationType := types.NewNamed(types.NewTypeName(0, types.NewPackage("time", "time"), "Duration", nil), types.Typ[types.Int64], nil)
+
+	tests := []struct {
+		name string
+		t    types.Type
+		tag  string
+		err  string
+	}{
+		{"string rules", types.Typ[types.String], "notempty,min=1,max=5,oneof=a|b,email,url,uuid,regex=^a", ""},
+		{"number rules", types.Typ[types.Int], "notempty,min=1,max=5,gte=1,lte=5,gt=0,lt=6,oneof=1|2", ""},
+		{"rules of a pointer", types.NewPointer(types.Typ[types.String]), "notempty,email", ""},
+		{"notempty of a uuid", uuidType, "notempty", ""},
+		{"notempty of a bool", types.Typ[types.Bool], "notempty", "rule notempty cannot be applied to bool"},
+		{"notempty of a bool pointer", types.NewPointer(types.Typ[types.Bool]), "notempty", ""},
+		{"email of a number", types.Typ[types.Int], "email", "rule email cannot be applied to int"},
+		{"gte of a string", types.Typ[types.String], "gte=1", "rule gte cannot be applied to string"},
+		{"length of a map", types.NewMap(types.Typ[types.String], types.Typ[types.Int]), "min=1,max=2", ""},
+		{"oneof of a slice", types.NewSlice(types.Typ[types.String]), "oneof=a", "rule oneof cannot be applied to []string"},
+		{"dive of a slice", types.NewSlice(types.Typ[types.String]), "max=3,dive,email", ""},
+		{"rules of the elements", types.NewSlice(types.Typ[types.Int]), "dive,email", "rule email cannot be applied to int"},
+		{"dive of a string", types.Typ[types.String], "dive,min=1", "rule dive cannot be applied to string"},
+		{"duration bound", durationType, "min=1s,max=1h", ""},
+		{"duration bound of an int", types.Typ[types.Int], "max=1h", `rule max: "1h" cannot be compared with int`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, _, err := parseValidateTag(tt.tag, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = checkRules(tt.t, rules)
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("expected error %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestCheckRulesBounds(t *testing.T) {
+	tests := []struct {
+		name  string
+		t     types.Type
+		tag   string
+		valid bool
+	}{
+		{"int bound", types.Typ[types.Int], "min=1,max=10", true},
+		{"negative bound", types.Typ[types.Int], "gte=-5", true},
+		{"float bound of int", types.Typ[types.Int], "max=1.5", false},
+		{"int8 overflow", types.Typ[types.Int8], "max=300", false},
+		{"uint8 bound", types.Typ[types.Uint8], "lte=255", true},
+		{"negative bound of uint", types.Typ[types.Uint], "gte=-1", false},
+		{"float bound", types.Typ[types.Float64], "gt=0.5,lt=1e3", true},
+		{"float32 overflow", types.Typ[types.Float32], "max=1e39", false},
+		{"float overflow", types.Typ[types.Float64], "max=1e400", false},
+		{"NaN", types.Typ[types.Float64], "min=NaN", false},
+		{"Inf", types.Typ[types.Float64], "max=Inf", false},
+		{"negative Inf", types.Typ[types.Float64], "min=-Inf", false},
+		{"NaN in oneof", types.Typ[types.Float64], "oneof=1|NaN", false},
+		{"int8 overflow in oneof", types.Typ[types.Int8], "oneof=1|128", false},
+		{"string length", types.Typ[types.String], "min=1,max=64", true},
+		{"float string length", types.Typ[types.String], "max=1.5", false},
+		{"negative string length", types.Typ[types.String], "min=-1", false},
+		{"slice length", types.NewSlice(types.Typ[types.Int]), "max=3,dive,gte=0", true},
+		{"slice element bound", types.NewSlice(types.Typ[types.Int8]), "dive,max=300", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, _, err := parseValidateTag(tt.tag, false)
+			if err == nil {
+				err = checkRules(tt.t, rules)
+			}
+
+			if tt.valid && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !tt.valid && err == nil {
+				t.Fatalf("expected %q to be rejected for %s", tt.tag, tt.t)
+			}
+		})
+	}
+}
+
+func TestGenerateRuleBounds(t *testing.T) {
+	code := generateSources(t, `package p
+
+import (
+	"time"
+
+	"github.com/exo-framework/exo"
+)
+
+type Get struct {
+	exo.Get `+"`route:\"/\"`"+`
+	N   int           `+"`query:\"n\" validate:\"min=1,max=10\"`"+`
+	Neg int           `+"`query:\"neg\" validate:\"gte=-5\"`"+`
+	F   float64       `+"`query:\"f\" validate:\"lt=1e3\"`"+`
+	F32 float32       `+"`query:\"f32\" validate:\"gt=0.5\"`"+`
+	U   uint64        `+"`query:\"u\" validate:\"max=18446744073709551615\"`"+`
+	O   int8          `+"`query:\"o\" validate:\"oneof=1|-2\"`"+`
+	D   time.Duration `+"`query:\"d\" validate:\"max=1m\"`"+`
+}
+
+func get(Get) error {
+	return nil
+}
+`)[0]
+
+	tests := []struct {
+		name string
+		code string
+	}{
+		{"int bounds", "q_N > 10"},
+		{"negative bound", "q_Neg < -5"},
+		{"float bound", "q_F >= 1000"},
+		{"float32 bound", "q_F32 <= 0.5"},
+		{"uint bound", "q_U > 18446744073709551615"},
+		{"oneof", "q_O != -2"},
+		{"duration bound", "q_D > 60000000000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(code, tt.code) {
+				t.Fatalf("expected %q in\n%s", tt.code, code)
+			}
+		})
+	}
+}
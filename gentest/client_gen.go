@@ -0,0 +1,236 @@
+// Code generated by exo. DO NOT EDIT.
+package gentest
+
+import (
+	"context"
+	"fmt"
+	exo "github.com/exo-framework/exo"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Client is a generated client for the routes of this package.
+type Client struct {
+	*exo.Client
+}
+
+// NewClient creates a new client for the routes of this package using the given connection settings.
+func NewClient(base *exo.Client) *Client {
+	return &Client{base}
+}
+
+// GetTest sends a GET request to /gentest/test/:id/:id2.
+func (cl *Client) GetTest(ctx context.Context, req GetTest) (string, error) {
+	creq := exo.ClientRequest{
+		Form:   url.Values{},
+		Header: http.Header{},
+		Method: "GET",
+		Path:   "/gentest/test/" + url.PathEscape(fmt.Sprint(req.Id)) + "/" + url.PathEscape(fmt.Sprint(req.Id2)),
+		Query:  url.Values{},
+	}
+	if req.Validator != "" {
+		creq.Header.Set("Validator", req.Validator)
+	}
+	if req.Theme != "" {
+		creq.Cookies = append(creq.Cookies, &http.Cookie{
+			Name:  "theme",
+			Value: req.Theme,
+		})
+	}
+	if req.Name != "" {
+		creq.Query.Set("name", req.Name)
+	}
+	if req.Name2 != "" {
+		creq.Query.Set("name2", req.Name2)
+	}
+	creq.Query.Set("limit", fmt.Sprint(req.Limit))
+	if req.Page != nil {
+		creq.Query.Set("page", fmt.Sprint(*req.Page))
+	}
+	for _, v := range req.Tags {
+		creq.Query.Add("tag", v)
+	}
+	if req.Since != nil {
+		creq.Query.Set("since", req.Since.Format(time.DateOnly))
+	}
+	if req.Email != "" {
+		creq.Query.Set("email", req.Email)
+	}
+	if req.Form != "" {
+		creq.Query.Set("form", req.Form)
+	}
+	if req.FormNamed != "" {
+		creq.Query.Set("form_named", req.FormNamed)
+	}
+	var r_0 string
+	res, err := cl.Do(ctx, creq)
+	if err != nil {
+		return r_0, err
+	}
+	defer res.Body.Close()
+	if err := exo.ReadResponse(res, &r_0); err != nil {
+		return r_0, err
+	}
+	return r_0, nil
+}
+
+// DeleteTest sends a DELETE request to /gentest/test/:id.
+func (cl *Client) DeleteTest(ctx context.Context, req DeleteTest) error {
+	creq := exo.ClientRequest{
+		Form:   url.Values{},
+		Header: http.Header{},
+		Method: "DELETE",
+		Path:   "/gentest/test/" + url.PathEscape(fmt.Sprint(req.Id)),
+		Query:  url.Values{},
+	}
+	res, err := cl.Do(ctx, creq)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return nil
+}
+
+// LogoutTest sends a POST request to /gentest/logout.
+func (cl *Client) LogoutTest(ctx context.Context, req LogoutTest) (exo.Cookies, error) {
+	creq := exo.ClientRequest{
+		Form:   url.Values{},
+		Header: http.Header{},
+		Method: "POST",
+		Path:   "/gentest/logout",
+		Query:  url.Values{},
+	}
+	if req.Session != "" {
+		creq.Cookies = append(creq.Cookies, &http.Cookie{
+			Name:  "session",
+			Value: req.Session,
+		})
+	}
+	var r_0 exo.Cookies
+	res, err := cl.Do(ctx, creq)
+	if err != nil {
+		return r_0, err
+	}
+	defer res.Body.Close()
+	r_0 = exo.ResponseCookies(res)
+	return r_0, nil
+}
+
+// UploadTest sends a POST request to /gentest/upload.
+func (cl *Client) UploadTest(ctx context.Context, req UploadTest, file_avatar exo.ClientFile, file_attachments []exo.ClientFile) error {
+	creq := exo.ClientRequest{
+		Files:  map[string][]exo.ClientFile{},
+		Form:   url.Values{},
+		Header: http.Header{},
+		Method: "POST",
+		Path:   "/gentest/upload",
+		Query:  url.Values{},
+	}
+	if file_avatar.Content != nil {
+		creq.Files["avatar"] = []exo.ClientFile{file_avatar}
+	}
+	if len(file_attachments) > 0 {
+		creq.Files["attachments"] = file_attachments
+	}
+	if req.Title != "" {
+		creq.Form.Set("title", req.Title)
+	}
+	res, err := cl.Do(ctx, creq)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return nil
+}
+
+// GetDtoTest sends a GET request to /gentest/dto/:id.
+func (cl *Client) GetDtoTest(ctx context.Context, req GetDtoTest) (*GetTestDto, int, error) {
+	creq := exo.ClientRequest{
+		Form:   url.Values{},
+		Header: http.Header{},
+		Method: "GET",
+		Path:   "/gentest/dto/" + url.PathEscape(fmt.Sprint(req.Id)),
+		Query:  url.Values{},
+	}
+	var r_0 *GetTestDto
+	var r_1 int
+	res, err := cl.Do(ctx, creq)
+	if err != nil {
+		return r_0, r_1, err
+	}
+	defer res.Body.Close()
+	if err := exo.ReadResponse(res, &r_0); err != nil {
+		return r_0, r_1, err
+	}
+	r_1 = int(res.StatusCode)
+	return r_0, r_1, nil
+}
+
+// ExportTest sends a GET request to /gentest/export.
+func (cl *Client) ExportTest(ctx context.Context, req ExportTest) (exo.Serialize[[]GetTestDto], error) {
+	creq := exo.ClientRequest{
+		Form:   url.Values{},
+		Header: http.Header{},
+		Method: "GET",
+		Path:   "/gentest/export",
+		Query:  url.Values{},
+	}
+	creq.Header.Set("Accept", "application/json")
+	var r_0 exo.Serialize[[]GetTestDto]
+	res, err := cl.Do(ctx, creq)
+	if err != nil {
+		return r_0, err
+	}
+	defer res.Body.Close()
+	if err := exo.ReadResponse(res, &r_0.Value); err != nil {
+		return r_0, err
+	}
+	return r_0, nil
+}
+
+// DbTest sends a GET request to /gentest/models/:kind/:slug.
+func (cl *Client) DbTest(ctx context.Context, req DbTest) ([]SomeDbModel, error) {
+	creq := exo.ClientRequest{
+		Form:   url.Values{},
+		Header: http.Header{},
+		Method: "GET",
+		Path:   "/gentest/models/" + url.PathEscape(req.Kind) + "/" + url.PathEscape(req.Slug),
+		Query:  url.Values{},
+	}
+	var r_0 []SomeDbModel
+	res, err := cl.Do(ctx, creq)
+	if err != nil {
+		return r_0, err
+	}
+	defer res.Body.Close()
+	if err := exo.ReadResponse(res, &r_0); err != nil {
+		return r_0, err
+	}
+	return r_0, nil
+}
+
+// PutDtoTest sends a PUT request to /gentest/dto/:id.
+func (cl *Client) PutDtoTest(ctx context.Context, req PutDtoTest) (exo.Response[GetTestDto], error) {
+	creq := exo.ClientRequest{
+		Form:   url.Values{},
+		Header: http.Header{},
+		Method: "PUT",
+		Path:   "/gentest/dto/" + url.PathEscape(fmt.Sprint(req.Id)),
+		Query:  url.Values{},
+	}
+	creq.Body = req.Dto
+	var r_0 exo.Response[GetTestDto]
+	res, err := cl.Do(ctx, creq)
+	if err != nil {
+		return r_0, err
+	}
+	defer res.Body.Close()
+	r_0.Status = res.StatusCode
+	r_0.Header = res.Header
+	r_0.Cookies = exo.ResponseCookies(res)
+	if err := exo.ReadResponse(res, &r_0.Body); err != nil {
+		return r_0, err
+	}
+	return r_0, nil
+}
@@ -3,6 +3,14 @@ package gentest
 
 import v2 "github.com/gofiber/fiber/v2"
 
-func RegisterRoutes(r *v2.App) {
-	r.Get("/test/:id/:id2", exog_getTest)
+// RegisterRoutes registers the routes of the package. r may be an app or a group, e.g. app.Group("/api/v1"). The handlers are called on ctrl.
+func RegisterRoutes(r v2.Router, ctrl *Controller) {
+	r.Get("/gentest/test/:id/:id2", Routes.Handlers(exog_getTest)...)
+	r.Delete("/gentest/test/:id", Routes.Handlers(exog_deleteTest)...)
+	r.Post("/gentest/logout", Routes.Handlers(exog_logoutTest)...)
+	r.Post("/gentest/upload", Routes.Handlers(uploadLimit, exog_uploadTest)...)
+	r.Get("/gentest/dto/:id", Routes.Handlers(exog_getDtoTest)...)
+	r.Get("/gentest/export", Routes.Handlers(exog_exportTest(ctrl))...)
+	r.Get("/gentest/models/:kind/:slug", Routes.Handlers(exog_dbTest)...)
+	r.Put("/gentest/dto/:id", Routes.Handlers(exog_putDtoTest)...)
 }
@@ -1,44 +1,161 @@
 package gentest
 
 import (
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"time"
+
 	"github.com/exo-framework/exo"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
-type SomeDbModel struct{}
+// Routes mounts the routes of this package under /gentest and runs noCache before every handler
+var Routes = exo.Group{Prefix: "/gentest", Middleware: []fiber.Handler{noCache}}
+
+func noCache(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Next()
+}
+
+var uploadLimit = limiter.New(limiter.Config{Max: 10})
+
+type SomeDbModel struct {
+	gorm.Model
+	Kind    string
+	Slug    string
+	OwnerId string
+	Tags    []SomeDbTag
+}
+
+type SomeDbTag struct {
+	gorm.Model
+	SomeDbModelID uint
+	Name          string
+}
 
 type GetTest struct {
-	exo.Get       `route:"/test/:id/:id2"` // this will generate a GET method for the /test/:id/:id2 route
-	Id            int                      `path:"id"`                                 // this will load the path parameter "id" into the Id field
-	Id2           uuid.UUID                `path:""`                                   // this will load the path parameter "id2" into the Id2 field. Omitting the name will use the field name in camel case notation
-	Name          string                   `query:"name"`                              // this will load the query parameter "name" into the Name field
-	Name2         string                   `query:""`                                  // this will load the query parameter "name2" into the Name2 field. Omitting the name will use the field name in camel case notation
-	Authorization string                   `header:"Authorization"`                    // this will load the header "Authorization" into the Authorization field
-	Validator     string                   `header:"Validator" validate:"onValidator"` // this will load the header "Validator" into the Validator field and validate it using the onValidator function
-	//Dto           GetTestDto               `body:""`                                   // this will load the body into the Dto field
-	Form        string      `form:""`           // this will load the form parameter "form" into the Form field
-	FormNamed   string      `form:"form_named"` // this will load the form parameter "form_named" into the FormNamed field
-	SomeDbModel SomeDbModel `path:"id" db:"id"` // this will load the path parameter "id" and uses it as WHERE against the SomeDbModel table to load the SomeDbModel field. If not found is returned, 404 is returned. If db is left empty, the default primary key is used
-	Dto         struct {
-		Id   int    `json:"id"`   // this will load the json field "id" into the Id field
-		Name string `json:"name"` // this will load the json field "name" into the Name field
-	} `body:""` // this will load the body into the Dto field
+	exo.Get     `route:"/test/:id/:id2"` // this will generate a GET method for the /test/:id/:id2 route
+	Id          int                      `path:"id"`                                 // this will load the path parameter "id" into the Id field
+	Id2         uuid.UUID                `path:""`                                   // this will load the path parameter "id2" into the Id2 field. Omitting the name will use the field name in camel case notation
+	Name        string                   `query:"name"`                              // this will load the query parameter "name" into the Name field
+	Name2       string                   `query:""`                                  // this will load the query parameter "name2" into the Name2 field. Omitting the name will use the field name in camel case notation
+	Limit       int                      `query:"limit" default:"20"`                // this will use the default value "20" if the query parameter "limit" is absent
+	Page        *int                     `query:"page"`                              // pointer fields are optional, Page is nil if the query parameter "page" is absent
+	Tags        []string                 `query:"tag" validate:"max=5,dive,min=2"`   // repeated query parameters like ?tag=a&tag=b are loaded into slices, use sep:"," for ?tag=a,b. The rules after dive are checked for every element
+	Since       *time.Time               `query:"since" format:"date"`               // time.Time is parsed as RFC 3339 timestamp by default, format selects date, unix, unixmilli or a layout of the time package. time.Duration and types implementing encoding.TextUnmarshaler are parsed as well
+	Email       string                   `query:"email" validate:"email,max=254"`    // built-in rules (min, max, gte, lte, gt, lt, oneof=a|b, regex=..., email, url, uuid, notempty, dive) are checked after parsing, failing rules are answered with 400
+	Auth        exo.Claims               `auth:""`                                   // this will load the verified identity token claims into the Auth field. Unauthenticated requests are answered with 401. Use auth:"optional" to allow them
+	Validator   string                   `header:"Validator" validate:"onValidator"` // this will load the header "Validator" into the Validator field and validate it using the onValidator function
+	Theme       string                   `cookie:"theme" default:"light"`            // this will load the cookie "theme" into the Theme field
+	Form        string                   `form:""`                                   // this will load the form parameter "form" into the Form field. Clients send form parameters of GET and HEAD requests as query
+	FormNamed   string                   `form:"form_named"`                         // this will load the form parameter "form_named" into the FormNamed field
+	SomeDbModel SomeDbModel              `path:"id" db:"id"`                         // this will load the path parameter "id" and uses it as WHERE against the SomeDbModel table to load the SomeDbModel field. If not found is returned, 404 is returned. If db is left empty, the default primary key is used
+}
+
+type DeleteTest struct {
+	exo.Delete `route:"/test/:id" roles:"admin,editor" scopes:"test:write"` // only tokens with the admin or editor role and the test:write scope may call this route, others are answered with 403
+	Id         int                                                          `path:"id"`
+}
+
+type LogoutTest struct {
+	exo.Post `route:"/logout"`
+	Session  string `cookie:"session" validate:"notempty"`
+}
+
+type UploadTest struct {
+	exo.Post    `route:"/upload" use:"uploadLimit"` // this will run uploadLimit before the generated handler
+	Avatar      *multipart.FileHeader               `file:"avatar" maxsize:"5MB" accept:"image/png,image/jpeg" validate:"notempty"` // this will load the uploaded file "avatar" of the multipart form. Larger files are answered with 413, files of other types with 400. Requests above the body limit of 4 MB are rejected before, raise it using exo.WithBodyLimit
+	Attachments []*multipart.FileHeader             `file:"" maxsize:"10MB" validate:"max=3"`                                       // this will load all files uploaded as "attachments"
+	Title       string                              `form:"title"`
 }
 
 type GetTestDto struct {
-	Id int `json:"id"` // this will load the json field "id" into the Id field
+	Id int `json:"id" validate:"gte=0"` // this will load the json field "id" into the Id field. The rules of body fields are checked after decoding
+}
+
+type GetDtoTest struct {
+	exo.Get `route:"/dto/:id" timeout:"5s"` // req.Context() is cancelled after 5 seconds, errors caused by the exceeded deadline are answered with 504
+	Id      int                             `path:"id"`
+}
+
+type ExportTest struct {
+	exo.Get `route:"/export"`
+}
+
+type DbTest struct {
+	exo.Get `route:"/models/:kind/:slug"`
+	Kind    string        `path:"kind"`
+	Slug    string        `path:"slug"`
+	Models  []SomeDbModel `db:"kind=kind" preload:"Tags"`                                              // this will load all models of the kind with their tags. Slices are loaded with Find, so no records are an empty slice instead of 404
+	Model   SomeDbModel   `db:"kind=kind,slug=slug" owner:"owner_id" deleted:"include" preload:"Tags"` // this will load the model by kind and slug if its owner_id is the subject of the claims, also if it is soft-deleted
+}
+
+type PutDtoTest struct {
+	exo.Put `route:"/dto/:id"`
+	Id      int        `path:"id"`
+	Dto     GetTestDto `body:""` // this will load the JSON body into the Dto field. GET and HEAD requests have no body, and bodies cannot be combined with form parameters or files
 }
 
 // Handler can return any tuple combinations (or alone) of the following data types:
-// - error: if an error is returned, the error handler will be called
+// - error: if an error is returned, the error handler will be called. Errors like exo.NotFound or exo.Conflict, also wrapped ones, are answered with their status code
 // - int, int8, int16, int32, int64: the int as as status code will be set
 // - string: the string as plain text response
 // - []byte: the byte slice as binary response/file
 // - interface{}, any: the interface{} will be serialized as JSON response
+// - structs, slices, maps and pointers to them: the value will be serialized as JSON response
+// - exo.Cookies: the cookies will be set on the response
+// - exo.Response[T]: the status, headers, cookies and body of the response, it can only be combined with an error
+// - exo.Serialize[T]: the value will be serialized as JSON, XML, MessagePack or CBOR, depending on the Accept header
 func getTest(GetTest) (string, error) {
 	return "", nil
 }
 
+func deleteTest(DeleteTest) error {
+	return nil
+}
+
+func logoutTest(LogoutTest) (exo.Cookies, error) {
+	return exo.Cookies{exo.ExpireCookie("session")}, nil
+}
+
+func uploadTest(UploadTest) error {
+	return nil
+}
+
+func getDtoTest(req GetDtoTest) (*GetTestDto, int, error) {
+	if req.Id == 0 {
+		return nil, 0, exo.NotFound("dto not found")
+	}
+
+	return &GetTestDto{Id: req.Id}, 200, nil
+}
+
+// Controller holds the dependencies of handlers declared as its methods. The generated RegisterRoutes takes the controller, so
+// the dependencies are injected instead of being global.
+type Controller struct {
+	Exports []GetTestDto
+}
+
+func (ctl *Controller) exportTest(ExportTest) (exo.Serialize[[]GetTestDto], error) {
+	return exo.Serialize[[]GetTestDto]{Value: ctl.Exports}, nil
+}
+
+func dbTest(req DbTest) ([]SomeDbModel, error) {
+	return append(req.Models, req.Model), nil
+}
+
+func putDtoTest(req PutDtoTest) (exo.Response[GetTestDto], error) {
+	return exo.Response[GetTestDto]{
+		Status: 201,
+		Header: http.Header{"Location": {"/dto/" + strconv.Itoa(req.Id)}},
+		Body:   req.Dto,
+	}, nil
+}
+
 func onValidator(string) string {
 	return "" // return an empty string if the value is valid, otherwise the error message which should be appended to the 400 response
 }
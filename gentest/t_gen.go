@@ -2,62 +2,126 @@
 package gentest
 
 import (
+	"errors"
 	exo "github.com/exo-framework/exo"
 	db "github.com/exo-framework/exo/db"
 	v2 "github.com/gofiber/fiber/v2"
 	uuid "github.com/google/uuid"
 	gorm "gorm.io/gorm"
 	"strconv"
+	"time"
+	"unicode/utf8"
 )
 
 func exog_getTest(c *v2.Ctx) error {
-	q_Authorization := c.Get("Authorization")
+	context_stop := exo.WatchContext(c, 0)
+	defer context_stop()
+	raw_Auth, raw_Auth_err := exo.RequireClaims(c)
+	if raw_Auth_err != nil {
+		return raw_Auth_err
+	}
+	q_Auth := *raw_Auth
+	v_errs := exo.ValidationErrors{}
 	q_Validator := c.Get("Validator")
 	if q_Validator_validator_errmsg := onValidator(q_Validator); q_Validator_validator_errmsg != "" {
-		return c.Status(400).SendString(q_Validator_validator_errmsg)
+		v_errs.Add("Validator", "header", "invalid", q_Validator_validator_errmsg)
 	}
+	q_Theme := c.Cookies("theme", "light")
 	raw_Id := c.Params("id")
-	q_Id, q_Id_err := strconv.Atoi(raw_Id)
-	if q_Id_err != nil {
-		return c.Status(400).SendString(q_Id_err.Error())
+	var q_Id int
+	if raw_Id == "" {
+		v_errs.Add("id", "path", "required", "id is required")
+	} else if p_Id, p_Id_err := strconv.Atoi(raw_Id); p_Id_err != nil {
+		v_errs.Add("id", "path", "invalid", "id must be an integer")
+	} else {
+		q_Id = p_Id
 	}
 	raw_Id2 := c.Params("id2")
-	q_Id2, q_Id2_err := uuid.Parse(raw_Id2)
-	if q_Id2_err != nil {
-		return c.Status(400).SendString(q_Id2_err.Error())
+	var q_Id2 uuid.UUID
+	if raw_Id2 == "" {
+		v_errs.Add("id2", "path", "required", "id2 is required")
+	} else if p_Id2, p_Id2_err := uuid.Parse(raw_Id2); p_Id2_err != nil {
+		v_errs.Add("id2", "path", "invalid", "id2 must be a valid UUID")
+	} else {
+		q_Id2 = p_Id2
 	}
 	raw_SomeDbModel := c.Params("id")
-	q_SomeDbModel := SomeDbModel{}
-	q_SomeDbModel_err := db.DB.Where("id=?", raw_SomeDbModel).First(&q_SomeDbModel).Error
-	if q_SomeDbModel_err == gorm.ErrRecordNotFound {
-		return c.Status(404).SendString("SomeDbModel not found")
-	}
-	if q_SomeDbModel_err != nil {
-		return q_SomeDbModel_err
-	}
 	q_Name := c.Query("name")
 	q_Name2 := c.Query("name2")
-	q_Dto := struct {
-		Id   int    `json:"id"`
-		Name string `json:"name"`
-	}{}
-	if q_Dto_err := c.BodyParser(&q_Dto); q_Dto_err != nil {
-		return c.Status(400).SendString(q_Dto_err.Error())
+	raw_Limit := c.Query("limit", "20")
+	var q_Limit int
+	if p_Limit, p_Limit_err := strconv.Atoi(raw_Limit); p_Limit_err != nil {
+		v_errs.Add("limit", "query", "invalid", "limit must be an integer")
+	} else {
+		q_Limit = p_Limit
+	}
+	raw_Page := c.Query("page")
+	var q_Page *int
+	if raw_Page != "" {
+		if p_Page, p_Page_err := strconv.Atoi(raw_Page); p_Page_err != nil {
+			v_errs.Add("page", "query", "invalid", "page must be an integer")
+		} else {
+			q_Page = &p_Page
+		}
+	}
+	raw_Tags := exo.QueryValues(c, "tag", "")
+	var q_Tags []string
+	for i_Tags, s_Tags := range raw_Tags {
+		e_Tags := s_Tags
+		q_Tags = append(q_Tags, e_Tags)
+		if utf8.RuneCountInString(e_Tags) < 2 {
+			v_errs.Add("tag["+strconv.Itoa(i_Tags)+"]", "query", "min", "tag["+strconv.Itoa(i_Tags)+"] must be at least 2 characters long")
+		}
+	}
+	if len(q_Tags) > 5 {
+		v_errs.Add("tag", "query", "max", "tag must contain at most 5 items")
+	}
+	raw_Since := c.Query("since")
+	var q_Since *time.Time
+	if raw_Since != "" {
+		if p_Since, p_Since_err := time.Parse(time.DateOnly, raw_Since); p_Since_err != nil {
+			v_errs.Add("since", "query", "invalid", "since must be a date like 2006-01-02")
+		} else {
+			q_Since = &p_Since
+		}
+	}
+	q_Email := c.Query("email")
+	if q_Email != "" && !exo.IsEmail(q_Email) {
+		v_errs.Add("email", "query", "email", "email must be a valid email address")
+	}
+	if utf8.RuneCountInString(q_Email) > 254 {
+		v_errs.Add("email", "query", "max", "email must be at most 254 characters long")
 	}
 	q_Form := c.FormValue("form")
 	q_FormNamed := c.FormValue("form_named")
+	if len(v_errs) > 0 {
+		return exo.SendValidationErrors(c, v_errs)
+	}
+	q_SomeDbModel := SomeDbModel{}
+	q_SomeDbModel_err := db.DB.WithContext(c.UserContext()).Where("id=?", raw_SomeDbModel).First(&q_SomeDbModel).Error
+	if errors.Is(q_SomeDbModel_err, gorm.ErrRecordNotFound) {
+		return exo.NotFound("SomeDbModel not found")
+	}
+	if q_SomeDbModel_err != nil {
+		return q_SomeDbModel_err
+	}
 	req := GetTest{
-		Authorization: q_Authorization,
-		Dto:           q_Dto,
-		Form:          q_Form,
-		FormNamed:     q_FormNamed,
-		Get:           exo.Get{Ctx: c},
-		Id:            q_Id,
-		Id2:           q_Id2,
-		Name:          q_Name,
-		Name2:         q_Name2,
-		SomeDbModel:   q_SomeDbModel,
-		Validator:     q_Validator,
+		Auth:        q_Auth,
+		Email:       q_Email,
+		Form:        q_Form,
+		FormNamed:   q_FormNamed,
+		Get:         exo.Get{Request: exo.Request{Ctx: c}},
+		Id:          q_Id,
+		Id2:         q_Id2,
+		Limit:       q_Limit,
+		Name:        q_Name,
+		Name2:       q_Name2,
+		Page:        q_Page,
+		Since:       q_Since,
+		SomeDbModel: q_SomeDbModel,
+		Tags:        q_Tags,
+		Theme:       q_Theme,
+		Validator:   q_Validator,
 	}
 	r_0, r_1 := getTest(req)
 	if r_1 != nil {
@@ -65,3 +129,214 @@ func exog_getTest(c *v2.Ctx) error {
 	}
 	return c.SendString(r_0)
 }
+func exog_deleteTest(c *v2.Ctx) error {
+	guard_claims, guard_err := exo.RequireClaims(c)
+	if guard_err != nil {
+		return guard_err
+	}
+	if !guard_claims.HasRole("admin", "editor") {
+		return exo.Forbidden("missing role")
+	}
+	if !guard_claims.HasScopes("test:write") {
+		return exo.Forbidden("missing scope")
+	}
+	context_stop := exo.WatchContext(c, 0)
+	defer context_stop()
+	v_errs := exo.ValidationErrors{}
+	raw_Id := c.Params("id")
+	var q_Id int
+	if raw_Id == "" {
+		v_errs.Add("id", "path", "required", "id is required")
+	} else if p_Id, p_Id_err := strconv.Atoi(raw_Id); p_Id_err != nil {
+		v_errs.Add("id", "path", "invalid", "id must be an integer")
+	} else {
+		q_Id = p_Id
+	}
+	if len(v_errs) > 0 {
+		return exo.SendValidationErrors(c, v_errs)
+	}
+	req := DeleteTest{
+		Delete: exo.Delete{Request: exo.Request{Ctx: c}},
+		Id:     q_Id,
+	}
+	r_0 := deleteTest(req)
+	if r_0 != nil {
+		return r_0
+	}
+	return c.SendStatus(204)
+}
+func exog_logoutTest(c *v2.Ctx) error {
+	context_stop := exo.WatchContext(c, 0)
+	defer context_stop()
+	v_errs := exo.ValidationErrors{}
+	q_Session := c.Cookies("session")
+	if q_Session == "" {
+		v_errs.Add("session", "cookie", "required", "session must not be empty")
+	}
+	if len(v_errs) > 0 {
+		return exo.SendValidationErrors(c, v_errs)
+	}
+	req := LogoutTest{
+		Post:    exo.Post{Request: exo.Request{Ctx: c}},
+		Session: q_Session,
+	}
+	r_0, r_1 := logoutTest(req)
+	if r_1 != nil {
+		return r_1
+	}
+	exo.SetCookies(c, r_0)
+	return c.SendStatus(204)
+}
+func exog_uploadTest(c *v2.Ctx) error {
+	context_stop := exo.WatchContext(c, 0)
+	defer context_stop()
+	v_errs := exo.ValidationErrors{}
+	q_Title := c.FormValue("title")
+	q_Avatar := exo.FormFile(c, "avatar")
+	if q_Avatar == nil {
+		v_errs.Add("avatar", "file", "required", "avatar is required")
+	} else {
+		if q_Avatar.Size > 5242880 {
+			v_errs.Add("avatar", "file", "maxsize", "avatar must not be larger than 5 MB")
+		}
+		if !exo.AcceptsFile(q_Avatar, "image/png", "image/jpeg") {
+			v_errs.Add("avatar", "file", "accept", "avatar must be of type image/png, image/jpeg")
+		}
+	}
+	q_Attachments := exo.FormFiles(c, "attachments")
+	for i_Attachments, s_Attachments := range q_Attachments {
+		if s_Attachments.Size > 10485760 {
+			v_errs.Add("attachments["+strconv.Itoa(i_Attachments)+"]", "file", "maxsize", "attachments["+strconv.Itoa(i_Attachments)+"] must not be larger than 10 MB")
+		}
+	}
+	if len(q_Attachments) > 3 {
+		v_errs.Add("attachments", "file", "max", "attachments must contain at most 3 items")
+	}
+	if len(v_errs) > 0 {
+		return exo.SendValidationErrors(c, v_errs)
+	}
+	req := UploadTest{
+		Attachments: q_Attachments,
+		Avatar:      q_Avatar,
+		Post:        exo.Post{Request: exo.Request{Ctx: c}},
+		Title:       q_Title,
+	}
+	r_0 := uploadTest(req)
+	if r_0 != nil {
+		return r_0
+	}
+	return c.SendStatus(204)
+}
+func exog_getDtoTest(c *v2.Ctx) error {
+	context_stop := exo.WatchContext(c, 5*time.Second)
+	defer context_stop()
+	v_errs := exo.ValidationErrors{}
+	raw_Id := c.Params("id")
+	var q_Id int
+	if raw_Id == "" {
+		v_errs.Add("id", "path", "required", "id is required")
+	} else if p_Id, p_Id_err := strconv.Atoi(raw_Id); p_Id_err != nil {
+		v_errs.Add("id", "path", "invalid", "id must be an integer")
+	} else {
+		q_Id = p_Id
+	}
+	if len(v_errs) > 0 {
+		return exo.SendValidationErrors(c, v_errs)
+	}
+	req := GetDtoTest{
+		Get: exo.Get{Request: exo.Request{Ctx: c}},
+		Id:  q_Id,
+	}
+	r_0, r_1, r_2 := getDtoTest(req)
+	if r_2 != nil {
+		return exo.TimeoutError(c.UserContext(), r_2)
+	}
+	c.Status(r_1)
+	return c.JSON(r_0)
+}
+func exog_exportTest(ctrl *Controller) v2.Handler {
+	return func(c *v2.Ctx) error {
+		context_stop := exo.WatchContext(c, 0)
+		defer context_stop()
+		req := ExportTest{Get: exo.Get{Request: exo.Request{Ctx: c}}}
+		r_0, r_1 := ctrl.exportTest(req)
+		if r_1 != nil {
+			return r_1
+		}
+		return exo.SendSerialize(c, r_0)
+	}
+}
+func exog_dbTest(c *v2.Ctx) error {
+	context_stop := exo.WatchContext(c, 0)
+	defer context_stop()
+	v_errs := exo.ValidationErrors{}
+	q_Kind := c.Params("kind")
+	q_Slug := c.Params("slug")
+	if len(v_errs) > 0 {
+		return exo.SendValidationErrors(c, v_errs)
+	}
+	q_Models := []SomeDbModel{}
+	q_Models_err := db.DB.WithContext(c.UserContext()).Preload("Tags").Where("kind=?", q_Kind).Find(&q_Models).Error
+	if q_Models_err != nil {
+		return q_Models_err
+	}
+	q_Model := SomeDbModel{}
+	q_Model_owner, q_Model_owner_err := exo.RequireClaims(c)
+	if q_Model_owner_err != nil {
+		return q_Model_owner_err
+	}
+	q_Model_err := db.DB.WithContext(c.UserContext()).Unscoped().Preload("Tags").Where("kind=? AND slug=?", q_Kind, q_Slug).Where("owner_id=?", q_Model_owner.Subject).First(&q_Model).Error
+	if errors.Is(q_Model_err, gorm.ErrRecordNotFound) {
+		return exo.NotFound("Model not found")
+	}
+	if q_Model_err != nil {
+		return q_Model_err
+	}
+	req := DbTest{
+		Get:    exo.Get{Request: exo.Request{Ctx: c}},
+		Kind:   q_Kind,
+		Model:  q_Model,
+		Models: q_Models,
+		Slug:   q_Slug,
+	}
+	r_0, r_1 := dbTest(req)
+	if r_1 != nil {
+		return r_1
+	}
+	return c.JSON(r_0)
+}
+func exog_putDtoTest(c *v2.Ctx) error {
+	context_stop := exo.WatchContext(c, 0)
+	defer context_stop()
+	v_errs := exo.ValidationErrors{}
+	raw_Id := c.Params("id")
+	var q_Id int
+	if raw_Id == "" {
+		v_errs.Add("id", "path", "required", "id is required")
+	} else if p_Id, p_Id_err := strconv.Atoi(raw_Id); p_Id_err != nil {
+		v_errs.Add("id", "path", "invalid", "id must be an integer")
+	} else {
+		q_Id = p_Id
+	}
+	q_Dto := GetTestDto{}
+	if q_Dto_err := c.BodyParser(&q_Dto); q_Dto_err != nil {
+		v_errs.Add("", "body", "invalid", "body could not be parsed: "+q_Dto_err.Error())
+	} else {
+		if q_Dto.Id < 0 {
+			v_errs.Add("id", "body", "gte", "id must be at least 0")
+		}
+	}
+	if len(v_errs) > 0 {
+		return exo.SendValidationErrors(c, v_errs)
+	}
+	req := PutDtoTest{
+		Dto: q_Dto,
+		Id:  q_Id,
+		Put: exo.Put{Request: exo.Request{Ctx: c}},
+	}
+	r_0, r_1 := putDtoTest(req)
+	if r_1 != nil {
+		return r_1
+	}
+	return exo.SendResponse(c, r_0)
+}
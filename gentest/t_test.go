@@ -0,0 +1,661 @@
+package gentest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/exo-framework/exo"
+	"github.com/exo-framework/exo/db"
+	"github.com/glebarez/sqlite"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// newTestApp registers the generated routes on a framework with authentication, the given options and an empty SQLite database.
+func newTestApp(t *testing.T, opts ...exo.ConfigOption) *exo.Framework {
+	t.Helper()
+
+	database, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "gentest.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := database.AutoMigrate(&SomeDbModel{}, &SomeDbTag{}); err != nil {
+		t.Fatal(err)
+	}
+
+	db.DB = database
+	t.Cleanup(func() { db.DB = nil })
+
+	// every app gets its own limiter, so that the uploads of the tests do not share one budget
+	uploadLimit = limiter.New(limiter.Config{Max: 10})
+
+	app := exo.New(append([]exo.ConfigOption{exo.WithAuth(exo.WithAuthSecrets("gentest"))}, opts...)...)
+	RegisterRoutes(app, &Controller{Exports: []GetTestDto{{Id: 1}, {Id: 2}}})
+	return app
+}
+
+// bearer issues an identity token for the claims.
+func bearer(t *testing.T, app *exo.Framework, claims exo.Claims) string {
+	t.Helper()
+
+	token, err := app.Auth().Issue(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return "Bearer " + token
+}
+
+// send sends the request to the app and returns the response along with its body.
+func send(t *testing.T, app *exo.Framework, req *http.Request) (*http.Response, string) {
+	t.Helper()
+
+	res, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return res, string(body)
+}
+
+// appTransport sends the requests of a client to the app without listening on a port.
+type appTransport struct {
+	app *exo.Framework
+}
+
+func (tr appTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return tr.app.Test(req, -1)
+}
+
+// newTestClient returns a generated client for the app which sends the token.
+func newTestClient(app *exo.Framework, token string) *Client {
+	return NewClient(&exo.Client{BaseURL: "http://gentest", Token: token, HTTPClient: &http.Client{Transport: appTransport{app}}})
+}
+
+func TestClient(t *testing.T) {
+	app := newTestApp(t)
+	if err := db.DB.Create(&SomeDbModel{Kind: "a", Slug: "b"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	token := strings.TrimPrefix(bearer(t, app, exo.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "user"}}), "Bearer ")
+	client := newTestClient(app, token)
+	ctx := context.Background()
+	id2 := uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+	page := 2
+
+	tests := []struct {
+		name   string
+		call   func() (any, error)
+		want   any
+		status int // status of the *exo.ClientError, 0 if the call succeeds
+	}{
+		{"parameters", func() (any, error) {
+			return client.GetTest(ctx, GetTest{Id: 1, Id2: id2, Page: &page, Tags: []string{"ab", "cd"}, Email: "a@b.c", Theme: "dark", Form: "f"})
+		}, "", 0},
+		{"validation problem", func() (any, error) {
+			return client.GetTest(ctx, GetTest{Id: 1, Id2: id2, Tags: []string{"a"}})
+		}, nil, fiber.StatusBadRequest},
+		{"status code", func() (any, error) {
+			dto, status, err := client.GetDtoTest(ctx, GetDtoTest{Id: 7})
+			return []any{dto, status}, err
+		}, []any{&GetTestDto{Id: 7}, fiber.StatusOK}, 0},
+		{"not found", func() (any, error) {
+			_, _, err := client.GetDtoTest(ctx, GetDtoTest{})
+			return nil, err
+		}, nil, fiber.StatusNotFound},
+		{"json body and response", func() (any, error) {
+			res, err := client.PutDtoTest(ctx, PutDtoTest{Id: 3, Dto: GetTestDto{Id: 4}})
+			return []any{res.Status, res.Header.Get("Location"), res.Body}, err
+		}, []any{fiber.StatusCreated, "/dto/3", GetTestDto{Id: 4}}, 0},
+		{"files and form", func() (any, error) {
+			avatar := exo.ClientFile{Filename: "a.png", ContentType: "image/png", Content: strings.NewReader("png")}
+			return nil, client.UploadTest(ctx, UploadTest{Title: "t"}, avatar, nil)
+		}, nil, 0},
+		{"missing file", func() (any, error) {
+			return nil, client.UploadTest(ctx, UploadTest{Title: "t"}, exo.ClientFile{}, nil)
+		}, nil, fiber.StatusBadRequest},
+		{"cookies", func() (any, error) {
+			cookies, err := client.LogoutTest(ctx, LogoutTest{Session: "s"})
+			if len(cookies) != 1 {
+				return cookies, err
+			}
+			return []any{cookies[0].Name, cookies[0].Value}, err
+		}, []any{"session", ""}, 0},
+		{"serialized response", func() (any, error) {
+			res, err := client.ExportTest(ctx, ExportTest{})
+			return res.Value, err
+		}, []GetTestDto{{Id: 1}, {Id: 2}}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.call()
+			if tt.status != 0 {
+				var clientErr *exo.ClientError
+				if !errors.As(err, &clientErr) || clientErr.StatusCode != tt.status || clientErr.Problem == nil {
+					t.Fatalf("expected a problem with status %d, got %v", tt.status, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %#v, got %#v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAuthField(t *testing.T) {
+	app := newTestApp(t)
+	if err := db.DB.Create(&SomeDbModel{Kind: "a", Slug: "b"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		status int
+		detail string
+	}{
+		{"valid token", bearer(t, app, exo.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "user"}}), fiber.StatusOK, ""},
+		{"missing token", "", fiber.StatusUnauthorized, "authentication required"},
+		{"invalid token", "Bearer invalid", fiber.StatusUnauthorized, "invalid token"},
+		{"other scheme", "Basic dXNlcjpwYXNz", fiber.StatusUnauthorized, "invalid authorization header"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodGet, "/gentest/test/1/6ba7b810-9dad-11d1-80b4-00c04fd430c8", nil)
+			if tt.header != "" {
+				req.Header.Set(fiber.HeaderAuthorization, tt.header)
+			}
+
+			res, body := send(t, app, req)
+			if res.StatusCode != tt.status {
+				t.Fatalf("expected %d, got %d: %s", tt.status, res.StatusCode, body)
+			}
+
+			if !strings.Contains(body, tt.detail) {
+				t.Errorf("expected %q in %s", tt.detail, body)
+			}
+		})
+	}
+}
+
+func TestGuards(t *testing.T) {
+	app := newTestApp(t)
+	claims := func(roles, scopes []string) exo.Claims {
+		return exo.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "user"}, Roles: roles, Scopes: scopes}
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		status int
+		detail string
+	}{
+		{"admin with scope", bearer(t, app, claims([]string{"admin"}, []string{"test:write"})), fiber.StatusNoContent, ""},
+		{"editor with scopes", bearer(t, app, claims([]string{"viewer", "editor"}, []string{"test:read", "test:write"})), fiber.StatusNoContent, ""},
+		{"missing role", bearer(t, app, claims([]string{"viewer"}, []string{"test:write"})), fiber.StatusForbidden, "missing role"},
+		{"missing scope", bearer(t, app, claims([]string{"admin"}, []string{"test:read"})), fiber.StatusForbidden, "missing scope"},
+		{"anonymous", "", fiber.StatusUnauthorized, "authentication required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodDelete, "/gentest/test/1", nil)
+			if tt.header != "" {
+				req.Header.Set(fiber.HeaderAuthorization, tt.header)
+			}
+
+			res, body := send(t, app, req)
+			if res.StatusCode != tt.status {
+				t.Fatalf("expected %d, got %d: %s", tt.status, res.StatusCode, body)
+			}
+
+			if !strings.Contains(body, tt.detail) {
+				t.Errorf("expected %q in %s", tt.detail, body)
+			}
+		})
+	}
+}
+
+func TestValidationRules(t *testing.T) {
+	app := newTestApp(t)
+	if err := db.DB.Create(&SomeDbModel{Kind: "a", Slug: "b"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	token := bearer(t, app, exo.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "user"}})
+	getTest := "/gentest/test/1/6ba7b810-9dad-11d1-80b4-00c04fd430c8?"
+
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		body   string
+		errors []exo.FieldError // without messages, nil if the request is valid
+	}{
+		{"valid query", fiber.MethodGet, getTest + "tag=ab&tag=cd&email=a@b.c", "", nil},
+		{"empty email", fiber.MethodGet, getTest + "email=", "", nil},
+		{"element rule", fiber.MethodGet, getTest + "tag=ab&tag=c", "", []exo.FieldError{{Field: "tag[1]", Source: "query", Code: "min"}}},
+		{"slice length", fiber.MethodGet, getTest + "tag=ab&tag=ab&tag=ab&tag=ab&tag=ab&tag=ab", "", []exo.FieldError{{Field: "tag", Source: "query", Code: "max"}}},
+		{"email", fiber.MethodGet, getTest + "email=a", "", []exo.FieldError{{Field: "email", Source: "query", Code: "email"}}},
+		{"email length", fiber.MethodGet, getTest + "email=" + strings.Repeat("a", 251) + "@b.c", "", []exo.FieldError{{Field: "email", Source: "query", Code: "max"}}},
+		{"several rules", fiber.MethodGet, getTest + "tag=a&email=a", "", []exo.FieldError{{Field: "tag[0]", Source: "query", Code: "min"}, {Field: "email", Source: "query", Code: "email"}}},
+		{"valid body", fiber.MethodPut, "/gentest/dto/1", `{"id":0}`, nil},
+		{"body field", fiber.MethodPut, "/gentest/dto/1", `{"id":-1}`, []exo.FieldError{{Field: "id", Source: "body", Code: "gte"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			req.Header.Set(fiber.HeaderAuthorization, token)
+			if tt.body != "" {
+				req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			}
+
+			res, body := send(t, app, req)
+			if tt.errors == nil {
+				if res.StatusCode >= 300 {
+					t.Fatalf("expected success, got %d: %s", res.StatusCode, body)
+				}
+				return
+			}
+
+			var problem exo.Problem
+			if res.StatusCode != fiber.StatusBadRequest || json.Unmarshal([]byte(body), &problem) != nil {
+				t.Fatalf("expected a problem with status 400, got %d: %s", res.StatusCode, body)
+			}
+
+			for i := range problem.Errors {
+				problem.Errors[i].Message = ""
+			}
+
+			if !reflect.DeepEqual(problem.Errors, tt.errors) {
+				t.Errorf("expected %+v, got %+v", tt.errors, problem.Errors)
+			}
+		})
+	}
+}
+
+func TestValidationErrors(t *testing.T) {
+	app := newTestApp(t)
+	token := bearer(t, app, exo.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "user"}})
+
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		header string
+		status int
+		detail string
+		errors []exo.FieldError
+	}{
+		{"path", fiber.MethodGet, "/gentest/test/x/6ba7b810-9dad-11d1-80b4-00c04fd430c8", "", fiber.StatusBadRequest, "The request has an invalid field.", []exo.FieldError{
+			{Field: "id", Source: "path", Code: "invalid", Message: "id must be an integer"},
+		}},
+		{"all invalid fields at once", fiber.MethodGet, "/gentest/test/x/y?limit=a&page=b&since=2024-13-01", "Validator: v", fiber.StatusBadRequest, "The request has 5 invalid fields.", []exo.FieldError{
+			{Field: "id", Source: "path", Code: "invalid", Message: "id must be an integer"},
+			{Field: "id2", Source: "path", Code: "invalid", Message: "id2 must be a valid UUID"},
+			{Field: "limit", Source: "query", Code: "invalid", Message: "limit must be an integer"},
+			{Field: "page", Source: "query", Code: "invalid", Message: "page must be an integer"},
+			{Field: "since", Source: "query", Code: "invalid", Message: "since must be a date like 2006-01-02"},
+		}},
+		{"cookie", fiber.MethodPost, "/gentest/logout", "", fiber.StatusBadRequest, "The request has an invalid field.", []exo.FieldError{
+			{Field: "session", Source: "cookie", Code: "required", Message: "session must not be empty"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, nil)
+			req.Header.Set(fiber.HeaderAuthorization, token)
+			if name, value, ok := strings.Cut(tt.header, ": "); ok {
+				req.Header.Set(name, value)
+			}
+
+			res, body := send(t, app, req)
+			if res.StatusCode != tt.status || res.Header.Get(fiber.HeaderContentType) != exo.ProblemContentType {
+				t.Fatalf("expected a problem with status %d, got %d: %s", tt.status, res.StatusCode, body)
+			}
+
+			var problem exo.Problem
+			if err := json.Unmarshal([]byte(body), &problem); err != nil {
+				t.Fatal(err)
+			}
+
+			if problem.Detail != tt.detail || !reflect.DeepEqual(problem.Errors, tt.errors) {
+				t.Errorf("expected %q %+v, got %q %+v", tt.detail, tt.errors, problem.Detail, problem.Errors)
+			}
+		})
+	}
+}
+
+func TestCookies(t *testing.T) {
+	app := newTestApp(t)
+
+	tests := []struct {
+		name      string
+		cookie    string
+		status    int
+		setCookie string
+	}{
+		{"session", "session=s", fiber.StatusNoContent, "session=; expires=Thu, 01 Jan 1970 00:00:00 GMT; path=/; SameSite=Lax"},
+		{"other cookies", "theme=dark; session=s", fiber.StatusNoContent, "session=; expires=Thu, 01 Jan 1970 00:00:00 GMT; path=/; SameSite=Lax"},
+		{"empty session", "session=", fiber.StatusBadRequest, ""},
+		{"no cookies", "", fiber.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodPost, "/gentest/logout", nil)
+			if tt.cookie != "" {
+				req.Header.Set(fiber.HeaderCookie, tt.cookie)
+			}
+
+			res, body := send(t, app, req)
+			if res.StatusCode != tt.status {
+				t.Fatalf("expected %d, got %d: %s", tt.status, res.StatusCode, body)
+			}
+
+			if got := res.Header.Get(fiber.HeaderSetCookie); got != tt.setCookie {
+				t.Errorf("expected Set-Cookie %q, got %q", tt.setCookie, got)
+			}
+		})
+	}
+}
+
+// testFile is a file of a multipart form sent by the tests.
+type testFile struct {
+	key, contentType string
+	size             int
+}
+
+func TestUpload(t *testing.T) {
+	app := newTestApp(t, exo.WithBodyLimit(32<<20))
+
+	png := testFile{"avatar", "image/png", 16}
+	attachment := testFile{"attachments", "application/pdf", 16}
+
+	tests := []struct {
+		name   string
+		files  []testFile
+		status int
+		errors []exo.FieldError // without messages
+	}{
+		{"avatar", []testFile{png}, fiber.StatusNoContent, nil},
+		{"avatar and attachments", []testFile{{"avatar", "image/jpeg", 16}, attachment, attachment}, fiber.StatusNoContent, nil},
+		{"missing avatar", []testFile{attachment}, fiber.StatusBadRequest, []exo.FieldError{{Field: "avatar", Source: "file", Code: "required"}}},
+		{"type of the avatar", []testFile{{"avatar", "image/gif", 16}}, fiber.StatusBadRequest, []exo.FieldError{{Field: "avatar", Source: "file", Code: "accept"}}},
+		{"too many attachments", []testFile{png, attachment, attachment, attachment, attachment}, fiber.StatusBadRequest, []exo.FieldError{{Field: "attachments", Source: "file", Code: "max"}}},
+		{"large avatar", []testFile{{"avatar", "image/png", 5<<20 + 1}}, fiber.StatusRequestEntityTooLarge, []exo.FieldError{{Field: "avatar", Source: "file", Code: "maxsize"}}},
+		{"large attachment", []testFile{png, attachment, {"attachments", "application/pdf", 10<<20 + 1}}, fiber.StatusRequestEntityTooLarge, []exo.FieldError{{Field: "attachments[1]", Source: "file", Code: "maxsize"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			writer := multipart.NewWriter(buf)
+			_ = writer.WriteField("title", "t")
+			for i, file := range tt.files {
+				header := textproto.MIMEHeader{}
+				header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="f%d"`, file.key, i))
+				header.Set("Content-Type", file.contentType)
+
+				part, err := writer.CreatePart(header)
+				if err != nil {
+					t.Fatal(err)
+				}
+				_, _ = part.Write(bytes.Repeat([]byte("x"), file.size))
+			}
+			_ = writer.Close()
+
+			req := httptest.NewRequest(fiber.MethodPost, "/gentest/upload", buf)
+			req.Header.Set(fiber.HeaderContentType, writer.FormDataContentType())
+
+			res, body := send(t, app, req)
+			if res.StatusCode != tt.status {
+				t.Fatalf("expected %d, got %d: %s", tt.status, res.StatusCode, body)
+			}
+
+			if tt.errors == nil {
+				return
+			}
+
+			var problem exo.Problem
+			if err := json.Unmarshal([]byte(body), &problem); err != nil {
+				t.Fatal(err)
+			}
+
+			for i := range problem.Errors {
+				problem.Errors[i].Message = ""
+			}
+
+			if !reflect.DeepEqual(problem.Errors, tt.errors) {
+				t.Errorf("expected %+v, got %+v", tt.errors, problem.Errors)
+			}
+		})
+	}
+}
+
+func TestResponses(t *testing.T) {
+	app := newTestApp(t)
+
+	tests := []struct {
+		name        string
+		method      string
+		url         string
+		body        string
+		status      int
+		contentType string
+		location    string
+		response    string
+	}{
+		{"pointer and status", fiber.MethodGet, "/gentest/dto/7", "", fiber.StatusOK, fiber.MIMEApplicationJSON, "", `{"id":7}`},
+		{"typed error", fiber.MethodGet, "/gentest/dto/0", "", fiber.StatusNotFound, exo.ProblemContentType, "", `{"title":"Not Found","status":404,"detail":"dto not found"}`},
+		{"response", fiber.MethodPut, "/gentest/dto/3", `{"id":4}`, fiber.StatusCreated, fiber.MIMEApplicationJSON, "/dto/3", `{"id":4}`},
+		{"slice", fiber.MethodGet, "/gentest/export", "", fiber.StatusOK, fiber.MIMEApplicationJSON, "", `[{"id":1},{"id":2}]`},
+		{"no content", fiber.MethodPost, "/gentest/logout", "", fiber.StatusNoContent, "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			req.Header.Set(fiber.HeaderCookie, "session=s")
+			if tt.body != "" {
+				req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			}
+
+			res, body := send(t, app, req)
+			if res.StatusCode != tt.status || res.Header.Get(fiber.HeaderContentType) != tt.contentType || body != tt.response {
+				t.Fatalf("expected %d %q %s, got %d %q %s", tt.status, tt.contentType, tt.response, res.StatusCode, res.Header.Get(fiber.HeaderContentType), body)
+			}
+
+			if got := res.Header.Get(fiber.HeaderLocation); got != tt.location {
+				t.Errorf("expected Location %q, got %q", tt.location, got)
+			}
+		})
+	}
+}
+
+func TestGroup(t *testing.T) {
+	app := newTestApp(t)
+
+	tests := []struct {
+		name         string
+		url          string
+		status       int
+		cacheControl string
+	}{
+		{"route of the group", "/gentest/dto/7", fiber.StatusOK, "no-store"},
+		{"error of a route of the group", "/gentest/dto/0", fiber.StatusNotFound, "no-store"},
+		{"route without prefix", "/dto/7", fiber.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, _ := send(t, app, httptest.NewRequest(fiber.MethodGet, tt.url, nil))
+			if res.StatusCode != tt.status || res.Header.Get(fiber.HeaderCacheControl) != tt.cacheControl {
+				t.Errorf("expected %d with Cache-Control %q, got %d with %q", tt.status, tt.cacheControl, res.StatusCode, res.Header.Get(fiber.HeaderCacheControl))
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	app := newTestApp(t)
+
+	// uploadLimit allows 10 requests and runs before the generated handler, so invalid uploads count as well
+	tests := []struct {
+		name     string
+		requests int
+		status   int
+	}{
+		{"within the limit", 10, fiber.StatusBadRequest},
+		{"above the limit", 1, fiber.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < tt.requests; i++ {
+				res, body := send(t, app, httptest.NewRequest(fiber.MethodPost, "/gentest/upload", nil))
+				if res.StatusCode != tt.status {
+					t.Fatalf("request %d: expected %d, got %d: %s", i+1, tt.status, res.StatusCode, body)
+				}
+			}
+		})
+	}
+}
+
+func TestController(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctrl     *Controller
+		change   func(*Controller)
+		response string
+	}{
+		{"exports", &Controller{Exports: []GetTestDto{{Id: 3}}}, nil, `[{"id":3}]`},
+		{"no exports", &Controller{}, nil, `null`},
+		// the handlers are called on the registered pointer
+		{"changed after registration", &Controller{}, func(ctrl *Controller) { ctrl.Exports = []GetTestDto{{Id: 4}} }, `[{"id":4}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := exo.New(exo.WithAuth(exo.WithAuthSecrets("gentest")))
+			RegisterRoutes(app, tt.ctrl)
+			if tt.change != nil {
+				tt.change(tt.ctrl)
+			}
+
+			res, body := send(t, app, httptest.NewRequest(fiber.MethodGet, "/gentest/export", nil))
+			if res.StatusCode != fiber.StatusOK || body != tt.response {
+				t.Errorf("expected 200 %s, got %d %s", tt.response, res.StatusCode, body)
+			}
+		})
+	}
+}
+
+func TestDBFields(t *testing.T) {
+	app := newTestApp(t)
+
+	models := []*SomeDbModel{
+		{Kind: "a", Slug: "b", OwnerId: "user", Tags: []SomeDbTag{{Name: "x"}, {Name: "y"}}},
+		{Kind: "a", Slug: "c", OwnerId: "other"},
+		{Kind: "a", Slug: "d", OwnerId: "user"},
+		{Kind: "e", Slug: "f", OwnerId: "user"},
+	}
+	for _, model := range models {
+		if err := db.DB.Create(model).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// d and f are soft-deleted, so only Model includes them
+	if err := db.DB.Delete(models[2]).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.DB.Delete(models[3]).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	user := bearer(t, app, exo.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "user"}})
+
+	tests := []struct {
+		name   string
+		url    string
+		header string
+		status int
+		models []string // slugs of Models followed by the slug of Model, each with the names of its tags
+	}{
+		{"own model", "/gentest/models/a/b", user, fiber.StatusOK, []string{"b[x y]", "c[]", "b[x y]"}},
+		{"model of another owner", "/gentest/models/a/c", user, fiber.StatusNotFound, nil},
+		{"deleted model", "/gentest/models/a/d", user, fiber.StatusOK, []string{"b[x y]", "c[]", "d[]"}},
+		{"no models of the kind", "/gentest/models/e/f", user, fiber.StatusOK, []string{"f[]"}},
+		{"missing model", "/gentest/models/a/z", user, fiber.StatusNotFound, nil},
+		{"no token", "/gentest/models/a/b", "", fiber.StatusUnauthorized, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodGet, tt.url, nil)
+			if tt.header != "" {
+				req.Header.Set(fiber.HeaderAuthorization, tt.header)
+			}
+
+			res, body := send(t, app, req)
+			if res.StatusCode != tt.status {
+				t.Fatalf("expected %d, got %d: %s", tt.status, res.StatusCode, body)
+			}
+
+			if tt.models == nil {
+				return
+			}
+
+			var got []SomeDbModel
+			if err := json.Unmarshal([]byte(body), &got); err != nil {
+				t.Fatal(err)
+			}
+
+			slugs := []string{}
+			for _, model := range got {
+				tags := []string{}
+				for _, tag := range model.Tags {
+					tags = append(tags, tag.Name)
+				}
+				slugs = append(slugs, fmt.Sprintf("%s%v", model.Slug, tags))
+			}
+
+			if !reflect.DeepEqual(slugs, tt.models) {
+				t.Errorf("expected %v, got %v", tt.models, slugs)
+			}
+		})
+	}
+}
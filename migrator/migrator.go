@@ -106,6 +106,11 @@ func (m *Migrator) Initialize(edb *gorm.DB) error {
 	return nil
 }
 
+// DB returns the database connection used by the migrator. It returns nil if the migrator is not initialized.
+func (m *Migrator) DB() *gorm.DB {
+	return m.db
+}
+
 // AddModel adds a model to the migrator.
 func (m *Migrator) AddModel(models ...any) {
 	m.models = append(m.models, models...)
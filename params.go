@@ -0,0 +1,94 @@
+package exo
+
+import (
+	"encoding"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// QueryValues returns all values of a repeated query parameter like ?tag=a&tag=b. If sep is not empty, the values are split by it as well,
+// so ?ids=1,2,3 results in three values.
+func QueryValues(c *fiber.Ctx, key, sep string) []string {
+	return splitValues(c.Context().QueryArgs().PeekMulti(key), sep)
+}
+
+// HeaderValues returns all values of a header which may be sent multiple times. If sep is not empty, the values are split by it as well.
+func HeaderValues(c *fiber.Ctx, key, sep string) []string {
+	return splitValues(c.Request().Header.PeekAll(key), sep)
+}
+
+// FormValues returns all values of a form parameter of an url encoded or a multipart form. If sep is not empty, the values are split by it as well.
+func FormValues(c *fiber.Ctx, key, sep string) []string {
+	if form, err := c.MultipartForm(); err == nil {
+		values := make([][]byte, len(form.Value[key]))
+		for i, value := range form.Value[key] {
+			values[i] = []byte(value)
+		}
+
+		return splitValues(values, sep)
+	}
+
+	return splitValues(c.Context().PostArgs().PeekMulti(key), sep)
+}
+
+func splitValues(raw [][]byte, sep string) []string {
+	values := []string{}
+	for _, value := range raw {
+		if sep == "" {
+			values = append(values, string(value))
+			continue
+		}
+
+		// empty values are skipped, so that ?ids= results in no value instead of one empty value
+		for _, part := range strings.Split(string(value), sep) {
+			if part = strings.TrimSpace(part); part != "" {
+				values = append(values, part)
+			}
+		}
+	}
+
+	return values
+}
+
+// ParseText parses a parameter of a type implementing encoding.TextUnmarshaler.
+func ParseText[T any, PT interface {
+	*T
+	encoding.TextUnmarshaler
+}](s string) (T, error) {
+	var value T
+	err := PT(&value).UnmarshalText([]byte(s))
+	return value, err
+}
+
+// FormatText formats a parameter of a type implementing encoding.TextMarshaler. Values which cannot be marshaled result in an empty string.
+func FormatText(value encoding.TextMarshaler) string {
+	text, err := value.MarshalText()
+	if err != nil {
+		return ""
+	}
+
+	return string(text)
+}
+
+// ParseUnix parses a unix timestamp in seconds.
+func ParseUnix(s string) (time.Time, error) {
+	sec, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(sec, 0), nil
+}
+
+// ParseUnixMilli parses a unix timestamp in milliseconds.
+func ParseUnixMilli(s string) (time.Time, error) {
+	msec, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.UnixMilli(msec), nil
+}
@@ -0,0 +1,153 @@
+package exo
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http/httptest"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestSplitValues(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  []string
+		sep  string
+		want []string
+	}{
+		{"no values", nil, "", []string{}},
+		{"repeated values", []string{"a", "b"}, "", []string{"a", "b"}},
+		{"empty value without separator", []string{""}, "", []string{""}},
+		{"separated values", []string{"1,2,3"}, ",", []string{"1", "2", "3"}},
+		{"separated and repeated values", []string{"1,2", "3"}, ",", []string{"1", "2", "3"}},
+		{"spaces and empty values", []string{" 1 ,, 2 ", ""}, ",", []string{"1", "2"}},
+		{"longer separator", []string{"a::b"}, "::", []string{"a", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := make([][]byte, len(tt.raw))
+			for i, value := range tt.raw {
+				raw[i] = []byte(value)
+			}
+
+			if got := splitValues(raw, tt.sep); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParameterValues(t *testing.T) {
+	multipartBody := func(values ...string) (string, string) {
+		buf := &bytes.Buffer{}
+		writer := multipart.NewWriter(buf)
+		for _, value := range values {
+			_ = writer.WriteField("ids", value)
+		}
+		_ = writer.Close()
+		return buf.String(), writer.FormDataContentType()
+	}
+
+	body, multipartType := multipartBody("1,2", "3")
+
+	tests := []struct {
+		name        string
+		values      func(c *fiber.Ctx) []string
+		query       string
+		header      []string
+		contentType string
+		body        string
+		want        []string
+	}{
+		{"repeated query", func(c *fiber.Ctx) []string { return QueryValues(c, "tag", "") }, "tag=a&tag=b&other=c", nil, "", "", []string{"a", "b"}},
+		{"separated query", func(c *fiber.Ctx) []string { return QueryValues(c, "ids", ",") }, "ids=1,2&ids=3", nil, "", "", []string{"1", "2", "3"}},
+		{"absent query", func(c *fiber.Ctx) []string { return QueryValues(c, "tag", "") }, "", nil, "", "", []string{}},
+		{"repeated header", func(c *fiber.Ctx) []string { return HeaderValues(c, "X-Ids", ",") }, "", []string{"1, 2", "3"}, "", "", []string{"1", "2", "3"}},
+		{"urlencoded form", func(c *fiber.Ctx) []string { return FormValues(c, "ids", ",") }, "", nil, fiber.MIMEApplicationForm, "ids=1,2&ids=3", []string{"1", "2", "3"}},
+		{"multipart form", func(c *fiber.Ctx) []string { return FormValues(c, "ids", ",") }, "", nil, multipartType, body, []string{"1", "2", "3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			app := fiber.New()
+			app.Post("/", func(c *fiber.Ctx) error {
+				got = tt.values(c)
+				return nil
+			})
+
+			req := httptest.NewRequest(fiber.MethodPost, "/?"+tt.query, strings.NewReader(tt.body))
+			for _, value := range tt.header {
+				req.Header.Add("X-Ids", value)
+			}
+			if tt.contentType != "" {
+				req.Header.Set(fiber.HeaderContentType, tt.contentType)
+			}
+
+			if _, err := app.Test(req); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		name  string
+		parse func(string) (time.Time, error)
+		value string
+		want  time.Time
+		err   bool
+	}{
+		{"unix", ParseUnix, "1700000000", time.Unix(1700000000, 0), false},
+		{"negative unix", ParseUnix, "-1", time.Unix(-1, 0), false},
+		{"unix with fraction", ParseUnix, "1.5", time.Time{}, true},
+		{"unix milliseconds", ParseUnixMilli, "1700000000123", time.UnixMilli(1700000000123), false},
+		{"empty unix milliseconds", ParseUnixMilli, "", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parse(tt.value)
+			if (err != nil) != tt.err || !got.Equal(tt.want) {
+				t.Errorf("expected %v, %v, got %v, %v", tt.want, tt.err, got, err)
+			}
+		})
+	}
+}
+
+func TestParseText(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  netip.Addr
+		err   bool
+	}{
+		{"ipv4", "192.168.0.1", netip.MustParseAddr("192.168.0.1"), false},
+		{"ipv6", "::1", netip.IPv6Loopback(), false},
+		{"invalid", "300.0.0.1", netip.Addr{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseText[netip.Addr](tt.value)
+			if (err != nil) != tt.err || got != tt.want {
+				t.Fatalf("expected %v, %v, got %v, %v", tt.want, tt.err, got, err)
+			}
+
+			if !tt.err && FormatText(got) != tt.value {
+				t.Errorf("expected %q to be formatted as itself, got %q", tt.value, FormatText(got))
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package exo
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ProblemContentType is the content type of RFC 9457 problem details documents.
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 9457 problem details document.
+type Problem struct {
+	Type     string       `json:"type,omitempty"` // URI identifying the problem type. If empty, clients treat it as "about:blank"
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"` // invalid fields of the request
+}
+
+// FieldError describes a single invalid field of a request.
+type FieldError struct {
+	Field   string `json:"field"`   // name of the parameter, or the path of a body field like "addresses[0].zip". Empty for the body itself
+	Source  string `json:"source"`  // path, query, header, cookie, body, form or file
+	Code    string `json:"code"`    // machine-readable reason like required, invalid, min, email or maxsize
+	Message string `json:"message"` // human-readable reason
+}
+
+// ValidationErrors collects the invalid fields of a request, so that the generated handlers can report all of them at once.
+type ValidationErrors []FieldError
+
+// Add adds an invalid field.
+func (e *ValidationErrors) Add(field, source, code, message string) {
+	*e = append(*e, FieldError{Field: field, Source: source, Code: code, Message: message})
+}
+
+// SendProblem sends the problem as application/problem+json using its status code.
+func SendProblem(c *fiber.Ctx, problem Problem) error {
+	return c.Status(problem.Status).JSON(problem, ProblemContentType)
+}
+
+// SendValidationErrors answers with 400 and a problem document listing all invalid fields. If an uploaded file exceeds its maximum
+// size, 413 is sent instead.
+func SendValidationErrors(c *fiber.Ctx, errs ValidationErrors) error {
+	detail := "The request has an invalid field."
+	if len(errs) != 1 {
+		detail = fmt.Sprintf("The request has %d invalid fields.", len(errs))
+	}
+
+	title, status := "Bad Request", fiber.StatusBadRequest
+	for _, err := range errs {
+		if err.Code == "maxsize" {
+			title, status = "Content Too Large", fiber.StatusRequestEntityTooLarge
+		}
+	}
+
+	return SendProblem(c, Problem{
+		Title:  title,
+		Status: status,
+		Detail: detail,
+		Errors: errs,
+	})
+}
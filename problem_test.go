@@ -0,0 +1,64 @@
+package exo
+
+import (
+	"io"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestSendValidationErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		errs   ValidationErrors
+		status int
+		title  string
+		detail string
+	}{
+		{"one field", ValidationErrors{{Field: "id", Source: "path", Code: "invalid", Message: "id must be an integer"}}, fiber.StatusBadRequest, "Bad Request", "The request has an invalid field."},
+		{"several fields", ValidationErrors{
+			{Field: "id", Source: "path", Code: "invalid", Message: "id must be an integer"},
+			{Field: "tag[1]", Source: "query", Code: "min", Message: "tag[1] must be at least 2 characters long"},
+		}, fiber.StatusBadRequest, "Bad Request", "The request has 2 invalid fields."},
+		{"file too large", ValidationErrors{
+			{Field: "title", Source: "form", Code: "required", Message: "title is required"},
+			{Field: "avatar", Source: "file", Code: "maxsize", Message: "avatar must not be larger than 5 MB"},
+		}, fiber.StatusRequestEntityTooLarge, "Content Too Large", "The request has 2 invalid fields."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error {
+				return SendValidationErrors(c, tt.errs)
+			})
+
+			res, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if res.StatusCode != tt.status || res.Header.Get(fiber.HeaderContentType) != ProblemContentType {
+				t.Fatalf("expected %d %s, got %d %s", tt.status, ProblemContentType, res.StatusCode, res.Header.Get(fiber.HeaderContentType))
+			}
+
+			body, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var problem Problem
+			if err := json.Unmarshal(body, &problem); err != nil {
+				t.Fatal(err)
+			}
+
+			want := Problem{Title: tt.title, Status: tt.status, Detail: tt.detail, Errors: tt.errs}
+			if !reflect.DeepEqual(problem, want) {
+				t.Errorf("expected %+v, got %+v", want, problem)
+			}
+		})
+	}
+}
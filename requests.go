@@ -1,45 +1,87 @@
 package exo
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Request is embedded by the method embeds like Get and gives the handlers access to the request. The embeds used to embed the
+// *fiber.Ctx directly, so literals like exo.Get{Ctx: c}, e.g. in tests calling handlers, are written as exo.Get{Request: exo.Request{Ctx: c}}.
+type Request struct {
+	*fiber.Ctx
+}
+
+// Context returns the user context of the request, which carries values set by middleware. The generated handlers cancel it once the
+// client disconnects or the deadline of the timeout tag of the route passes (see WatchContext), and pass it to the database, so lookups
+// are cancelled as well. Without a fiber context, e.g. if a handler is called by a test, the background context is returned.
+//
+// The fasthttp request context remains available as Ctx.Context().
+func (r Request) Context() context.Context {
+	if r.Ctx == nil {
+		return context.Background()
+	}
+
+	return r.Ctx.UserContext()
+}
 
 // Get declares a struct that represents a GET request.
 type Get struct {
-	*fiber.Ctx
+	Request
 }
 
 // Post declares a struct that represents a POST request.
 type Post struct {
-	*fiber.Ctx
+	Request
 }
 
 // Put declares a struct that represents a PUT request.
 type Put struct {
-	*fiber.Ctx
+	Request
 }
 
 // Delete declares a struct that represents a DELETE request.
 type Delete struct {
-	*fiber.Ctx
+	Request
 }
 
 // Patch declares a struct that represents a PATCH request.
 type Patch struct {
-	*fiber.Ctx
+	Request
 }
 
 // Options declares a struct that represents an OPTIONS request.
 type Options struct {
-	*fiber.Ctx
+	Request
 }
 
 // Head declares a struct that represents a HEAD request.
 type Head struct {
-	*fiber.Ctx
+	Request
 }
 
 // Trace declares a struct that represents a TRACE request.
 type Trace struct {
-	*fiber.Ctx
+	Request
+}
+
+// Group mounts the routes of a package under a prefix and runs middleware before their handlers. It is declared as package-level variable
+// next to the request structs, e.g.
+//
+//	var Routes = exo.Group{Prefix: "/orders", Middleware: []fiber.Handler{audit}}
+//
+// The prefix must be a constant, as it is part of the generated clients and documents. The middleware only runs for the routes of the package.
+type Group struct {
+	Prefix     string
+	Middleware []fiber.Handler
+}
+
+// Handlers returns the middleware of the group followed by the handlers of a route, which are the middleware of its use tag and the
+// handler itself.
+func (g Group) Handlers(handlers ...fiber.Handler) []fiber.Handler {
+	all := make([]fiber.Handler, 0, len(g.Middleware)+len(handlers))
+	all = append(all, g.Middleware...)
+	return append(all, handlers...)
 }
 
 // O is a type that represents a JSON object.
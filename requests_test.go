@@ -0,0 +1,54 @@
+package exo
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestGroupHandlers(t *testing.T) {
+	var calls []string
+	handler := func(name string) fiber.Handler {
+		return func(c *fiber.Ctx) error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+
+	tests := []struct {
+		name     string
+		group    Group
+		handlers []fiber.Handler
+		calls    []string
+	}{
+		{"no middleware", Group{}, []fiber.Handler{handler("handler")}, []string{"handler"}},
+		{"middleware first", Group{Middleware: []fiber.Handler{handler("audit"), handler("noCache")}}, []fiber.Handler{handler("limit"), handler("handler")}, []string{"audit", "noCache", "limit", "handler"}},
+		{"middleware only", Group{Middleware: []fiber.Handler{handler("audit")}}, nil, []string{"audit"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = nil
+			for _, h := range tt.group.Handlers(tt.handlers...) {
+				_ = h(nil)
+			}
+
+			if !reflect.DeepEqual(calls, tt.calls) {
+				t.Errorf("expected %v, got %v", tt.calls, calls)
+			}
+		})
+	}
+}
+
+func TestGroupHandlersKeepsMiddleware(t *testing.T) {
+	// spare capacity would let append share the array between the routes
+	middleware := make([]fiber.Handler, 1, 2)
+	group := Group{Middleware: middleware}
+
+	a := group.Handlers(func(c *fiber.Ctx) error { return nil })
+	b := group.Handlers(nil)
+	if a[1] == nil || b[1] != nil || len(group.Middleware) != 1 {
+		t.Error("expected the handlers of a route not to overwrite those of another route")
+	}
+}
@@ -0,0 +1,44 @@
+package exo
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Response gives a handler full control over its response. Handlers return it instead of the body, it can only be combined with an error.
+type Response[T any] struct {
+	Status  int         // status code. If 0, 200 is sent
+	Header  http.Header // headers added to the response
+	Cookies Cookies     // cookies set on the response
+	Body    T           // sent like a body returned by a handler: strings as text, byte slices as binary and everything else as JSON
+}
+
+// SendResponse sends a response returned by a handler.
+func SendResponse[T any](c *fiber.Ctx, res Response[T]) error {
+	for key, values := range res.Header {
+		for _, value := range values {
+			c.Response().Header.Add(key, value)
+		}
+	}
+
+	SetCookies(c, res.Cookies)
+
+	status := res.Status
+	if status == 0 {
+		status = fiber.StatusOK
+	}
+
+	c.Status(status)
+
+	switch body := any(res.Body).(type) {
+	case nil:
+		return nil
+	case string:
+		return c.SendString(body)
+	case []byte:
+		return c.Send(body)
+	}
+
+	return c.JSON(res.Body)
+}
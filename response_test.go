@@ -0,0 +1,80 @@
+package exo
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestSendResponse(t *testing.T) {
+	type item struct {
+		Name string `json:"name"`
+	}
+
+	tests := []struct {
+		name        string
+		send        func(c *fiber.Ctx) error
+		status      int
+		contentType string // not checked if empty
+		body        string
+		header      http.Header
+	}{
+		{"default status", func(c *fiber.Ctx) error {
+			return SendResponse(c, Response[item]{Body: item{Name: "a"}})
+		}, fiber.StatusOK, fiber.MIMEApplicationJSON, `{"name":"a"}`, nil},
+		{"status and headers", func(c *fiber.Ctx) error {
+			return SendResponse(c, Response[*item]{Status: fiber.StatusCreated, Header: http.Header{"Location": {"/items/a"}, "X-Tag": {"a", "b"}}, Body: &item{Name: "a"}})
+		}, fiber.StatusCreated, fiber.MIMEApplicationJSON, `{"name":"a"}`, http.Header{"Location": {"/items/a"}, "X-Tag": {"a", "b"}}},
+		{"cookies", func(c *fiber.Ctx) error {
+			return SendResponse(c, Response[string]{Cookies: Cookies{{Name: "a", Value: "1"}}, Body: "ok"})
+		}, fiber.StatusOK, fiber.MIMETextPlainCharsetUTF8, "ok", http.Header{"Set-Cookie": {"a=1; path=/; SameSite=Lax"}}},
+		{"bytes", func(c *fiber.Ctx) error {
+			return SendResponse(c, Response[[]byte]{Header: http.Header{"Content-Type": {"image/png"}}, Body: []byte{1, 2}})
+		}, fiber.StatusOK, "image/png", "\x01\x02", nil},
+		{"no body", func(c *fiber.Ctx) error {
+			return SendResponse(c, Response[any]{Status: fiber.StatusAccepted})
+		}, fiber.StatusAccepted, "", "", nil},
+		{"nil pointer", func(c *fiber.Ctx) error {
+			return SendResponse(c, Response[*item]{})
+		}, fiber.StatusOK, fiber.MIMEApplicationJSON, "null", nil},
+		{"slice", func(c *fiber.Ctx) error {
+			return SendResponse(c, Response[[]item]{Body: []item{{Name: "a"}}})
+		}, fiber.StatusOK, fiber.MIMEApplicationJSON, `[{"name":"a"}]`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", tt.send)
+
+			res, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			body, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			contentType := res.Header.Get(fiber.HeaderContentType)
+			if tt.contentType == "" {
+				contentType = ""
+			}
+
+			if res.StatusCode != tt.status || contentType != tt.contentType || string(body) != tt.body {
+				t.Errorf("expected %d %q %q, got %d %q %q", tt.status, tt.contentType, tt.body, res.StatusCode, contentType, body)
+			}
+
+			for key, values := range tt.header {
+				if got := res.Header.Values(key); !reflect.DeepEqual(got, values) {
+					t.Errorf("expected %s %q, got %q", key, values, got)
+				}
+			}
+		})
+	}
+}
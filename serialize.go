@@ -0,0 +1,278 @@
+package exo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/tinylib/msgp/msgp"
+)
+
+// Format is an encoding a Serialize value can be sent in.
+type Format string
+
+const (
+	FormatJSON    Format = "json"
+	FormatXML     Format = "xml"
+	FormatMsgPack Format = "msgpack"
+	FormatCBOR    Format = "cbor"
+)
+
+// formats are the content types of the formats, in the order they are preferred if the client accepts several of them equally.
+var formats = []struct {
+	format      Format
+	contentType string
+}{
+	{FormatJSON, fiber.MIMEApplicationJSON},
+	{FormatXML, fiber.MIMEApplicationXML},
+	{FormatXML, fiber.MIMETextXML},
+	{FormatMsgPack, "application/msgpack"},
+	{FormatMsgPack, "application/x-msgpack"},
+	{FormatMsgPack, "application/vnd.msgpack"},
+	{FormatCBOR, "application/cbor"},
+}
+
+// Serialize is returned by handlers to send a value in the format the client asks for by its Accept header. Value is encoded by its json
+// tags for JSON, MessagePack and CBOR, and by its xml tags for XML.
+type Serialize[T any] struct {
+	Value  T
+	Format Format // if not empty, the value is always sent in this format
+}
+
+// SendSerialize sends a value returned by a handler. If its format is empty, the format is negotiated by the Accept header of the request,
+// clients which accept none of the formats are answered with 406.
+func SendSerialize[T any](c *fiber.Ctx, s Serialize[T]) error {
+	format, contentType := s.Format, ""
+	if format == "" {
+		c.Vary(fiber.HeaderAccept)
+
+		offers := make([]string, len(formats))
+		for i, f := range formats {
+			offers[i] = f.contentType
+		}
+
+		contentType = c.Accepts(offers...)
+		if contentType == "" {
+			return SendProblem(c, Problem{
+				Title:  "Not Acceptable",
+				Status: fiber.StatusNotAcceptable,
+				Detail: "The response can be sent as application/json, application/xml, application/msgpack or application/cbor.",
+			})
+		}
+	}
+
+	for _, f := range formats {
+		if f.contentType == contentType || (contentType == "" && f.format == format) {
+			format, contentType = f.format, f.contentType
+			break
+		}
+	}
+
+	switch format {
+	case FormatJSON:
+		return c.JSON(s.Value, contentType)
+	case FormatXML:
+		c.Set(fiber.HeaderContentType, contentType)
+		data, err := c.App().Config().XMLEncoder(s.Value)
+		if err != nil {
+			return err
+		}
+
+		return c.Send(data)
+	case FormatMsgPack, FormatCBOR:
+		data, err := transcode(c.App().Config().JSONEncoder, s.Value, format)
+		if err != nil {
+			return err
+		}
+
+		c.Set(fiber.HeaderContentType, contentType)
+		return c.Send(data)
+	}
+
+	return errors.New("exo: unknown format " + string(format))
+}
+
+// member is a member of a JSON object. Objects are kept as lists of members, so that the order of the fields is kept.
+type member struct {
+	key   string
+	value any
+}
+
+// transcode encodes v as JSON and converts the JSON to MessagePack or CBOR, so that the json tags apply to all formats.
+func transcode(encode func(any) ([]byte, error), v any, format Format) ([]byte, error) {
+	data, err := encode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	value, err := readJSON(dec)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == FormatMsgPack {
+		return appendMsgPack(nil, value), nil
+	}
+
+	return appendCBOR(nil, value), nil
+}
+
+// readJSON reads the next value of dec as nil, bool, json.Number, string, []any or []member.
+func readJSON(dec *json.Decoder) (any, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('['):
+		values := []any{}
+		for dec.More() {
+			value, err := readJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			values = append(values, value)
+		}
+
+		_, err = dec.Token()
+		return values, err
+	case json.Delim('{'):
+		members := []member{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			value, err := readJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			members = append(members, member{key.(string), value})
+		}
+
+		_, err = dec.Token()
+		return members, err
+	}
+
+	return token, nil
+}
+
+func appendMsgPack(b []byte, value any) []byte {
+	switch v := value.(type) {
+	case bool:
+		return msgp.AppendBool(b, v)
+	case string:
+		return msgp.AppendString(b, v)
+	case json.Number:
+		if i, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return msgp.AppendInt64(b, i)
+		}
+
+		if u, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			return msgp.AppendUint64(b, u)
+		}
+
+		f, _ := v.Float64()
+		return msgp.AppendFloat64(b, f)
+	case []any:
+		b = msgp.AppendArrayHeader(b, uint32(len(v)))
+		for _, item := range v {
+			b = appendMsgPack(b, item)
+		}
+
+		return b
+	case []member:
+		b = msgp.AppendMapHeader(b, uint32(len(v)))
+		for _, m := range v {
+			b = msgp.AppendString(b, m.key)
+			b = appendMsgPack(b, m.value)
+		}
+
+		return b
+	}
+
+	return msgp.AppendNil(b)
+}
+
+// CBOR major types, see RFC 8949
+const (
+	cborUint   = 0
+	cborNegInt = 1
+	cborText   = 3
+	cborArray  = 4
+	cborMap    = 5
+	cborSimple = 7
+)
+
+func appendCBOR(b []byte, value any) []byte {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return append(b, cborSimple<<5|21)
+		}
+
+		return append(b, cborSimple<<5|20)
+	case string:
+		return append(appendCBORHead(b, cborText, uint64(len(v))), v...)
+	case json.Number:
+		if i, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			if i < 0 {
+				return appendCBORHead(b, cborNegInt, uint64(-(i + 1)))
+			}
+
+			return appendCBORHead(b, cborUint, uint64(i))
+		}
+
+		if u, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			return appendCBORHead(b, cborUint, u)
+		}
+
+		f, _ := v.Float64()
+		return binary.BigEndian.AppendUint64(append(b, cborSimple<<5|27), math.Float64bits(f))
+	case []any:
+		b = appendCBORHead(b, cborArray, uint64(len(v)))
+		for _, item := range v {
+			b = appendCBOR(b, item)
+		}
+
+		return b
+	case []member:
+		b = appendCBORHead(b, cborMap, uint64(len(v)))
+		for _, m := range v {
+			b = appendCBOR(b, m.key)
+			b = appendCBOR(b, m.value)
+		}
+
+		return b
+	}
+
+	// null
+	return append(b, cborSimple<<5|22)
+}
+
+// appendCBORHead appends the head of a data item, which is its major type and an argument like the length of a string.
+func appendCBORHead(b []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(b, major<<5|byte(n))
+	case n <= math.MaxUint8:
+		return append(b, major<<5|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, major<<5|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, major<<5|26), uint32(n))
+	}
+
+	return binary.BigEndian.AppendUint64(append(b, major<<5|27), n)
+}
@@ -0,0 +1,270 @@
+package exo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/tinylib/msgp/msgp"
+)
+
+type serializeTestValue struct {
+	Name    string               `json:"name"`
+	Tags    []string             `json:"tags,omitempty"`
+	Nested  *serializeTestNested `json:"nested"`
+	Skipped string               `json:"-"`
+}
+
+type serializeTestNested struct {
+	Count uint64 `json:"count"`
+}
+
+// normalize converts values decoded by the reference decoders into comparable values. Numbers are kept as their exact text, so
+// that integers beyond the precision of float64 are compared as well.
+func normalize(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return strconv.FormatInt(i, 10)
+		}
+
+		if u, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			return strconv.FormatUint(u, 10)
+		}
+
+		f, _ := v.Float64()
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case []any:
+		for i := range v {
+			v[i] = normalize(v[i])
+		}
+
+		return v
+	case map[string]any:
+		for k := range v {
+			v[k] = normalize(v[k])
+		}
+
+		return v
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, value := range v {
+			m[k.(string)] = normalize(value)
+		}
+
+		return m
+	}
+
+	return v
+}
+
+func TestTranscode(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+	}{
+		{"null", nil},
+		{"true", true},
+		{"false", false},
+		{"zero", 0},
+		{"small int", 23},
+		{"uint8", 24},
+		{"max uint8", 255},
+		{"uint16", 256},
+		{"max uint16", 65535},
+		{"uint32", 65536},
+		{"max uint32", uint64(math.MaxUint32)},
+		{"uint64", uint64(math.MaxUint32) + 1},
+		{"max int64", int64(math.MaxInt64)},
+		{"max uint64", uint64(math.MaxUint64)},
+		{"negative", -1},
+		{"small negative", -24},
+		{"negative int8", -25},
+		{"negative int16", -257},
+		{"min int64", int64(math.MinInt64)},
+		{"float", 1.5},
+		{"negative float", -0.25},
+		{"large float", 1e300},
+		{"integer beyond uint64", json.RawMessage("100000000000000000000")},
+		{"float with zero fraction", json.RawMessage("2.0")},
+		{"empty string", ""},
+		{"unicode", "héllo wörld ✓"},
+		{"string of 300 bytes", strings.Repeat("a", 300)},
+		{"string of 65535 bytes", strings.Repeat("b", 65535)},
+		{"string of 70000 bytes", strings.Repeat("c", 70000)},
+		{"escaped string", "quote \" backslash \\ newline \n"},
+		{"empty array", []int{}},
+		{"nested arrays", [][]any{{1, "a"}, {}, {nil, true}}},
+		{"array of 30 items", make([]int, 30)},
+		{"empty object", map[string]int{}},
+		{"object", map[string]any{"a": 1, "b": []string{"x"}, "c": map[string]bool{"d": false}}},
+		{"struct", serializeTestValue{Name: "n", Nested: &serializeTestNested{Count: math.MaxUint64}, Skipped: "s"}},
+		{"omitted fields", serializeTestValue{Name: "n"}},
+		{"bytes", []byte{0, 1, 2, 255}},
+		{"time", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			dec := json.NewDecoder(bytes.NewReader(data))
+			dec.UseNumber()
+
+			var expected any
+			if err := dec.Decode(&expected); err != nil {
+				t.Fatal(err)
+			}
+			expected = normalize(expected)
+
+			msgpack, err := transcode(json.Marshal, tt.value, FormatMsgPack)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			value, rest, err := msgp.ReadIntfBytes(msgpack)
+			if err != nil {
+				t.Fatalf("msgpack: %v", err)
+			}
+
+			if len(rest) > 0 {
+				t.Errorf("msgpack: %d trailing bytes", len(rest))
+			}
+
+			if value = normalize(value); !reflect.DeepEqual(value, expected) {
+				t.Errorf("msgpack: expected %v, got %v", expected, value)
+			}
+
+			data, err = transcode(json.Marshal, tt.value, FormatCBOR)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := cbor.Wellformed(data); err != nil {
+				t.Fatalf("cbor: %v", err)
+			}
+
+			value = nil
+			if err := cbor.Unmarshal(data, &value); err != nil {
+				t.Fatalf("cbor: %v", err)
+			}
+
+			if value = normalize(value); !reflect.DeepEqual(value, expected) {
+				t.Errorf("cbor: expected %v, got %v", expected, value)
+			}
+		})
+	}
+}
+
+// TestTranscodeOrder checks that the members of objects are encoded in the order of the JSON, which is the order of the struct fields.
+func TestTranscodeOrder(t *testing.T) {
+	value := struct {
+		B int `json:"b"`
+		A int `json:"a"`
+		C int `json:"c"`
+	}{1, 2, 3}
+
+	data, err := transcode(json.Marshal, value, FormatMsgPack)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, data, err := msgp.ReadMapHeaderBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := []string{}
+	for i := uint32(0); i < n; i++ {
+		var key string
+		if key, data, err = msgp.ReadStringBytes(data); err != nil {
+			t.Fatal(err)
+		}
+
+		if data, err = msgp.Skip(data); err != nil {
+			t.Fatal(err)
+		}
+
+		keys = append(keys, key)
+	}
+
+	if fmt.Sprint(keys) != "[b a c]" {
+		t.Fatalf("expected keys [b a c], got %v", keys)
+	}
+}
+
+func TestSendSerialize(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      Format
+		accept      string
+		status      int
+		contentType string
+	}{
+		{"no accept header", "", "", fiber.StatusOK, fiber.MIMEApplicationJSON},
+		{"any type", "", "*/*", fiber.StatusOK, fiber.MIMEApplicationJSON},
+		{"json", "", "application/json", fiber.StatusOK, fiber.MIMEApplicationJSON},
+		{"xml", "", "application/xml", fiber.StatusOK, fiber.MIMEApplicationXML},
+		{"text xml", "", "text/xml", fiber.StatusOK, fiber.MIMETextXML},
+		{"msgpack", "", "application/msgpack", fiber.StatusOK, "application/msgpack"},
+		{"msgpack alias", "", "application/x-msgpack", fiber.StatusOK, "application/x-msgpack"},
+		{"cbor", "", "application/cbor", fiber.StatusOK, "application/cbor"},
+		{"preferred by quality", "", "application/json;q=0.5, application/cbor", fiber.StatusOK, "application/cbor"},
+		{"not acceptable", "", "text/html", fiber.StatusNotAcceptable, ProblemContentType},
+		{"fixed format", FormatCBOR, "application/json", fiber.StatusOK, "application/cbor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error {
+				return SendSerialize(c, Serialize[serializeTestNested]{Value: serializeTestNested{Count: 1}, Format: tt.format})
+			})
+
+			req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+			if tt.accept != "" {
+				req.Header.Set(fiber.HeaderAccept, tt.accept)
+			}
+
+			res, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if res.StatusCode != tt.status {
+				t.Errorf("expected %d, got %d", tt.status, res.StatusCode)
+			}
+
+			if contentType := res.Header.Get(fiber.HeaderContentType); !strings.HasPrefix(contentType, tt.contentType) {
+				t.Errorf("expected content type %s, got %s", tt.contentType, contentType)
+			}
+
+			if tt.format == "" && res.Header.Get(fiber.HeaderVary) != fiber.HeaderAccept {
+				t.Errorf("expected Vary: Accept, got %q", res.Header.Get(fiber.HeaderVary))
+			}
+
+			if _, err := io.ReadAll(res.Body); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
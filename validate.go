@@ -0,0 +1,18 @@
+package exo
+
+import (
+	"net/mail"
+	"net/url"
+)
+
+// IsEmail reports whether s is a plain email address like user@example.com. Addresses with a display name are not accepted.
+func IsEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s
+}
+
+// IsURL reports whether s is an absolute URL with a scheme and a host.
+func IsURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
@@ -0,0 +1,51 @@
+package exo
+
+import "testing"
+
+func TestIsEmail(t *testing.T) {
+	tests := []struct {
+		value string
+		valid bool
+	}{
+		{"user@example.com", true},
+		{"first.last+tag@sub.example.org", true},
+		{"", false},
+		{"user", false},
+		{"user@", false},
+		{"@example.com", false},
+		{"User <user@example.com>", false},
+		{" user@example.com", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := IsEmail(tt.value); got != tt.valid {
+				t.Errorf("expected %v, got %v", tt.valid, got)
+			}
+		})
+	}
+}
+
+func TestIsURL(t *testing.T) {
+	tests := []struct {
+		value string
+		valid bool
+	}{
+		{"https://example.com", true},
+		{"http://localhost:8080/path?q=1", true},
+		{"ftp://files.example.com/a", true},
+		{"", false},
+		{"example.com", false},
+		{"/relative/path", false},
+		{"mailto:user@example.com", false},
+		{"https://", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := IsURL(tt.value); got != tt.valid {
+				t.Errorf("expected %v, got %v", tt.valid, got)
+			}
+		})
+	}
+}